@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 
+	"gocha/internal/avatar"
 	"gocha/internal/config"
 	"gocha/internal/handlers"
 	"gocha/internal/repo/postgres"
@@ -72,24 +73,6 @@ func main() {
 
 	coreLogger.Info().Msgf("Bot user: %+v\n", botUser)
 
-	updates, _ := bot.UpdatesViaLongPolling(ctx, nil)
-
-	bh, _ := th.NewBotHandler(bot, updates)
-
-	initHandlers(bh)
-
-	// Stop handling updates
-	defer func() { _ = bh.Stop() }()
-
-	// Start handling updates
-	go func() {
-		// Start handling updates
-		err := bh.Start()
-		if err != nil {
-			coreLogger.Error().Err(err).Msg("bot handler failed")
-		}
-	}()
-
 	mux := http.NewServeMux()
 
 	// Создаём под-файл-систему, чтобы убрать префикс `ui/static` из путей
@@ -107,7 +90,7 @@ func main() {
 
 	repo := postgres.NewRepository(&repoLogger, pgPool)
 
-	srv := service.NewService(cfg, &srvLogger, repo)
+	srv := service.NewService(cfg, &srvLogger, repo, handlers.NewTelegramNotifier(bot))
 
 	err = srv.MonitorPetsAll(ctx)
 	if err != nil {
@@ -116,21 +99,77 @@ func main() {
 
 	defer srv.Stop()
 
-	petHandlers := handlers.NewPetHandlers(handlersLogger, srv, cfg.BaseUrl, cfg.IsDev)
+	go srv.RunDailyReports(ctx)
+	go srv.RunStatCompaction(ctx)
+	go srv.RunLeaderboards(ctx)
+	go srv.RunLazyReschedules(ctx)
+	go srv.RunDeliveries(ctx)
+
+	updates, _ := bot.UpdatesViaLongPolling(ctx, nil)
+
+	bh, _ := th.NewBotHandler(bot, updates)
+
+	compositor := avatar.NewCompositor(subFS)
+
+	botHandlers := handlers.NewBotHandlers(handlersLogger, srv, compositor)
+	botHandlers.RunApp(bh)
+
+	err = botHandlers.SetCommands(ctx, bot)
+	if err != nil {
+		coreLogger.Warn().Err(err).Msg("can't set bot commands")
+	}
+
+	// Stop handling updates
+	defer func() { _ = bh.Stop() }()
+
+	// Start handling updates
+	go func() {
+		// Start handling updates
+		err := bh.Start()
+		if err != nil {
+			coreLogger.Error().Err(err).Msg("bot handler failed")
+		}
+	}()
+
+	petHandlers := handlers.NewPetHandlers(handlersLogger, srv, handlers.NewTelegramChatAdmins(bot, handlersLogger), cfg.BaseUrl, cfg.TgToken, cfg.IsDev)
 
 	if cfg.IsDev {
 		mux.HandleFunc("/api/debug/mock-init-data", petHandlers.DebugMockInitDataHandler)
 		mux.HandleFunc("/api/debug/init-config", petHandlers.DebugInitTgConfigHandler)
+		mux.HandleFunc("/api/scheduler/stats", petHandlers.SchedulerStatsHandler)
 	}
 
 	mux.HandleFunc("/api/pet/create/", petHandlers.PetNewHandler)
 	mux.HandleFunc("/api/pet/info/", petHandlers.PetInfoHandler)
-	mux.HandleFunc("/api/pet/heal/", petHandlers.PetHealHandler)
-	mux.HandleFunc("/api/pet/feed/", petHandlers.PetFeedHandler)
-	mux.HandleFunc("/api/pet/play/", petHandlers.PetPlayHandler)
-	mux.HandleFunc("/api/pet/clean/", petHandlers.PetCleanHandler)
-	mux.HandleFunc("/api/pet/sleep/", petHandlers.PetSleepHandler)
-	mux.HandleFunc("/api/pet/wakeup/", petHandlers.PetWakeUpHandler)
+	mux.HandleFunc("/api/pet/report/", petHandlers.PetReportHandler)
+	mux.HandleFunc("/api/pet/diary/", petHandlers.PetDiaryHandler)
+	mux.HandleFunc("/api/pet/history/", petHandlers.PetHistoryHandler)
+	mux.HandleFunc("/api/pet/stats/", petHandlers.PetStatsHandler)
+	mux.HandleFunc("/api/streak/", petHandlers.StreakHandler)
+	mux.HandleFunc("/api/quests/", petHandlers.QuestsHandler)
+	mux.HandleFunc("/api/events/active", petHandlers.ActiveEventsHandler)
+	mux.HandleFunc("/api/settings/", petHandlers.SettingsHandler)
+	mux.HandleFunc("/api/leaderboard/", petHandlers.LeaderboardHandler)
+
+	// Маршруты действий строятся из реестра: /api/pet/feed/, /api/pet/train/ и т.д.
+	for _, action := range service.Actions() {
+		mux.HandleFunc("/api/pet/"+action.Name+"/", petHandlers.PetActionHandler(action.Name))
+	}
+
+	mux.HandleFunc("/api/cosmetics/", petHandlers.WardrobeHandler)
+	mux.HandleFunc("/api/cosmetics/buy/", petHandlers.BuyCosmeticHandler)
+	mux.HandleFunc("/api/cosmetics/equip/", petHandlers.EquipCosmeticHandler)
+
+	mux.HandleFunc("/api/gifts/", petHandlers.GiftsHandler)
+	mux.HandleFunc("/api/gifts/send/", petHandlers.SendGiftHandler)
+	mux.HandleFunc("/api/gifts/accept/", petHandlers.AcceptGiftHandler)
+	mux.HandleFunc("/api/gifts/decline/", petHandlers.DeclineGiftHandler)
+
+	// Аватары с аксессуарами собираются на лету; маршрут точнее /static/, поэтому перехватывает эти адреса.
+	avatarHandlers := handlers.NewAvatarHandlers(handlersLogger, srv, compositor, cfg.BaseUrl, cfg.CardSecret, cfg.TgToken, cfg.IsDev)
+	mux.HandleFunc("/static/avatar/", avatarHandlers.ComposedAvatarHandler)
+	mux.HandleFunc("/api/pet/card/", avatarHandlers.PetCardLinkHandler)
+	mux.HandleFunc("/card/", avatarHandlers.PetCardHandler)
 
 	fileServer := http.FileServer(http.FS(subFS))
 	mux.Handle("/static/", http.StripPrefix("/static", fileServer))
@@ -165,10 +204,6 @@ func main() {
 	}
 }
 
-func initHandlers(bh *th.BotHandler) {
-	handlers.RunApp(bh)
-}
-
 func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Разрешаем запросы с фронтенда
@@ -288,6 +288,17 @@ async function loadPetInfo() {
         petData = apiResponse.data;
         console.log('Pet data loaded:', petData);
         displayPetInfo();
+        loadReport();
+        loadDiary();
+        loadHistory();
+        loadStats();
+        loadWardrobe();
+        loadStreak();
+        loadQuests();
+        loadEvents();
+        loadGifts();
+        loadLeaderboards();
+        loadSettings();
 
         // Безопасный вызов HapticFeedback
         if (tg.HapticFeedback && typeof tg.HapticFeedback.notificationOccurred === 'function') {
@@ -523,7 +534,7 @@ async function createPet() {
 }
 
 // Выполнить действие (кормить, играть и т.д.)
-async function performAction(action) {
+async function performAction(action, payload = null) {
     if (isLoading || !tg) return;
 
     if (!petData) {
@@ -544,21 +555,10 @@ async function performAction(action) {
     }
 
     // Проверяем доступность действия на фронтенде (для UX)
-    const availableActions = petData.availableActions;
-    if (availableActions) {
-        const actionMap = {
-            'feed': 'canFeed',
-            'play': 'canPlay',
-            'clean': 'canClean',
-            'heal': 'canHeal',
-            'sleep': 'canSleep',
-            'wakeup': 'canWakeUp'
-        };
-
-        if (!availableActions[actionMap[action]]) {
-            showNotification(getActionDisabledReason(action, availableActions), 'warning');
-            return;
-        }
+    const actionInfo = (petData.actions || []).find(a => a.name === action);
+    if (actionInfo && !actionInfo.available) {
+        showNotification(actionInfo.reason || 'Действие недоступно', 'warning');
+        return;
     }
 
     // Безопасный вызов HapticFeedback
@@ -577,6 +577,7 @@ async function performAction(action) {
                 'Content-Type': 'application/json',
                 'X-Telegram-Init-Data': tg.initData
             },
+            body: payload ? JSON.stringify(payload) : undefined,
             mode: 'cors'
         });
 
@@ -620,12 +621,21 @@ async function performAction(action) {
             showNotification(actionResult.actionFeedback, 'good');
         }
 
+        // Новости о сериях, заданиях и наградах показываем следом
+        if (Array.isArray(actionResult.notices) && actionResult.notices.length > 0) {
+            setTimeout(() => showNotification(actionResult.notices.join('\n'), 'good'), 1500);
+            loadStreak();
+        }
+        loadQuests();
+        loadHistory();
+
         if (tg.HapticFeedback && typeof tg.HapticFeedback.notificationOccurred === 'function') {
             tg.HapticFeedback.notificationOccurred('success');
         }
     } catch (error) {
         console.error(`Ошибка действия ${action}:`, error);
         showNotification(error.message || 'Не удалось выполнить действие', 'danger');
+        loadHistory();
 
         if (tg.HapticFeedback && typeof tg.HapticFeedback.notificationOccurred === 'function') {
             tg.HapticFeedback.notificationOccurred('error');
@@ -718,6 +728,7 @@ function updatePetDisplay() {
 
     // === Аватар из бэкенда ===
     updateAvatarFromBackend(pet.avatar);
+    updateSpeech(pet.speech);
 
     // === Статусное сообщение с бэкенда ===
     updateStatusFromBackend(status);
@@ -726,7 +737,13 @@ function updatePetDisplay() {
     updateCriticalEffectsFromBackend(status);
 
     // === Обновление доступных действий ===
-    updateAvailableActions(pet.availableActions);
+    updateActions(pet.actions);
+
+    // === Текущее занятие ===
+    updateActivity(pet.activity, pet.availableActions);
+
+    // === Трюки ===
+    updateTricks(pet.tricks, pet.availableActions);
 
     // === Интерфейс для мертвого питомца ===
     updateDeadPetInterface(pet.state === "dead");
@@ -769,6 +786,15 @@ function updateAvatarFromBackend(avatar) {
     moodIndicator.textContent = moodEmoji;
 }
 
+// Реплика питомца в облачке над статусом
+function updateSpeech(speech) {
+    const el = document.getElementById('petSpeech');
+    if (!el) return;
+
+    el.textContent = speech || '';
+    el.style.display = speech ? 'inline-block' : 'none';
+}
+
 // Обновление статуса из бэкенда
 function updateStatusFromBackend(status) {
     const statusEl = document.getElementById('statusMessage');
@@ -793,63 +819,848 @@ function updateCriticalEffectsFromBackend(status) {
     }
 }
 
-// Обновление доступности действий
-function updateAvailableActions(availableActions) {
-    if (!availableActions) return;
-
-    const actionMap = {
-        'feed': 'canFeed',
-        'play': 'canPlay',
-        'clean': 'canClean',
-        'heal': 'canHeal',
-        'sleep': 'canSleep',
-        'wakeup': 'canWakeUp'
+// Кнопки действий строятся из списка, который отдаёт бэкенд.
+// Действия с параметром (трюки, занятия) отрисовываются в своих секциях.
+function updateActions(actions) {
+    const grid = document.querySelector('.actions-grid');
+    if (!grid || !Array.isArray(actions)) return;
+
+    grid.innerHTML = '';
+    actions.filter(action => !action.param).forEach(action => {
+        const btn = document.createElement('button');
+        btn.className = `action-btn btn-${action.name}`;
+        btn.id = `${action.name}Btn`;
+        btn.setAttribute('aria-label', action.title);
+        btn.innerHTML = '<span></span><span></span>';
+        btn.children[0].textContent = action.emoji;
+        btn.children[1].textContent = action.title;
+        btn.disabled = !action.available;
+        btn.style.opacity = action.available ? '1' : '0.5';
+        btn.title = action.available ? '' : (action.reason || '');
+        btn.onclick = () => performAction(action.name);
+
+        // Подсказываем, что сейчас уход даст меньше (или больше) обычного
+        if (action.efficacy && action.efficacy !== 100) {
+            const badge = document.createElement('span');
+            badge.className = 'action-efficacy';
+            badge.textContent = `${action.efficacy}%`;
+            badge.title = 'Эффективность с учётом состояния питомца и повторов';
+            btn.appendChild(badge);
+        }
+
+        grid.appendChild(btn);
+    });
+
+    // Кнопки сна и пробуждения взаимоисключающие: показываем только одну
+    const wakeup = actions.find(action => action.name === 'wakeup');
+    const sleepBtn = document.getElementById('sleepBtn');
+    const wakeupBtn = document.getElementById('wakeupBtn');
+
+    if (sleepBtn && wakeupBtn && wakeup) {
+        sleepBtn.style.display = wakeup.available ? 'none' : 'flex';
+        wakeupBtn.style.display = wakeup.available ? 'flex' : 'none';
+    }
+}
+
+// Текущее занятие и оставшееся время
+let activityTimer = null;
+
+function updateActivity(activity, availableActions) {
+    const statusEl = document.getElementById('activityStatus');
+    const buttonsEl = document.getElementById('activityButtons');
+    if (!statusEl || !buttonsEl) return;
+
+    if (activityTimer) {
+        clearInterval(activityTimer);
+        activityTimer = null;
+    }
+
+    const canStart = availableActions ? availableActions.activity : false;
+    buttonsEl.querySelectorAll('button').forEach(btn => {
+        btn.disabled = !canStart;
+    });
+
+    if (!activity) {
+        statusEl.style.display = 'none';
+        return;
+    }
+
+    const endsAt = Date.now() + (activity.remainingSeconds || 0) * 1000;
+    const render = () => {
+        const left = Math.max(0, Math.round((endsAt - Date.now()) / 1000));
+        const minutes = Math.floor(left / 60);
+        const seconds = String(left % 60).padStart(2, '0');
+        const quality = activity.quality ? ` · качество сна ${activity.quality}%` : '';
+        statusEl.textContent = `⏳ ${activity.title}: осталось ${minutes}:${seconds}${quality}`;
+
+        if (left === 0 && activityTimer) {
+            clearInterval(activityTimer);
+            activityTimer = null;
+        }
     };
 
-    Object.keys(actionMap).forEach(action => {
-        const btn = document.getElementById(`${action}Btn`);
-        if (btn) {
-            const canPerform = availableActions[actionMap[action]];
-            btn.disabled = !canPerform;
-            btn.style.opacity = canPerform ? '1' : '0.5';
+    statusEl.style.display = 'block';
+    render();
+    activityTimer = setInterval(render, 1000);
+}
+
+// Отрисовка списка трюков
+function updateTricks(tricks, availableActions) {
+    const list = document.getElementById('tricksList');
+    if (!list || !Array.isArray(tricks)) return;
 
-            // Добавляем подсказку почему действие недоступно
-            if (!canPerform) {
-                btn.title = getActionDisabledReason(action, availableActions);
-            } else {
-                btn.title = '';
-            }
+    const canTrain = availableActions ? availableActions.train : false;
+
+    list.innerHTML = '';
+    tricks.forEach(trick => {
+        const row = document.createElement('div');
+        row.className = 'trick-row';
+
+        const info = document.createElement('div');
+        info.className = 'trick-info';
+        info.innerHTML = `
+            <div class="trick-title"></div>
+            <div class="progress-bar"><div class="progress-fill"></div></div>
+        `;
+        info.querySelector('.trick-title').textContent = `${trick.title} · ${trick.level}`;
+        info.querySelector('.progress-fill').style.width = `${Math.max(0, Math.min(100, trick.level))}%`;
+
+        const trainBtn = document.createElement('button');
+        trainBtn.className = 'trick-btn';
+        trainBtn.textContent = '📚 Учить';
+        trainBtn.disabled = !canTrain;
+        trainBtn.onclick = () => performAction('train', {trick: trick.name});
+
+        const performBtn = document.createElement('button');
+        performBtn.className = 'trick-btn';
+        performBtn.textContent = '✨ Показать';
+        performBtn.disabled = !trick.canPerform;
+        performBtn.title = trick.canPerform ? '' : 'Питомец ещё не выучил этот трюк';
+        performBtn.onclick = () => performAction('trick', {trick: trick.name});
+
+        row.append(info, trainBtn, performBtn);
+        list.appendChild(row);
+    });
+}
+
+// Загрузка серий ухода
+async function loadStreak() {
+    if (!tg || !tg.initData) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/streak/`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateStreak(apiResponse.data);
         }
+    } catch (error) {
+        console.error('Ошибка загрузки серий:', error);
+    }
+}
+
+function updateStreak(info) {
+    const el = document.getElementById('streakBar');
+    if (!el || !info) return;
+
+    el.innerHTML = '<span></span><span></span><span></span><span></span>';
+    el.children[0].textContent = `🔥 Чат: ${info.chat.current}`;
+    el.children[1].textContent = `👤 Вы: ${info.user.current}`;
+    el.children[2].textContent = `🧊 ${info.user.freezes}`;
+    el.children[3].textContent = `🪙 ${info.coins}`;
+    el.title = info.nextMilestone ? `До следующей награды: ${info.nextMilestone} дн.` : '';
+    el.style.display = 'flex';
+}
+
+// Загрузка ежедневных заданий
+async function loadQuests() {
+    if (!tg || !tg.initData) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/quests/`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateQuests(apiResponse.data);
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки заданий:', error);
+    }
+}
+
+function updateQuests(board) {
+    const list = document.getElementById('questsList');
+    if (!list || !board || !Array.isArray(board.quests)) return;
+
+    const icons = {active: '⬜', done: '✅', failed: '❌'};
+
+    list.innerHTML = '';
+    board.quests.forEach(quest => {
+        const row = document.createElement('div');
+        row.className = `quest-row ${quest.status}`;
+        row.innerHTML = `
+            <div class="trick-title"></div>
+            <div class="progress-bar"><div class="progress-fill"></div></div>
+        `;
+        row.querySelector('.trick-title').textContent =
+            `${icons[quest.status] || ''} ${quest.title} · ${quest.progress}/${quest.target} · +${quest.reward} 🪙`;
+        row.querySelector('.progress-fill').style.width =
+            `${Math.min(100, Math.round(quest.progress / Math.max(quest.target, 1) * 100))}%`;
+        list.appendChild(row);
     });
+}
 
-    // Обновляем кнопки сна/пробуждения
-    const sleepBtn = document.getElementById('sleepBtn');
-    const wakeupBtn = document.getElementById('wakeupBtn');
+// Загрузка идущих событий календаря
+async function loadEvents() {
+    if (!tg || !tg.initData) return;
 
-    if (sleepBtn && wakeupBtn) {
-        if (availableActions.canWakeUp) {
-            sleepBtn.style.display = 'none';
-            wakeupBtn.style.display = 'flex';
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/events/active`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateEvents(apiResponse.data);
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки событий:', error);
+    }
+}
+
+function updateEvents(events) {
+    const banner = document.getElementById('eventsBanner');
+    if (!banner) return;
+
+    banner.innerHTML = '';
+    if (!Array.isArray(events) || events.length === 0) {
+        banner.style.display = 'none';
+        return;
+    }
+
+    events.forEach(ev => {
+        const chip = document.createElement('div');
+        chip.className = 'event-chip';
+        chip.textContent = `${ev.emoji} ${ev.title}`;
+        chip.title = `До ${new Date(ev.endsAt).toLocaleDateString()}`;
+        banner.appendChild(chip);
+    });
+    banner.style.display = 'flex';
+}
+
+// Поделиться карточкой питомца в другом чате
+async function sharePetCard() {
+    if (!tg || !tg.initData) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/pet/card/`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (!apiResponse.success) {
+            showNotification(apiResponse.message || 'Не удалось поделиться', 'warning');
+            return;
+        }
+
+        const url = apiResponse.data.imageUrl;
+        const text = `Знакомьтесь: ${petData && petData.name ? petData.name : 'мой питомец'}!`;
+        const shareUrl = `https://t.me/share/url?url=${encodeURIComponent(url)}&text=${encodeURIComponent(text)}`;
+
+        if (typeof tg.openTelegramLink === 'function') {
+            tg.openTelegramLink(shareUrl);
         } else {
-            sleepBtn.style.display = 'flex';
-            wakeupBtn.style.display = 'none';
+            window.open(shareUrl, '_blank');
         }
+    } catch (error) {
+        console.error('Ошибка при создании карточки:', error);
     }
 }
 
-// Получение причины недоступности действия
-function getActionDisabledReason(action, availableActions) {
-    // Эти сообщения тоже можно получать с бэкенда в будущем
-    const reasons = {
-        'feed': !availableActions.canFeed ? 'Питомец не голоден или спит' : '',
-        'play': !availableActions.canPlay ? 'Питомец устал или уже счастлив' : '',
-        'clean': !availableActions.canClean ? 'Питомец уже чистый или спит' : '',
-        'heal': !availableActions.canHeal ? 'Питомец здоров или спит' : '',
-        'sleep': !availableActions.canSleep ? 'Питомец не устал или уже спит' : '',
-        'wakeup': !availableActions.canWakeUp ? 'Питомец не спит' : ''
-    };
+// Загрузка гардероба; с payload — покупка или смена аксессуара
+async function loadWardrobe(path = '', payload = null) {
+    if (!tg || !tg.initData) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/cosmetics/${path}`, {
+            method: payload ? 'POST' : 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            body: payload ? JSON.stringify(payload) : undefined,
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (!apiResponse.success) {
+            if (payload) showNotification(apiResponse.message || 'Ошибка гардероба', 'warning');
+            return;
+        }
+
+        updateWardrobe(apiResponse.data);
+
+        // Аватар собирается на сервере из надетых предметов — перезагружаем питомца.
+        if (payload) {
+            loadPetInfo();
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки гардероба:', error);
+    }
+}
+
+// Подарки: входящие, принять/отклонить и отправка
+async function loadGifts(path = '', payload = null) {
+    if (!tg || !tg.initData) return false;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/gifts/${path}`, {
+            method: payload ? 'POST' : 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            body: payload ? JSON.stringify(payload) : undefined,
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (!apiResponse.success) {
+            if (payload) showNotification(apiResponse.message || 'Ошибка подарков', 'warning');
+            return false;
+        }
+
+        updateGifts(apiResponse.data);
+
+        // Принятые монеты и предметы видны в гардеробе.
+        if (payload) {
+            loadWardrobe();
+        }
+
+        return true;
+    } catch (error) {
+        console.error('Ошибка загрузки подарков:', error);
+        return false;
+    }
+}
+
+function updateGifts(gifts) {
+    const list = document.getElementById('giftsList');
+    if (!list || !Array.isArray(gifts)) return;
+
+    list.innerHTML = '';
+    if (gifts.length === 0) {
+        list.textContent = 'Новых подарков нет';
+        return;
+    }
+
+    gifts.forEach(gift => {
+        const row = document.createElement('div');
+        row.className = 'gift-row';
+        row.innerHTML = `
+            <span class="gift-title"></span>
+            <button class="wardrobe-item" title="Принять">✅</button>
+            <button class="wardrobe-item" title="Отклонить">↩️</button>
+        `;
+        row.querySelector('.gift-title').textContent = `${gift.title} от ${gift.fromName}`;
+
+        const [accept, decline] = row.querySelectorAll('button');
+        accept.onclick = () => loadGifts('accept/', {id: gift.id});
+        decline.onclick = () => loadGifts('decline/', {id: gift.id});
+        list.appendChild(row);
+    });
+}
+
+async function sendGift(event) {
+    event.preventDefault();
+
+    const to = document.getElementById('giftTo');
+    const what = document.getElementById('giftWhat');
+
+    const sent = await loadGifts('send/', {to: to.value, what: what.value});
+    if (sent) {
+        showNotification('🎁 Подарок отправлен', 'good');
+        to.value = '';
+        what.value = '';
+    }
+}
+
+// Настройки предупреждений: тихие часы, режим, виды и пороги
+async function loadSettings(payload = null) {
+    if (!tg || !tg.initData) return false;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/settings/`, {
+            method: payload ? 'POST' : 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            body: payload ? JSON.stringify(payload) : undefined,
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (!apiResponse.success) {
+            if (payload) showNotification(apiResponse.message || 'Ошибка настроек', 'warning');
+            return false;
+        }
+
+        updateSettings(apiResponse.data);
+        return true;
+    } catch (error) {
+        console.error('Ошибка загрузки настроек:', error);
+        return false;
+    }
+}
+
+function updateSettings(settings) {
+    const list = document.getElementById('settingsAlerts');
+    if (!list || !settings || !Array.isArray(settings.alerts)) return;
+
+    document.getElementById('quietFrom').value = settings.quietFrom;
+    document.getElementById('quietTo').value = settings.quietTo;
+    document.getElementById('alertMode').value = settings.mode;
+
+    const timezone = document.getElementById('settingsTimezone');
+    if (timezone) timezone.textContent = settings.timezone || '';
+
+    list.innerHTML = '';
+    settings.alerts.forEach(alert => {
+        const row = document.createElement('label');
+        row.className = 'settings-row';
+        row.dataset.type = alert.type;
+        row.innerHTML = `
+            <input type="checkbox">
+            <span class="gift-title"></span>
+            ≤ <input class="gift-input settings-hour" type="number" min="0" max="100">
+        `;
+        row.querySelector('.gift-title').textContent = alert.title;
+        row.querySelector('input[type="checkbox"]').checked = alert.enabled;
+        row.querySelector('input[type="number"]').value = alert.threshold;
+        list.appendChild(row);
+    });
+}
+
+async function saveSettings(event) {
+    event.preventDefault();
+
+    const alerts = Array.from(document.querySelectorAll('#settingsAlerts .settings-row')).map(row => ({
+        type: row.dataset.type,
+        enabled: row.querySelector('input[type="checkbox"]').checked,
+        threshold: Number(row.querySelector('input[type="number"]').value)
+    }));
+
+    const saved = await loadSettings({
+        quietFrom: Number(document.getElementById('quietFrom').value),
+        quietTo: Number(document.getElementById('quietTo').value),
+        mode: document.getElementById('alertMode').value,
+        alerts
+    });
+    if (saved) {
+        showNotification('🔔 Настройки сохранены', 'good');
+    }
+}
+
+function updateWardrobe(wardrobe) {
+    const list = document.getElementById('wardrobeList');
+    if (!list || !wardrobe || !Array.isArray(wardrobe.items)) return;
+
+    const coins = document.getElementById('wardrobeCoins');
+    if (coins) coins.textContent = `🪙 ${wardrobe.coins} · личные ${wardrobe.own_coins || 0}`;
+
+    list.innerHTML = '';
+    wardrobe.items.forEach(item => {
+        const btn = document.createElement('button');
+        btn.className = 'wardrobe-item' + (item.equipped ? ' equipped' : '');
+
+        if (item.owned) {
+            btn.textContent = `${item.emoji} ${item.title}`;
+            btn.title = item.equipped ? 'Снять' : 'Надеть';
+            btn.onclick = () => loadWardrobe('equip/', {item: item.id, equip: !item.equipped});
+        } else {
+            btn.textContent = `${item.emoji} ${item.title} · ${item.price} 🪙`;
+            btn.title = item.event ? `Купить · только на ${item.event}` : 'Купить';
+            btn.disabled = wardrobe.coins < item.price && (wardrobe.own_coins || 0) < item.price;
+            btn.onclick = () => loadWardrobe('buy/', {item: item.id});
+        }
+
+        list.appendChild(btn);
+    });
+}
+
+// Загрузка табеля качества ухода
+async function loadReport() {
+    if (!tg || !tg.initData) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/pet/report/`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateReport(apiResponse.data);
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки табеля:', error);
+    }
+}
+
+// Отрисовка табеля: оценка за сегодня и полоска прошлых дней
+function updateReport(card) {
+    const el = document.getElementById('reportCard');
+    if (!el || !card || !card.today) return;
+
+    const today = card.today;
+    el.innerHTML = `
+        <div><span class="report-score"></span> <span class="report-grade"></span></div>
+        <div class="report-details"></div>
+        <div class="report-history"></div>
+    `;
+    el.querySelector('.report-score').textContent = `${today.score}/100`;
+    el.querySelector('.report-grade').textContent = today.grade;
+    el.querySelector('.report-details').textContent =
+        `🟡 ${today.warningMinutes} мин · 🔴 ${today.criticalMinutes} мин · ⚠️ ${today.respondedAlerts}/${today.alerts}`;
+
+    const history = el.querySelector('.report-history');
+    (card.history || []).forEach(day => {
+        const cell = document.createElement('span');
+        cell.textContent = day.score;
+        cell.title = `${new Date(day.day).toLocaleDateString()}: ${day.grade}`;
+        history.appendChild(cell);
+    });
+}
 
-    return reasons[action] || '';
+// Загрузка дневника питомца
+async function loadDiary() {
+    if (!tg || !tg.initData) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/pet/diary/`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateDiary(apiResponse.data);
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки дневника:', error);
+    }
+}
+
+// Отрисовка записей дневника, свежие сверху
+function updateDiary(entries) {
+    const el = document.getElementById('diaryList');
+    if (!el) return;
+
+    el.innerHTML = '';
+    if (!entries || entries.length === 0) {
+        el.textContent = 'Записей пока нет';
+        return;
+    }
+
+    entries.forEach(entry => {
+        const row = document.createElement('div');
+        row.className = 'diary-entry';
+
+        const day = document.createElement('div');
+        day.className = 'diary-day';
+        day.textContent = new Date(entry.day).toLocaleDateString();
+
+        const text = document.createElement('div');
+        text.textContent = entry.text;
+
+        row.append(day, text);
+        el.appendChild(row);
+    });
+}
+
+// Курсор следующей страницы журнала, 0 — страниц больше нет
+let historyCursor = 0;
+
+const historyStats = {
+    health: 'здоровье',
+    hunger: 'сытость',
+    happiness: 'счастье',
+    energy: 'энергия',
+    hygiene: 'гигиена'
+};
+
+// Загрузка журнала действий: первая страница или следующая по курсору
+async function loadHistory(more = false) {
+    if (!tg || !tg.initData) return;
+
+    const query = more && historyCursor ? `?before=${historyCursor}` : '';
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/pet/history/${query}`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateHistory(apiResponse.data, more);
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки журнала:', error);
+    }
+}
+
+// Отрисовка журнала, свежие сверху; следующая страница дописывается в конец
+function updateHistory(page, append) {
+    const el = document.getElementById('historyList');
+    const more = document.getElementById('historyMore');
+    if (!el) return;
+
+    const entries = (page && page.entries) || [];
+
+    if (!append) {
+        el.innerHTML = '';
+        if (entries.length === 0) {
+            el.textContent = 'Действий пока не было';
+        }
+    }
+
+    entries.forEach(entry => {
+        const row = document.createElement('div');
+        row.className = 'history-entry' + (entry.success ? '' : ' failed');
+
+        const info = (petData && petData.actions || []).find(a => a.name === entry.action);
+        const title = info ? `${info.emoji} ${info.title}` : entry.action;
+        const who = entry.actor && entry.actor.name ? entry.actor.name : 'кто-то';
+
+        const head = document.createElement('div');
+        head.className = 'history-head';
+        head.textContent = `${new Date(entry.at).toLocaleString()} · ${who} · ${title}`;
+
+        const changes = Object.keys(historyStats)
+            .filter(stat => entry.before[stat] !== entry.after[stat])
+            .map(stat => `${historyStats[stat]} ${entry.before[stat]} → ${entry.after[stat]}`);
+
+        const text = document.createElement('div');
+        text.textContent = [entry.message, changes.join(', ')].filter(Boolean).join('. ');
+
+        row.append(head, text);
+        el.appendChild(row);
+    });
+
+    historyCursor = (page && page.next) || 0;
+    if (more) {
+        more.style.display = historyCursor ? '' : 'none';
+    }
+}
+
+// Окно графиков, выбранное пользователем
+let statsWindow = 'day';
+
+const statsLines = {
+    health: { title: 'Здоровье', color: '#ef4444' },
+    hunger: { title: 'Сытость', color: '#f59e0b' },
+    happiness: { title: 'Счастье', color: '#ec4899' },
+    energy: { title: 'Энергия', color: '#10b981' },
+    hygiene: { title: 'Гигиена', color: '#3b82f6' }
+};
+
+// Загрузка рядов статов за день или неделю
+async function loadStats(name = statsWindow) {
+    if (!tg || !tg.initData) return;
+
+    statsWindow = name;
+    document.querySelectorAll('.stats-windows [data-window]').forEach(btn => {
+        btn.classList.toggle('equipped', btn.dataset.window === name);
+    });
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/pet/stats/?window=${name}`, {
+            method: 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (apiResponse.success) {
+            updateStatsChart(apiResponse.data);
+        }
+    } catch (error) {
+        console.error('Ошибка загрузки графиков:', error);
+    }
+}
+
+// Отрисовка графиков: по линии на стат, ось X — окно целиком, ось Y — от 0 до 100
+function updateStatsChart(series) {
+    const el = document.getElementById('statsChart');
+    const legend = document.getElementById('statsLegend');
+    if (!el) return;
+
+    const points = (series && series.points) || [];
+    if (points.length === 0) {
+        el.textContent = 'Замеров пока нет';
+        if (legend) legend.innerHTML = '';
+        return;
+    }
+
+    const width = 300;
+    const height = 120;
+    const from = new Date(series.from).getTime();
+    const span = Math.max(new Date(series.to).getTime() - from, 1);
+
+    const x = at => ((new Date(at).getTime() - from) / span * width).toFixed(1);
+    const y = value => (height - value / 100 * height).toFixed(1);
+
+    const svgNS = 'http://www.w3.org/2000/svg';
+    const svg = document.createElementNS(svgNS, 'svg');
+    svg.setAttribute('viewBox', `0 0 ${width} ${height}`);
+    svg.setAttribute('preserveAspectRatio', 'none');
+
+    Object.entries(statsLines).forEach(([stat, line]) => {
+        const polyline = document.createElementNS(svgNS, 'polyline');
+        polyline.setAttribute('points', points.map(p => `${x(p.at)},${y(p[stat])}`).join(' '));
+        polyline.setAttribute('stroke', line.color);
+        polyline.setAttribute('fill', 'none');
+        polyline.setAttribute('stroke-width', '1.5');
+        polyline.setAttribute('vector-effect', 'non-scaling-stroke');
+        svg.appendChild(polyline);
+    });
+
+    el.innerHTML = '';
+    el.appendChild(svg);
+
+    if (legend) {
+        const last = points[points.length - 1];
+
+        legend.innerHTML = '';
+        Object.entries(statsLines).forEach(([stat, line]) => {
+            const item = document.createElement('span');
+            item.className = 'stats-legend-item';
+            item.style.color = line.color;
+            item.textContent = `● ${line.title} ${last[stat]}`;
+            legend.appendChild(item);
+        });
+    }
+}
+
+// Последние загруженные рейтинги и выбранная вкладка
+let leaderboards = null;
+let leaderboardScope = 'global';
+
+// Загрузка рейтингов; hidden — переключить участие чата в общих рейтингах
+async function loadLeaderboards(hidden) {
+    if (!tg || !tg.initData) return;
+
+    const update = typeof hidden === 'boolean';
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/api/leaderboard/`, {
+            method: update ? 'POST' : 'GET',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-Telegram-Init-Data': tg.initData
+            },
+            body: update ? JSON.stringify({ hidden }) : undefined,
+            mode: 'cors'
+        });
+
+        const apiResponse = await response.json();
+        if (!apiResponse.success) {
+            throw new Error(apiResponse.message || 'Ошибка загрузки рейтингов');
+        }
+
+        leaderboards = apiResponse.data;
+        showLeaderboards(leaderboardScope);
+    } catch (error) {
+        console.error('Ошибка загрузки рейтингов:', error);
+        if (update) {
+            showNotification(error.message, 'danger');
+        }
+    }
+}
+
+function setLeaderboardHidden(hidden) {
+    loadLeaderboards(hidden);
+}
+
+// Отрисовка рейтингов выбранной вкладки: общие или своего чата
+function showLeaderboards(scope) {
+    leaderboardScope = scope;
+    document.querySelectorAll('.leaderboard-section [data-scope]').forEach(btn => {
+        btn.classList.toggle('equipped', btn.dataset.scope === scope);
+    });
+
+    const el = document.getElementById('leaderboardList');
+    const hidden = document.getElementById('leaderboardHidden');
+    if (!el || !leaderboards) return;
+
+    if (hidden) hidden.checked = leaderboards.hidden;
+
+    el.innerHTML = '';
+    (leaderboards[scope] || []).forEach(board => {
+        const block = document.createElement('div');
+        block.className = 'leaderboard';
+
+        const title = document.createElement('div');
+        title.className = 'diary-day';
+        title.textContent = board.title;
+        block.appendChild(title);
+
+        if (!board.entries || board.entries.length === 0) {
+            const empty = document.createElement('div');
+            empty.textContent = 'Пока пусто';
+            block.appendChild(empty);
+        }
+
+        (board.entries || []).forEach(entry => {
+            const row = document.createElement('div');
+            row.className = 'leaderboard-row' + (entry.own ? ' own' : '');
+            row.textContent = `${entry.rank}. ${entry.name} — ${entry.value} ${board.unit}`;
+            block.appendChild(row);
+        });
+
+        el.appendChild(block);
+    });
 }
 
 // Обновление интерфейса для мертвого питомца
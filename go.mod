@@ -9,6 +9,7 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.34.0
 	github.com/telegram-mini-apps/init-data-golang v1.5.0
+	golang.org/x/image v0.30.0
 )
 
 require (
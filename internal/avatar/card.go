@@ -0,0 +1,213 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+
+	"gocha/internal/entity"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Размеры карточки подобраны под превью ссылок Telegram (примерно 1.91:1).
+const (
+	cardWidth   = 800
+	cardHeight  = 420
+	cardPadding = 40
+	cardAvatar  = 340
+	cardRadius  = 24
+	barWidth    = 220
+	barHeight   = 16
+)
+
+var (
+	cardTop    = color.RGBA{R: 0x63, G: 0x66, B: 0xf1, A: 0xff}
+	cardBottom = color.RGBA{R: 0x8b, G: 0x5c, B: 0xf6, A: 0xff}
+	barTrack   = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x40}
+	statGood   = color.RGBA{R: 0x22, G: 0xc5, B: 0x5e, A: 0xff}
+	statWarn   = color.RGBA{R: 0xf5, G: 0x9e, B: 0x0b, A: 0xff}
+	statBad    = color.RGBA{R: 0xef, G: 0x44, B: 0x44, A: 0xff}
+)
+
+// Card Что изображено на карточке питомца.
+type Card struct {
+	Name     string
+	Subtitle string // Возраст и уровень.
+	Speech   string
+	Mood     string
+	Items    []entity.Cosmetic
+	Stats    []CardStat
+	Footer   string
+}
+
+// CardStat Полоска стата на карточке.
+type CardStat struct {
+	Title string
+	Value int
+}
+
+type cardFaces struct {
+	title, text, speech, small font.Face
+}
+
+var (
+	facesOnce sync.Once
+	faces     cardFaces
+	facesErr  error
+)
+
+// Card Рисует PNG-карточку питомца: аватар с аксессуарами, имя, подпись и полоски статов.
+func (c *Compositor) Card(card Card) ([]byte, error) {
+	facesOnce.Do(func() { faces, facesErr = loadFaces() })
+	if facesErr != nil {
+		return nil, facesErr
+	}
+
+	data, err := c.Compose(card.Mood, card.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	pic, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	for y := 0; y < cardHeight; y++ {
+		draw.Draw(img, image.Rect(0, y, cardWidth, y+1), image.NewUniform(blend(cardTop, cardBottom, y*255/cardHeight)), image.Point{}, draw.Src)
+	}
+
+	avatarTop := (cardHeight - cardAvatar) / 2
+	avatarRect := image.Rect(cardPadding, avatarTop, cardPadding+cardAvatar, avatarTop+cardAvatar)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, cardAvatar, cardAvatar))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), pic, pic.Bounds(), draw.Src, nil)
+	draw.DrawMask(img, avatarRect, scaled, image.Point{}, roundedMask(cardAvatar, cardAvatar, cardRadius), image.Point{}, draw.Over)
+
+	x := avatarRect.Max.X + cardPadding
+	white := image.NewUniform(color.White)
+
+	drawText(img, faces.title, white, x, avatarTop+40, card.Name)
+	drawText(img, faces.text, white, x, avatarTop+76, card.Subtitle)
+
+	y := avatarTop + 110
+	if card.Speech != "" {
+		drawText(img, faces.speech, white, x, y, "«"+card.Speech+"»")
+		y += 20
+	}
+
+	for _, stat := range card.Stats {
+		y += 38
+
+		drawText(img, faces.text, white, x, y, stat.Title)
+
+		bar := image.Rect(cardWidth-cardPadding-barWidth, y-barHeight, cardWidth-cardPadding, y)
+		draw.DrawMask(img, bar, image.NewUniform(barTrack), image.Point{}, roundedMask(barWidth, barHeight, barHeight/2), image.Point{}, draw.Over)
+
+		filled := barWidth * min(max(stat.Value, 0), 100) / 100
+		if filled > 0 {
+			fill := image.Rect(bar.Min.X, bar.Min.Y, bar.Min.X+filled, bar.Max.Y)
+			draw.DrawMask(img, fill, image.NewUniform(statColor(stat.Value)), image.Point{}, roundedMask(filled, barHeight, min(barHeight, filled)/2), image.Point{}, draw.Over)
+		}
+	}
+
+	if card.Footer != "" {
+		width := font.MeasureString(faces.small, card.Footer).Ceil()
+		drawText(img, faces.small, white, cardWidth-cardPadding-width, cardHeight-16, card.Footer)
+	}
+
+	var buf bytes.Buffer
+
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func loadFaces() (cardFaces, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return cardFaces{}, err
+	}
+
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return cardFaces{}, err
+	}
+
+	italic, err := opentype.Parse(goitalic.TTF)
+	if err != nil {
+		return cardFaces{}, err
+	}
+
+	var f cardFaces
+
+	for _, face := range []struct {
+		dst  *font.Face
+		src  *opentype.Font
+		size float64
+	}{
+		{&f.title, bold, 40},
+		{&f.text, regular, 22},
+		{&f.speech, italic, 20},
+		{&f.small, regular, 16},
+	} {
+		*face.dst, err = opentype.NewFace(face.src, &opentype.FaceOptions{Size: face.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return cardFaces{}, err
+		}
+	}
+
+	return f, nil
+}
+
+func drawText(dst draw.Image, face font.Face, src image.Image, x, y int, text string) {
+	d := font.Drawer{Dst: dst, Src: src, Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(text)
+}
+
+func statColor(value int) color.RGBA {
+	switch {
+	case value <= 20:
+		return statBad
+	case value <= 40:
+		return statWarn
+	default:
+		return statGood
+	}
+}
+
+func blend(a, b color.RGBA, t int) color.RGBA {
+	return color.RGBA{R: mix(a.R, b.R, t), G: mix(a.G, b.G, t), B: mix(a.B, b.B, t), A: 0xff}
+}
+
+// roundedMask Маска прямоугольника w×h со скруглёнными углами радиуса r.
+func roundedMask(w, h, r int) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cx := min(max(x, r), w-1-r)
+			cy := min(max(y, r), h-1-r)
+
+			dx, dy := x-cx, y-cy
+			if dx*dx+dy*dy <= r*r {
+				mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+			}
+		}
+	}
+
+	return mask
+}
@@ -0,0 +1,101 @@
+package avatar
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestStatColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value int
+		want  color.RGBA
+	}{
+		{name: "пусто", value: 0, want: statBad},
+		{name: "красная зона", value: 20, want: statBad},
+		{name: "жёлтая зона", value: 40, want: statWarn},
+		{name: "норма", value: 41, want: statGood},
+		{name: "полный", value: 100, want: statGood},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := statColor(tt.value); got != tt.want {
+				t.Errorf("statColor(%d) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundedMask(t *testing.T) {
+	t.Parallel()
+
+	mask := roundedMask(20, 10, 5)
+
+	tests := []struct {
+		name   string
+		at     image.Point
+		opaque bool
+	}{
+		{name: "угол срезан", at: image.Pt(0, 0), opaque: false},
+		{name: "противоположный угол срезан", at: image.Pt(19, 9), opaque: false},
+		{name: "середина края", at: image.Pt(10, 0), opaque: true},
+		{name: "центр", at: image.Pt(10, 5), opaque: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := mask.AlphaAt(tt.at.X, tt.at.Y).A == 0xff; got != tt.opaque {
+				t.Errorf("opaque at %v = %v, want %v", tt.at, got, tt.opaque)
+			}
+		})
+	}
+}
+
+func TestCompositor_Card(t *testing.T) {
+	t.Parallel()
+
+	stats := []CardStat{{Title: "Сытость", Value: 100}, {Title: "Счастье", Value: 30}, {Title: "Гигиена", Value: 0}}
+
+	data, err := NewCompositor(testAssets(t)).Card(Card{
+		Name:     "Гоча",
+		Subtitle: "3 дн. · ур. 2",
+		Speech:   "Привет!",
+		Mood:     defaultMood,
+		Stats:    stats,
+		Footer:   "@gocha_bot",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := decode(t, data)
+	if img.Bounds().Dx() != cardWidth || img.Bounds().Dy() != cardHeight {
+		t.Fatalf("Card() size = %v, want %dx%d", img.Bounds(), cardWidth, cardHeight)
+	}
+
+	// Полоски идут под подписью с речью: у каждой цвет по значению, пустая остаётся дорожкой.
+	avatarTop := (cardHeight - cardAvatar) / 2
+	y := avatarTop + 110 + 20
+	x := cardWidth - cardPadding - barWidth + barWidth/2
+
+	for _, stat := range stats {
+		y += 38
+
+		got := rgba(img, x, y-barHeight/2)
+		if colored := got == statColor(stat.Value); colored != (stat.Value > 50) {
+			t.Errorf("%s bar middle = %v, colored %v", stat.Title, got, colored)
+		}
+	}
+
+	if _, err = NewCompositor(testAssets(t)).Card(Card{Mood: "angry"}); err == nil {
+		t.Error("Card() with unknown mood succeeded")
+	}
+}
@@ -0,0 +1,229 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"strings"
+	"sync"
+
+	"gocha/internal/entity"
+)
+
+const (
+	Size         = 512  // Сторона собранной картинки.
+	sourceSize   = 1024 // Сторона исходных картинок настроения; в этих координатах заданы якоря.
+	defaultMood  = "default"
+	whiteCutoff  = 215 // Светлее этого пиксели картинки настроения считаются фоном и уступают место фону-аксессуару.
+	cosmeticsDir = "cosmetics"
+)
+
+var ErrUnknownMood = errors.New("неизвестное настроение")
+
+// anchor Точки на картинке настроения, к которым крепятся аксессуары.
+type anchor struct {
+	Head image.Point // Макушка: низ шапки.
+	Neck image.Point // Шея: центр ошейника.
+}
+
+// anchors Подобраны по картинкам настроения вручную.
+var anchors = map[string]anchor{
+	"default":  {Head: image.Pt(470, 650), Neck: image.Pt(470, 730)},
+	"happy":    {Head: image.Pt(450, 545), Neck: image.Pt(480, 690)},
+	"sad":      {Head: image.Pt(560, 615), Neck: image.Pt(580, 800)},
+	"sick":     {Head: image.Pt(560, 110), Neck: image.Pt(560, 370)},
+	"sleeping": {Head: image.Pt(420, 590), Neck: image.Pt(440, 740)},
+	"hungry":   {Head: image.Pt(390, 630), Neck: image.Pt(400, 700)},
+	"tired":    {Head: image.Pt(470, 650), Neck: image.Pt(470, 730)}, // Своей картинки нет, берётся default.
+	"dead":     {},                                                   // Аксессуары на умершего питомца не надеваются, картинка нужна для карточки.
+}
+
+// Compositor Собирает аватар из картинки настроения и аксессуаров. Результаты кешируются в памяти:
+// набор настроений и предметов конечен, поэтому кеш ограничен сам собой.
+type Compositor struct {
+	assets fs.FS
+
+	mu    sync.RWMutex
+	cache map[string][]byte
+}
+
+func NewCompositor(assets fs.FS) *Compositor {
+	return &Compositor{assets: assets, cache: make(map[string][]byte)}
+}
+
+// Compose Возвращает PNG: картинка настроения mood с предметами items, надетыми в порядке слоёв.
+func (c *Compositor) Compose(mood string, items []entity.Cosmetic) ([]byte, error) {
+	if _, ok := anchors[mood]; !ok {
+		return nil, ErrUnknownMood
+	}
+
+	key := cacheKey(mood, items)
+
+	c.mu.RLock()
+	data, ok := c.cache[key]
+	c.mu.RUnlock()
+
+	if ok {
+		return data, nil
+	}
+
+	data, err := c.compose(mood, items)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.cache[key] = data
+	c.mu.Unlock()
+
+	return data, nil
+}
+
+func (c *Compositor) compose(mood string, items []entity.Cosmetic) ([]byte, error) {
+	base, err := c.load(mood + ".png")
+	if errors.Is(err, fs.ErrNotExist) {
+		// Не для всех настроений есть своя картинка.
+		base, err = c.load(defaultMood + ".png")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := scale(base, Size, Size)
+
+	for _, slot := range entity.CosmeticSlots {
+		for _, item := range items {
+			if item.Slot != slot {
+				continue
+			}
+
+			layer, err := c.load(cosmeticsDir + "/" + item.ID + ".png")
+			if err != nil {
+				return nil, fmt.Errorf("cosmetic %s: %w", item.ID, err)
+			}
+
+			switch slot {
+			case entity.SlotBackground:
+				underlay(canvas, scale(layer, Size, Size))
+			case entity.SlotHat:
+				at := toCanvas(anchors[mood].Head)
+				b := layer.Bounds()
+				draw.Draw(canvas, b.Sub(b.Min).Add(image.Pt(at.X-b.Dx()/2, at.Y-b.Dy())), layer, b.Min, draw.Over)
+			case entity.SlotCollar:
+				at := toCanvas(anchors[mood].Neck)
+				b := layer.Bounds()
+				draw.Draw(canvas, b.Sub(b.Min).Add(image.Pt(at.X-b.Dx()/2, at.Y-b.Dy()/2)), layer, b.Min, draw.Over)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	err = png.Encode(&buf, canvas)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Compositor) load(name string) (image.Image, error) {
+	f, err := c.assets.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+
+	return img, nil
+}
+
+func cacheKey(mood string, items []entity.Cosmetic) string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return mood + "/" + strings.Join(ids, "+")
+}
+
+func toCanvas(p image.Point) image.Point {
+	return image.Pt(p.X*Size/sourceSize, p.Y*Size/sourceSize)
+}
+
+// underlay Подкладывает фон под светлые пиксели: чем белее пиксель, тем сильнее проступает фон.
+func underlay(dst *image.RGBA, bg *image.RGBA) {
+	for y := 0; y < Size; y++ {
+		for x := 0; x < Size; x++ {
+			p := dst.RGBAAt(x, y)
+
+			lightness := min(p.R, p.G, p.B)
+			if lightness <= whiteCutoff {
+				continue
+			}
+
+			t := int(lightness-whiteCutoff) * 255 / (255 - whiteCutoff)
+			q := bg.RGBAAt(x, y)
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: mix(p.R, q.R, t),
+				G: mix(p.G, q.G, t),
+				B: mix(p.B, q.B, t),
+				A: 255,
+			})
+		}
+	}
+}
+
+func mix(a, b uint8, t int) uint8 {
+	return uint8((int(a)*(255-t) + int(b)*t) / 255)
+}
+
+// scale Масштабирует картинку усреднением по площади: подходит и для уменьшения, и для увеличения.
+func scale(src image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sb := src.Bounds()
+
+	for y := 0; y < h; y++ {
+		y0 := sb.Min.Y + y*sb.Dy()/h
+		y1 := max(sb.Min.Y+(y+1)*sb.Dy()/h, y0+1)
+
+		for x := 0; x < w; x++ {
+			x0 := sb.Min.X + x*sb.Dx()/w
+			x1 := max(sb.Min.X+(x+1)*sb.Dx()/w, x0+1)
+
+			var r, g, b, a, n uint32
+
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += pr
+					g += pg
+					b += pb
+					a += pa
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,192 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"gocha/internal/entity"
+)
+
+var (
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.RGBA{A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+	red   = color.RGBA{R: 255, A: 255}
+)
+
+// solidPNG Однотонная картинка w×h; если body не пустой, в его границах она закрашена цветом fg.
+func solidPNG(t *testing.T, w, h int, bg, fg color.RGBA, body image.Rectangle) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := bg
+			if image.Pt(x, y).In(body) {
+				c = fg
+			}
+
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func testAssets(t *testing.T) fs.FS {
+	t.Helper()
+
+	// Питомец — чёрный квадрат в центре белой картинки.
+	return fstest.MapFS{
+		"default.png":               {Data: solidPNG(t, 64, 64, white, black, image.Rect(24, 24, 40, 40))},
+		"cosmetics/bg_sky.png":      {Data: solidPNG(t, 8, 8, blue, blue, image.Rectangle{})},
+		"cosmetics/hat_cap.png":     {Data: solidPNG(t, 10, 10, red, red, image.Rectangle{})},
+		"cosmetics/collar_red.png":  {Data: solidPNG(t, 10, 10, red, red, image.Rectangle{})},
+		"cosmetics/broken_item.png": {Data: []byte("not a png")},
+	}
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img
+}
+
+func rgba(img image.Image, x, y int) color.RGBA {
+	r, g, b, a := img.At(x, y).RGBA()
+
+	return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+}
+
+func TestCompositor_Compose(t *testing.T) {
+	t.Parallel()
+
+	sky := entity.Cosmetic{ID: "bg_sky", Slot: entity.SlotBackground}
+	hat := entity.Cosmetic{ID: "hat_cap", Slot: entity.SlotHat}
+	head := toCanvas(anchors[defaultMood].Head)
+
+	tests := []struct {
+		name    string
+		mood    string
+		items   []entity.Cosmetic
+		checks  map[image.Point]color.RGBA
+		wantErr bool
+	}{
+		{
+			name: "без предметов",
+			mood: defaultMood,
+			checks: map[image.Point]color.RGBA{
+				image.Pt(0, 0):           white,
+				image.Pt(Size/2, Size/2): black,
+			},
+		},
+		{
+			name:  "фон проступает только под белым",
+			mood:  defaultMood,
+			items: []entity.Cosmetic{sky},
+			checks: map[image.Point]color.RGBA{
+				image.Pt(0, 0):           blue,
+				image.Pt(Size/2, Size/2): black,
+			},
+		},
+		{
+			name:  "шапка садится на макушку поверх фона",
+			mood:  defaultMood,
+			items: []entity.Cosmetic{hat, sky},
+			checks: map[image.Point]color.RGBA{
+				image.Pt(head.X, head.Y-1): red,
+				image.Pt(0, 0):             blue,
+			},
+		},
+		{
+			name: "настроение без своей картинки берёт default",
+			mood: "tired",
+			checks: map[image.Point]color.RGBA{
+				image.Pt(Size/2, Size/2): black,
+			},
+		},
+		{
+			name:    "неизвестное настроение",
+			mood:    "angry",
+			wantErr: true,
+		},
+		{
+			name:    "битый предмет",
+			mood:    defaultMood,
+			items:   []entity.Cosmetic{{ID: "broken_item", Slot: entity.SlotHat}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := NewCompositor(testAssets(t)).Compose(tt.mood, tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compose() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			img := decode(t, data)
+			if img.Bounds().Dx() != Size || img.Bounds().Dy() != Size {
+				t.Fatalf("Compose() size = %v, want %dx%d", img.Bounds(), Size, Size)
+			}
+
+			for at, want := range tt.checks {
+				if got := rgba(img, at.X, at.Y); got != want {
+					t.Errorf("pixel %v = %v, want %v", at, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompositor_Cache(t *testing.T) {
+	t.Parallel()
+
+	assets := testAssets(t).(fstest.MapFS)
+	c := NewCompositor(assets)
+	items := []entity.Cosmetic{{ID: "hat_cap", Slot: entity.SlotHat}}
+
+	first, err := c.Compose(defaultMood, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Из кеша картинка отдаётся, даже если исходник пропал.
+	delete(assets, "cosmetics/hat_cap.png")
+
+	second, err := c.Compose(defaultMood, items)
+	if err != nil || !bytes.Equal(first, second) {
+		t.Errorf("cached Compose() = %d bytes, %v; want the first result", len(second), err)
+	}
+
+	if _, err = c.Compose(defaultMood, nil); err != nil {
+		t.Errorf("Compose() without items error = %v", err)
+	}
+
+	_, err = NewCompositor(fstest.MapFS{}).Compose(defaultMood, nil)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Compose() without assets error = %v, want fs.ErrNotExist", err)
+	}
+}
@@ -13,10 +13,21 @@ type Configuration struct {
 	TgToken          string `env:"TG_TOKEN"            env-required:"true" yaml:"tg_token"`
 	DbDataSourceName string `env:"DB_DATA_SOURCE_NAME" env-required:"true" yaml:"db_data_source_name"`
 	AlertCooldown    int    `env:"ALERT_COOLDOWN"      env-required:"true" yaml:"alert_cooldown"`
+	DigestWindow     int    `env:"DIGEST_WINDOW"       env-default:"60"    yaml:"digest_window"` // Минуты, за которые копится сводка.
 	UpdateInterval   int    `env:"UPDATE_INTERVAL"     env-required:"true" yaml:"update_interval"`
 	Host             string `env:"HOST"                env-required:"true" yaml:"host"`
 	Port             int    `env:"PORT"                env-required:"true" yaml:"port"`
 	BaseUrl          string `env:"BASE_URL"            env-required:"true" yaml:"host"`
+	CardSecret       string `env:"CARD_SECRET"         yaml:"card_secret"`   // Ключ ссылок на карточки; без него выводится из токена бота.
+	CalendarFile     string `env:"CALENDAR_FILE"       yaml:"calendar_file"` // JSON с событиями календаря вместо встроенных.
+	SchedulerWorkers int    `env:"SCHEDULER_WORKERS"   env-default:"4"     yaml:"scheduler_workers"`
+	SchedulerBatch   int    `env:"SCHEDULER_BATCH"     env-default:"100"   yaml:"scheduler_batch"`
+	LazyStats        bool   `env:"LAZY_STATS"          yaml:"lazy_stats"` // Статы считаются при чтении, фон будит чат только у порогов.
+	SMTPHost         string `env:"SMTP_HOST"           yaml:"smtp_host"`  // Без хоста уведомления на почту отключены.
+	SMTPPort         int    `env:"SMTP_PORT"           env-default:"587"   yaml:"smtp_port"`
+	SMTPUser         string `env:"SMTP_USER"           yaml:"smtp_user"`
+	SMTPPassword     string `env:"SMTP_PASSWORD"       yaml:"smtp_password"`
+	SMTPFrom         string `env:"SMTP_FROM"           yaml:"smtp_from"`
 	IsDev            bool   `env:"IS_DEV"`
 }
 
@@ -0,0 +1,8 @@
+package entity
+
+// Alert Предупреждение о состоянии питомца и действия, которые его снимают.
+type Alert struct {
+	Type    string       `json:"type"`
+	Message string       `json:"message"`
+	Actions []ActionInfo `json:"actions,omitempty"`
+}
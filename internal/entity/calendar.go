@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// DecayModifiers Скорость убывания статов во время события в процентах от обычной: 0 — не убывает, 150 — в полтора раза быстрее.
+// Отсутствующий стат убывает как обычно.
+type DecayModifiers struct {
+	Hunger    *int `json:"hunger,omitempty"`
+	Energy    *int `json:"energy,omitempty"`
+	Hygiene   *int `json:"hygiene,omitempty"`
+	Happiness *int `json:"happiness,omitempty"`
+}
+
+// CalendarEvent Событие календаря: праздник, повторяющийся каждый год, или день рождения питомца.
+type CalendarEvent struct {
+	ID           string         `json:"id"`
+	Title        string         `json:"title"`
+	Emoji        string         `json:"emoji"`
+	Announcement string         `json:"announcement"`    // Объявление в чат; ровно один %s — имя питомца, знак процента — %%.
+	Start        string         `json:"start,omitempty"` // Первый день в формате MM-DD.
+	End          string         `json:"end,omitempty"`   // Последний день в формате MM-DD, может быть в следующем году.
+	Birthday     bool           `json:"birthday,omitempty"`
+	Decay        DecayModifiers `json:"decay"`
+	Items        []string       `json:"items,omitempty"` // Тематические аксессуары, которые продаются только во время события.
+	Decor        []string       `json:"decor,omitempty"` // Аксессуары, которые питомец носит на время события, если место свободно.
+}
+
+// ActiveEvent Идущее сейчас событие.
+type ActiveEvent struct {
+	ID           string         `json:"id"`
+	Title        string         `json:"title"`
+	Emoji        string         `json:"emoji"`
+	Announcement string         `json:"announcement"`
+	StartedAt    time.Time      `json:"startedAt"`
+	EndsAt       time.Time      `json:"endsAt"`
+	Decay        DecayModifiers `json:"decay"`
+	Items        []string       `json:"items,omitempty"`
+	Decor        []string       `json:"decor,omitempty"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+// CosmeticSlot Место аксессуара на аватаре. На каждое место надевается не больше одного предмета.
+type CosmeticSlot string
+
+const (
+	SlotBackground CosmeticSlot = "background"
+	SlotCollar     CosmeticSlot = "collar"
+	SlotHat        CosmeticSlot = "hat"
+)
+
+// CosmeticSlots Порядок слоёв на аватаре: от нижнего к верхнему.
+var CosmeticSlots = []CosmeticSlot{SlotBackground, SlotCollar, SlotHat}
+
+// Cosmetic Предмет гардероба питомца.
+type Cosmetic struct {
+	ID       string       `json:"id"`
+	Slot     CosmeticSlot `json:"slot"`
+	Title    string       `json:"title"`
+	Emoji    string       `json:"emoji"`
+	Price    int          `json:"price"`
+	Owned    bool         `json:"owned"`
+	Equipped bool         `json:"equipped"`
+	Event    string       `json:"event,omitempty"` // Событие, во время которого продаётся тематический предмет.
+}
+
+// Wardrobe Каталог предметов с отметками о покупке, баланс кошелька чата и личных монет покупателя.
+type Wardrobe struct {
+	Coins    int        `json:"coins"`
+	OwnCoins int        `json:"own_coins"`
+	Items    []Cosmetic `json:"items"`
+}
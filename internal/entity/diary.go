@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// DiaryFacts Факты дня, из которых питомец пишет дневник.
+type DiaryFacts struct {
+	Care         map[string]map[string]int `json:"care"`       // Действие -> кто -> сколько раз.
+	SadMoments   []time.Time               `json:"sadMoments"` // Когда питомцу стало грустно.
+	SleptMinutes int                       `json:"sleptMinutes"`
+	MinHealth    int                       `json:"minHealth"`
+	EndHappiness int                       `json:"endHappiness"`
+	Sad          bool                      `json:"sad"` // Грустит ли питомец на момент последнего замера.
+	LastSampleAt time.Time                 `json:"lastSampleAt"`
+}
+
+// DiaryEntry Запись в дневнике питомца за день.
+type DiaryEntry struct {
+	Day   time.Time  `json:"day"`
+	Text  string     `json:"text"`
+	Facts DiaryFacts `json:"-"`
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -30,32 +31,73 @@ type PetStatus struct {
 	CanPerformAction bool   `json:"canPerformAction"`
 }
 
-// AvailableActions Информация о доступных действиях.
-type AvailableActions struct {
-	CanFeed   bool `json:"canFeed"`
-	CanPlay   bool `json:"canPlay"`
-	CanClean  bool `json:"canClean"`
-	CanHeal   bool `json:"canHeal"`
-	CanSleep  bool `json:"canSleep"`
-	CanWakeUp bool `json:"canWakeUp"`
+// ActionInfo Описание действия для UI.
+type ActionInfo struct {
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	Emoji     string `json:"emoji"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`   // Почему действие сейчас недоступно.
+	Efficacy  int    `json:"efficacy,omitempty"` // Эффективность ухода в процентах с учётом состояния и повторов.
+	Param     string `json:"param,omitempty"`    // Имя параметра, если действие его требует.
+}
+
+// Activity Текущее занятие питомца (сон, прогулка, ветеринар, школа).
+type Activity struct {
+	Kind             string        `json:"kind"`
+	Title            string        `json:"title"`
+	StartedAt        time.Time     `json:"startedAt"`
+	Duration         time.Duration `json:"duration"`
+	EndsAt           time.Time     `json:"endsAt"`
+	RemainingSeconds int           `json:"remainingSeconds"`
+	Quality          int           `json:"quality,omitempty"` // Среднее качество сна в процентах.
+
+	Minutes      int `json:"minutes"`
+	QualityTotal int `json:"qualityTotal,omitempty"`
+	EnergyCarry  int `json:"energyCarry,omitempty"`
+}
+
+// Skill Сохранённый уровень трюка.
+type Skill struct {
+	Level         int       `json:"level"`
+	LastPracticed time.Time `json:"lastPracticed"`
+}
+
+// TrickInfo Информация о трюке для UI.
+type TrickInfo struct {
+	Name       string `json:"name"`
+	Title      string `json:"title"`
+	Level      int    `json:"level"`
+	CanPerform bool   `json:"canPerform"`
 }
 
 type Pet struct {
-	Name             string           `json:"name"`
-	Health           int              `json:"health"`
-	Hunger           int              `json:"hunger"`
-	Happiness        int              `json:"happiness"`
-	Energy           int              `json:"energy"`
-	Hygiene          int              `json:"hygiene"`
-	State            State            `json:"state"`
-	SleepStartTime   time.Time        `json:"sleepStartTime"`
-	Config           PetConfig        `json:"config"`
-	LastUpdated      time.Time        `json:"lastUpdated"`
-	Age              int              `json:"age"`
-	Avatar           Avatar           `json:"avatar"`
-	Status           PetStatus        `json:"status"`
-	AvailableActions AvailableActions `json:"availableActions"`
-	UIConfig         UIConfig         `json:"uiConfig"`
+	ID               int                    `json:"id"`
+	Name             string                 `json:"name"`
+	Health           int                    `json:"health"`
+	Hunger           int                    `json:"hunger"`
+	Happiness        int                    `json:"happiness"`
+	Energy           int                    `json:"energy"`
+	Hygiene          int                    `json:"hygiene"`
+	State            State                  `json:"state"`
+	SleepStartTime   time.Time              `json:"sleepStartTime"`
+	Skills           map[string]Skill       `json:"-"`
+	RecentCare       map[string][]time.Time `json:"-"`
+	Tricks           []TrickInfo            `json:"tricks"`
+	Activity         *Activity              `json:"activity"`
+	Config           PetConfig              `json:"config"`
+	LastUpdated      time.Time              `json:"lastUpdated"`
+	Age              int                    `json:"age"`
+	CreatedAt        time.Time              `json:"createdAt"`
+	Version          int                    `json:"-"` // Номер сохранения: снимок пишется, только если его никто не обогнал.
+	Avatar           Avatar                 `json:"avatar"`
+	Cosmetics        []string               `json:"cosmetics"`        // Надетые аксессуары в порядке слоёв.
+	Speech           string                 `json:"speech,omitempty"` // Что питомец говорит сейчас.
+	Events           []ActiveEvent          `json:"events,omitempty"` // Идущие сейчас события календаря.
+	Status           PetStatus              `json:"status"`
+	AvailableActions map[string]bool        `json:"availableActions"`
+	Actions          []ActionInfo           `json:"actions"`
+	UIConfig         UIConfig               `json:"uiConfig"`
 }
 
 type PetConfig struct {
@@ -81,9 +123,6 @@ func (pet *Pet) UpdateStatus() {
 	// Генерируем статусное сообщение
 	pet.generateStatusMessage()
 
-	// Определяем доступные действия
-	pet.updateAvailableActions()
-
 	// Устанавливаем UI конфигурацию
 	pet.UIConfig = UIConfig{
 		CriticalThreshold: 20,
@@ -122,19 +161,19 @@ func (pet *Pet) generateStatusMessage() {
 	}
 }
 
-// Определение доступных действий.
-func (pet *Pet) updateAvailableActions() {
-	isDead := pet.State == PetDead
-	isSleeping := pet.State == PetSleeping
-
-	pet.AvailableActions = AvailableActions{
-		CanFeed:   !isDead && !isSleeping && pet.Hunger < 100,
-		CanPlay:   !isDead && !isSleeping && pet.Energy > 20 && pet.Happiness < 100,
-		CanClean:  !isDead && !isSleeping && pet.Hygiene < 100,
-		CanHeal:   !isDead && !isSleeping && pet.Health < 100,
-		CanSleep:  !isDead && !isSleeping && pet.Energy <= 30,
-		CanWakeUp: !isDead && isSleeping,
+// Level Уровень питомца: растёт с возрастом (неделя — уровень) и с выученными трюками (сто очков — уровень).
+func (pet *Pet) Level() int {
+	skills := 0
+	for _, skill := range pet.Skills {
+		skills += skill.Level
 	}
+
+	return 1 + pet.Age/7 + skills/100
+}
+
+// IsBusy Питомец занят чем-то кроме сна.
+func (pet *Pet) IsBusy() bool {
+	return pet.Activity != nil && pet.Activity.Kind != "sleep"
 }
 
 func (pet *Pet) GetAvatar(baseURL string) {
@@ -144,6 +183,7 @@ func (pet *Pet) GetAvatar(baseURL string) {
 			Image: baseURL + "/dead.png",
 			Emoji: "💀",
 			Mood:  "💀",
+			Base:  "dead",
 		}
 
 		return
@@ -151,9 +191,10 @@ func (pet *Pet) GetAvatar(baseURL string) {
 
 	if pet.State == PetSleeping {
 		pet.Avatar = Avatar{
-			Image: baseURL + "/sleeping.png",
+			Image: pet.avatarImage(baseURL, "sleeping"),
 			Emoji: "😴",
 			Mood:  "💤",
+			Base:  "sleeping",
 		}
 
 		return
@@ -165,113 +206,57 @@ func (pet *Pet) GetAvatar(baseURL string) {
 	var img, emoji, moodEmoji string
 
 	if pet.Health <= 20 {
-		img = baseURL + "/sick.png"
+		img = "sick"
 		emoji = "🤒"
 		moodEmoji = "🤒"
 	} else if pet.Energy <= 20 {
-		img = baseURL + "/tired.png"
+		img = "tired"
 		emoji = "😴"
 		moodEmoji = "😴"
 	} else if avg >= 80 {
-		img = baseURL + "/happy.png"
+		img = "happy"
 		emoji = "😸"
 		moodEmoji = "😸"
 	} else if avg >= 60 {
-		img = baseURL + "/default.png"
+		img = "default"
 		emoji = "🐱"
 		moodEmoji = "😊"
 	} else if avg >= 40 {
-		img = baseURL + "/default.png"
+		img = "default"
 		emoji = "🐱"
 		moodEmoji = "😐"
 	} else {
-		img = baseURL + "/sad.png"
+		img = "sad"
 		emoji = "🙀"
 		moodEmoji = "😿"
 	}
 
 	pet.Avatar = Avatar{
-		Image: img,
+		Image: pet.avatarImage(baseURL, img),
 		Emoji: emoji,
 		Mood:  moodEmoji,
+		Base:  img,
 	}
 
 	pet.UpdateStatus()
 }
 
-// CanPerformAction Метод для проверки возможности выполнения конкретного действия.
-func (pet *Pet) CanPerformAction(action string) (bool, string) {
-	if pet.State == PetDead {
-		return false, "Питомец мертв"
+// avatarImage Картинка настроения; с надетыми аксессуарами — собранная сервером: /avatar/<настроение>/<предмет>+<предмет>.png.
+func (pet *Pet) avatarImage(baseURL, mood string) string {
+	if len(pet.Cosmetics) == 0 {
+		return baseURL + "/" + mood + ".png"
 	}
 
-	switch action {
-	case "feed":
-		if pet.State == PetSleeping {
-			return false, "Питомец спит"
-		}
-		if pet.Hunger >= 100 {
-			return false, "Питомец не голоден"
-		}
-
-		return true, ""
-
-	case "play":
-		if pet.State == PetSleeping {
-			return false, "Питомец спит"
-		}
-		if pet.Energy <= 20 {
-			return false, "Питомец слишком устал"
-		}
-		if pet.Happiness >= 100 {
-			return false, "Питомец уже счастлив"
-		}
-		return true, ""
-
-	case "clean":
-		if pet.State == PetSleeping {
-			return false, "Питомец спит"
-		}
-		if pet.Hygiene >= 100 {
-			return false, "Питомец уже чистый"
-		}
-		return true, ""
-
-	case "heal":
-		if pet.State == PetSleeping {
-			return false, "Питомец спит"
-		}
-		if pet.Health >= 100 {
-			return false, "Питомец здоров"
-		}
-		return true, ""
-
-	case "sleep":
-		if pet.State == PetSleeping {
-			return false, "Питомец уже спит"
-		}
-		if pet.Energy > 30 {
-			return false, "Питомец не устал"
-		}
-		return true, ""
-
-	case "wakeup":
-		if pet.State != PetSleeping {
-			return false, "Питомец не спит"
-		}
-		return true, ""
-
-	default:
-		return false, "Неизвестное действие"
-	}
+	return baseURL + "/avatar/" + mood + "/" + strings.Join(pet.Cosmetics, "+") + ".png"
 }
 
 // PetActionResult Обновленная структура результата действия.
 type PetActionResult struct {
-	Pet            *Pet   `json:"pet"`
-	Result         Result `json:"result"`
-	Avatar         Avatar `json:"avatar"`
-	ActionFeedback string `json:"actionFeedback"` // Специальное сообщение для действия
+	Pet            *Pet     `json:"pet"`
+	Result         Result   `json:"result"`
+	Avatar         Avatar   `json:"avatar"`
+	ActionFeedback string   `json:"actionFeedback"`    // Специальное сообщение для действия
+	Notices        []string `json:"notices,omitempty"` // Серии, награды и прочие новости после действия
 }
 
 func (r *PetActionResult) GetAvatar(baseURL string) {
@@ -283,25 +268,11 @@ func (r *PetActionResult) GetAvatar(baseURL string) {
 }
 
 // GenerateActionFeedback Генерация обратной связи для действия.
-func (r *PetActionResult) GenerateActionFeedback(action string) {
-	actionNames := map[string]string{
-		"feed":   "покормили",
-		"play":   "поиграли",
-		"clean":  "помыли",
-		"heal":   "вылечили",
-		"sleep":  "уложили спать",
-		"wakeup": "разбудили",
-	}
-
-	name := actionNames[action]
-	if name == "" {
-		name = action
-	}
-
+func (r *PetActionResult) GenerateActionFeedback(feedback string) {
 	if r.Result.Success {
-		r.ActionFeedback = fmt.Sprintf("🎉 Успешно %s!", name)
+		r.ActionFeedback = fmt.Sprintf("🎉 Успешно %s!", feedback)
 	} else {
-		r.ActionFeedback = fmt.Sprintf("❌ Не удалось %s", name)
+		r.ActionFeedback = fmt.Sprintf("❌ Не удалось %s", feedback)
 	}
 }
 
@@ -356,8 +327,14 @@ type Result struct {
 	Message string `json:"message"`
 }
 
+// ShareCard Публичная ссылка на картинку-карточку питомца.
+type ShareCard struct {
+	ImageURL string `json:"imageUrl"`
+}
+
 type Avatar struct {
 	Image string `json:"image"`
 	Emoji string `json:"emoji"` // Основной эмодзи аватара
 	Mood  string `json:"mood"`
+	Base  string `json:"-"` // Имя картинки настроения без расширения: happy, sad…
 }
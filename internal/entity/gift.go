@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+type GiftKind string
+
+const (
+	GiftCoins GiftKind = "coins"
+	GiftItem  GiftKind = "item"
+)
+
+type GiftStatus string
+
+const (
+	GiftPending  GiftStatus = "pending"
+	GiftAccepted GiftStatus = "accepted"
+	GiftDeclined GiftStatus = "declined"
+)
+
+// Gift Подарок питомцу другого чата. Пока подарок ждёт ответа, монеты или предмет списаны у отправителя
+// и хранятся в самом подарке: при отказе они возвращаются. Если вернуть предмет нельзя — у отправителя уже есть
+// такой же, — ему возвращается цена предмета на момент отправки.
+type Gift struct {
+	ID         int64        `json:"id"`
+	FromChatID int          `json:"fromChatId"`
+	FromUserID int64        `json:"fromUserId"`
+	FromName   string       `json:"fromName"`
+	ToChatID   int          `json:"toChatId"`
+	Kind       GiftKind     `json:"kind"`
+	Item       string       `json:"item,omitempty"`
+	Slot       CosmeticSlot `json:"slot,omitempty"`
+	Amount     int          `json:"amount,omitempty"` // Число монет; у предмета — его цена.
+	Title      string       `json:"title"`
+	Status     GiftStatus   `json:"status"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	Refunded   bool         `json:"refunded,omitempty"` // Отклонённый предмет вернулся отправителю монетами.
+}
+
+// GiftLimits Ограничения против злоупотреблений подарками.
+type GiftLimits struct {
+	Since        time.Time // Начало суток, за которые считаются подарки отправителя.
+	PerDay       int       // Подарков от одного пользователя в сутки.
+	CoinsPerDay  int       // Монет от одного пользователя в сутки.
+	PendingPerTo int       // Неразобранных подарков у получателя.
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+// StatSnapshot Статы питомца в момент действия.
+type StatSnapshot struct {
+	Health    int   `json:"health"`
+	Hunger    int   `json:"hunger"`
+	Happiness int   `json:"happiness"`
+	Energy    int   `json:"energy"`
+	Hygiene   int   `json:"hygiene"`
+	State     State `json:"state"`
+}
+
+// HistoryEntry Запись журнала действий: кто, что сделал с питомцем и чем это кончилось.
+type HistoryEntry struct {
+	ID      int64             `json:"id"`
+	Actor   Actor             `json:"actor"`
+	Action  string            `json:"action"`
+	Params  map[string]string `json:"params,omitempty"`
+	Before  StatSnapshot      `json:"before"`
+	After   StatSnapshot      `json:"after"`
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	At      time.Time         `json:"at"`
+}
+
+// HistoryPage Страница журнала, от новых записей к старым.
+type HistoryPage struct {
+	Entries []HistoryEntry `json:"entries"`
+	Next    int64          `json:"next,omitempty"` // Курсор следующей страницы: ID, до которого читать дальше.
+}
+
+// Stats Снимок статов питомца для журнала.
+func (pet *Pet) Stats() StatSnapshot {
+	return StatSnapshot{
+		Health:    pet.Health,
+		Hunger:    pet.Hunger,
+		Happiness: pet.Happiness,
+		Energy:    pet.Energy,
+		Hygiene:   pet.Hygiene,
+		State:     pet.State,
+	}
+}
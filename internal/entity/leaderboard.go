@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// LeaderboardCategory Вид рейтинга.
+type LeaderboardCategory string
+
+const (
+	LeaderboardAge    LeaderboardCategory = "age"    // Самый старый живой питомец.
+	LeaderboardLevel  LeaderboardCategory = "level"  // Самый высокий уровень.
+	LeaderboardCare   LeaderboardCategory = "care"   // Лучший уход за неделю.
+	LeaderboardStreak LeaderboardCategory = "streak" // Самая длинная серия ухода.
+)
+
+// LeaderboardEntry Место в рейтинге: питомец чата в общем рейтинге или участник в рейтинге группы.
+type LeaderboardEntry struct {
+	Rank   int    `json:"rank"`
+	ChatID int    `json:"-"`
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	Value  int    `json:"value"`
+	Own    bool   `json:"own,omitempty"` // Это свой чат или сам пользователь.
+}
+
+// Leaderboard Рейтинг одного вида.
+type Leaderboard struct {
+	Category LeaderboardCategory `json:"category"`
+	Title    string              `json:"title"`
+	Unit     string              `json:"unit"`
+	Entries  []LeaderboardEntry  `json:"entries"`
+}
+
+// Leaderboards Общие рейтинги всех чатов и рейтинги участников своей группы.
+type Leaderboards struct {
+	Global    []Leaderboard `json:"global"`
+	Chat      []Leaderboard `json:"chat"`
+	Hidden    bool          `json:"hidden"` // Чат скрыт из общих рейтингов.
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// ChannelKind Вид канала уведомлений.
+type ChannelKind string
+
+const (
+	ChannelWebhook ChannelKind = "webhook"
+	ChannelEmail   ChannelKind = "email"
+)
+
+// NotifyChannel Дополнительный канал уведомлений чата: сообщения в Telegram приходят всегда, а сюда — копией.
+type NotifyChannel struct {
+	Kind      ChannelKind `json:"kind"`
+	Target    string      `json:"target"` // URL вебхука или адрес почты.
+	Secret    string      `json:"-"`      // Ключ подписи вебхука.
+	CreatedAt time.Time   `json:"createdAt"`
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+type QuestStatus string
+
+const (
+	QuestActive QuestStatus = "active"
+	QuestDone   QuestStatus = "done"
+	QuestFailed QuestStatus = "failed"
+)
+
+// Quest Ежедневное задание чата.
+type Quest struct {
+	ID       string      `json:"id"`
+	Kind     string      `json:"kind"`
+	Title    string      `json:"title"`
+	Progress int         `json:"progress"`
+	Target   int         `json:"target"`
+	Status   QuestStatus `json:"status"`
+	Reward   int         `json:"reward"` // Монеты в кошелёк чата.
+
+	Action    string `json:"action,omitempty"`    // Действие, которое засчитывается.
+	Stat      string `json:"stat,omitempty"`      // Стат, который нужно удерживать.
+	Threshold int    `json:"threshold,omitempty"` // Порог стата или минимальный сон в минутах.
+	From      int    `json:"from,omitempty"`      // С какого часа по местному времени держать стат.
+	Until     int    `json:"until,omitempty"`     // До какого часа по местному времени держать стат.
+}
+
+// QuestBoard Задания на день по местному времени чата.
+type QuestBoard struct {
+	Day      time.Time `json:"day"`
+	Quests   []Quest   `json:"quests"`
+	ResetsAt time.Time `json:"resetsAt"`
+}
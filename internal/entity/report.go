@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// CareReport Дневная сводка качества ухода за питомцем в чате.
+type CareReport struct {
+	Day             time.Time `json:"day"`
+	Score           int       `json:"score"` // Оценка ухода от 0 до 100.
+	Grade           string    `json:"grade"`
+	ObservedMinutes int       `json:"observedMinutes"` // Сколько минут питомец был под наблюдением.
+	WarningMinutes  int       `json:"warningMinutes"`  // Время в жёлтой зоне.
+	CriticalMinutes int       `json:"criticalMinutes"` // Время в красной зоне.
+	NeglectMinutes  int       `json:"neglectMinutes"`  // Время без ухода дольше допустимого.
+	Alerts          int       `json:"alerts"`
+	RespondedAlerts int       `json:"respondedAlerts"`
+	ResponseSeconds int       `json:"-"` // Суммарное время реакции на предупреждения.
+	AvgResponseMin  int       `json:"avgResponseMinutes"`
+	Actions         int       `json:"actions"`
+
+	LastActionAt   time.Time `json:"lastActionAt"`
+	PendingAlertAt time.Time `json:"-"` // Предупреждение, на которое ещё не отреагировали.
+	LastSampleAt   time.Time `json:"-"`
+}
+
+// CareReportCard Табель ухода: сегодняшний день и история за неделю.
+type CareReportCard struct {
+	Today   CareReport   `json:"today"`
+	History []CareReport `json:"history"`
+}
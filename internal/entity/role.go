@@ -0,0 +1,18 @@
+package entity
+
+// Role Роль участника группового чата в уходе за питомцем.
+type Role string
+
+const (
+	RoleCook        Role = "cook"
+	RoleDoctor      Role = "doctor"
+	RoleEntertainer Role = "entertainer"
+	RoleJanitor     Role = "janitor"
+)
+
+// ChatRole Роль, назначенная участнику чата.
+type ChatRole struct {
+	UserID int64  `json:"userId"`
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+}
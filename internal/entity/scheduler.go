@@ -0,0 +1,15 @@
+package entity
+
+// SchedulerStats Состояние очереди замеров питомцев.
+type SchedulerStats struct {
+	Chats      int     `json:"chats"`      // Чатов под наблюдением.
+	QueueDepth int     `json:"queueDepth"` // Чатов в очереди, не считая обрабатываемых.
+	Due        int     `json:"due"`        // Из них уже пора обработать.
+	InFlight   int     `json:"inFlight"`   // Обрабатываются воркерами прямо сейчас.
+	Lag        float64 `json:"lagSeconds"` // Насколько просрочен самый старый чат в очереди.
+	LastLag    float64 `json:"lastBatchLagSeconds"`
+	Workers    int     `json:"workers"`
+	BatchSize  int     `json:"batchSize"`
+	Processed  int64   `json:"processed"`
+	Batches    int64   `json:"batches"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// StatPoint Статы питомца в момент замера или средние за отрезок времени.
+type StatPoint struct {
+	At        time.Time `json:"at"`
+	Health    int       `json:"health"`
+	Hunger    int       `json:"hunger"`
+	Happiness int       `json:"happiness"`
+	Energy    int       `json:"energy"`
+	Hygiene   int       `json:"hygiene"`
+}
+
+// StatSeries Ряд статов питомца за окно: точка на каждый отрезок step, где были замеры.
+type StatSeries struct {
+	Window string      `json:"window"`
+	Step   int         `json:"step"` // Длина отрезка в секундах.
+	From   time.Time   `json:"from"`
+	To     time.Time   `json:"to"`
+	Points []StatPoint `json:"points"`
+}
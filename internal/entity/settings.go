@@ -0,0 +1,42 @@
+package entity
+
+// AlertMode Как присылать предупреждения: каждое отдельно или одной сводкой за окно сводки.
+type AlertMode string
+
+const (
+	AlertInstant AlertMode = "instant"
+	AlertDigest  AlertMode = "digest"
+)
+
+// AlertPreference Настройка одного вида предупреждений.
+type AlertPreference struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Enabled   bool   `json:"enabled"`
+	Threshold int    `json:"threshold"` // Предупреждать, когда стат опустился до порога.
+}
+
+// NotifySettings Настройки предупреждений чата. Тихие часы — по часовому поясу чата, с QuietFrom до QuietTo;
+// если они равны, тихих часов нет.
+type NotifySettings struct {
+	QuietFrom int               `json:"quietFrom"`
+	QuietTo   int               `json:"quietTo"`
+	Mode      AlertMode         `json:"mode"`
+	Alerts    []AlertPreference `json:"alerts"`
+	Timezone  string            `json:"timezone,omitempty"` // Только для показа; меняется через /timezone.
+}
+
+// AlertPreferenceUpdate Изменение настройки одного вида предупреждений: nil-поля остаются как были.
+type AlertPreferenceUpdate struct {
+	Type      string `json:"type"`
+	Enabled   *bool  `json:"enabled,omitempty"`
+	Threshold *int   `json:"threshold,omitempty"`
+}
+
+// NotifySettingsUpdate Изменение настроек предупреждений чата: меняются только переданные поля и виды.
+type NotifySettingsUpdate struct {
+	QuietFrom *int                    `json:"quietFrom,omitempty"`
+	QuietTo   *int                    `json:"quietTo,omitempty"`
+	Mode      *AlertMode              `json:"mode,omitempty"`
+	Alerts    []AlertPreferenceUpdate `json:"alerts,omitempty"`
+}
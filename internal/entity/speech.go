@@ -0,0 +1,194 @@
+package entity
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Черты характера питомца. Выводятся из имени и номера питомца, поэтому постоянны.
+const (
+	TraitPlayful = "playful"
+	TraitLazy    = "lazy"
+	TraitGlutton = "glutton"
+	TraitGrumpy  = "grumpy"
+)
+
+var traits = []string{TraitPlayful, TraitLazy, TraitGlutton, TraitGrumpy}
+
+const recentCareWindow = 15 * time.Minute
+
+// phraseRule Условие, при котором питомец может сказать фразу из набора Key.
+// Срочные правила (голод, болезнь) вытесняют все остальные.
+type phraseRule struct {
+	Key    string
+	Weight int
+	Urgent bool
+	When   func(pet *Pet, now time.Time) bool
+}
+
+var phraseRules = []phraseRule{
+	{Key: "sleeping", Weight: 1, Urgent: true, When: func(p *Pet, _ time.Time) bool { return p.State == PetSleeping }},
+	{Key: "sick", Weight: 10, Urgent: true, When: func(p *Pet, _ time.Time) bool { return p.Health <= 20 }},
+	{Key: "starving", Weight: 10, Urgent: true, When: func(p *Pet, _ time.Time) bool { return p.Hunger <= 20 }},
+	{Key: "exhausted", Weight: 8, Urgent: true, When: func(p *Pet, _ time.Time) bool { return p.Energy <= 20 }},
+	{Key: "sad", Weight: 8, Urgent: true, When: func(p *Pet, _ time.Time) bool { return p.Happiness <= 20 }},
+	{Key: "filthy", Weight: 6, Urgent: true, When: func(p *Pet, _ time.Time) bool { return p.Hygiene <= 20 }},
+	{Key: "busy", Weight: 6, When: func(p *Pet, _ time.Time) bool { return p.IsBusy() }},
+	{Key: "hungry", Weight: 4, When: func(p *Pet, _ time.Time) bool { return p.Hunger <= 40 }},
+	{Key: "dirty", Weight: 3, When: func(p *Pet, _ time.Time) bool { return p.Hygiene <= 40 }},
+	{Key: "bored", Weight: 3, When: func(p *Pet, _ time.Time) bool { return p.Happiness <= 40 }},
+	{Key: "happy", Weight: 4, When: func(p *Pet, _ time.Time) bool { return p.averageStats() >= 80 }},
+	{Key: "fed", Weight: 6, When: func(p *Pet, now time.Time) bool { return p.caredRecently("feed", now) }},
+	{Key: "played", Weight: 6, When: func(p *Pet, now time.Time) bool { return p.caredRecently("play", now) }},
+	{Key: "cleaned", Weight: 5, When: func(p *Pet, now time.Time) bool { return p.caredRecently("clean", now) }},
+	{Key: "healed", Weight: 5, When: func(p *Pet, now time.Time) bool { return p.caredRecently("heal", now) }},
+	{Key: "morning", Weight: 2, When: func(_ *Pet, now time.Time) bool { return now.Hour() >= 6 && now.Hour() < 11 }},
+	{Key: "night", Weight: 2, When: func(_ *Pet, now time.Time) bool { return now.Hour() >= 22 || now.Hour() < 6 }},
+	{Key: TraitPlayful, Weight: 2, When: func(p *Pet, _ time.Time) bool { return p.Trait() == TraitPlayful }},
+	{Key: TraitLazy, Weight: 2, When: func(p *Pet, _ time.Time) bool { return p.Trait() == TraitLazy }},
+	{Key: TraitGlutton, Weight: 2, When: func(p *Pet, _ time.Time) bool { return p.Trait() == TraitGlutton }},
+	{Key: TraitGrumpy, Weight: 2, When: func(p *Pet, _ time.Time) bool { return p.Trait() == TraitGrumpy }},
+	{Key: "idle", Weight: 1, When: func(_ *Pet, _ time.Time) bool { return true }},
+}
+
+// phrasePacks Наборы фраз по языкам.
+var phrasePacks = map[string]map[string][]string{
+	"ru": {
+		"sleeping":   {"Хр-р-р…", "Zzz…", "Мур… сплю…"},
+		"sick":       {"Мне так плохо…", "Кажется, я заболел. Вылечи меня!", "Кхе-кхе…"},
+		"starving":   {"Я умираю с голоду!", "Еды! Срочно!", "Живот урчит на весь дом!"},
+		"exhausted":  {"Сил совсем нет…", "Уложи меня спать, пожалуйста", "Лапки не держат…"},
+		"sad":        {"Мне грустно…", "Со мной никто не играет…", "Обними меня"},
+		"filthy":     {"Я весь чешусь!", "Фу, от меня пахнет…", "Помой меня, пожалуйста!"},
+		"busy":       {"Я занят, не отвлекай!", "Скоро вернусь!", "Столько всего интересного!"},
+		"hungry":     {"Я бы перекусил", "Что там у нас на обед?", "Чем-то вкусным пахнет…"},
+		"dirty":      {"Пора бы искупаться", "Кажется, я испачкался"},
+		"bored":      {"Скучно…", "Давай чем-нибудь займёмся?"},
+		"happy":      {"Жизнь прекрасна!", "Я тебя люблю!", "Мур-мур!"},
+		"fed":        {"Ням! Спасибо!", "Вкуснотища!", "Я сыт и доволен"},
+		"played":     {"Ещё! Ещё!", "Это было весело!", "Давай ещё поиграем!"},
+		"cleaned":    {"Я весь блестящий!", "Как хорошо быть чистым!"},
+		"healed":     {"Мне уже лучше!", "Спасибо, доктор!"},
+		"morning":    {"Доброе утро!", "Проснись и пой!"},
+		"night":      {"Уже поздно…", "Звёзды такие красивые"},
+		TraitPlayful: {"Поиграем?", "Догони меня!"},
+		TraitLazy:    {"Полежу ещё немножко…", "Куда торопиться?"},
+		TraitGlutton: {"А добавка будет?", "Я думаю о еде. Всегда."},
+		TraitGrumpy:  {"Ну что опять?", "Хм."},
+		"idle":       {"Привет!", "Как дела?", "Я тут!"},
+	},
+	"en": {
+		"sleeping":   {"Zzz…", "Snore…", "Purr… sleeping…"},
+		"sick":       {"I feel so bad…", "I think I'm sick. Heal me!", "Cough, cough…"},
+		"starving":   {"I'm starving!", "Food! Now!", "My tummy is rumbling!"},
+		"exhausted":  {"I'm so tired…", "Put me to bed, please", "Can't keep my eyes open…"},
+		"sad":        {"I'm sad…", "Nobody plays with me…", "Hug me"},
+		"filthy":     {"I'm so itchy!", "Ugh, I smell…", "Please give me a bath!"},
+		"busy":       {"I'm busy, don't distract me!", "Be right back!", "So much to see!"},
+		"hungry":     {"I could eat something", "What's for lunch?", "Something smells tasty…"},
+		"dirty":      {"Time for a bath", "I think I got dirty"},
+		"bored":      {"I'm bored…", "Let's do something?"},
+		"happy":      {"Life is wonderful!", "I love you!", "Purr-purr!"},
+		"fed":        {"Yum! Thanks!", "Delicious!", "Full and happy"},
+		"played":     {"More! More!", "That was fun!", "Let's play again!"},
+		"cleaned":    {"I'm all shiny!", "It feels great to be clean!"},
+		"healed":     {"I feel better already!", "Thanks, doc!"},
+		"morning":    {"Good morning!", "Rise and shine!"},
+		"night":      {"It's getting late…", "The stars are so pretty"},
+		TraitPlayful: {"Wanna play?", "Catch me!"},
+		TraitLazy:    {"Just five more minutes…", "What's the rush?"},
+		TraitGlutton: {"Is there a second helping?", "I'm thinking about food. Always."},
+		TraitGrumpy:  {"What now?", "Hmph."},
+		"idle":       {"Hi!", "How are you?", "I'm here!"},
+	},
+}
+
+// Trait Черта характера питомца.
+func (pet *Pet) Trait() string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(pet.Name))
+	_, _ = h.Write([]byte{byte(pet.ID), byte(pet.ID >> 8)})
+
+	return traits[h.Sum32()%uint32(len(traits))]
+}
+
+// Speak Выбирает, что питомец скажет сейчас, и записывает фразу в Speech.
+// Фразы из recent не повторяются, пока есть другие подходящие.
+func (pet *Pet) Speak(lang string, now time.Time, recent []string, rnd *rand.Rand) string {
+	pet.Speech = ""
+
+	if pet.State == PetDead {
+		return ""
+	}
+
+	pack, ok := phrasePacks[lang]
+	if !ok {
+		pack = phrasePacks["ru"]
+	}
+
+	type candidate struct {
+		text   string
+		weight int
+	}
+
+	var candidates, urgent []candidate
+
+	for _, rule := range phraseRules {
+		if !rule.When(pet, now) {
+			continue
+		}
+
+		for _, text := range pack[rule.Key] {
+			c := candidate{text: text, weight: rule.Weight}
+			candidates = append(candidates, c)
+
+			if rule.Urgent {
+				urgent = append(urgent, c)
+			}
+		}
+	}
+
+	if len(urgent) > 0 {
+		candidates = urgent
+	}
+
+	fresh := slices.DeleteFunc(slices.Clone(candidates), func(c candidate) bool {
+		return slices.Contains(recent, c.text)
+	})
+	if len(fresh) > 0 {
+		candidates = fresh
+	}
+
+	total := 0
+	for _, c := range candidates {
+		total += c.weight
+	}
+
+	if total == 0 {
+		return ""
+	}
+
+	n := rnd.IntN(total)
+	for _, c := range candidates {
+		n -= c.weight
+		if n < 0 {
+			pet.Speech = c.text
+
+			break
+		}
+	}
+
+	return pet.Speech
+}
+
+func (pet *Pet) averageStats() int {
+	return (pet.Hunger + pet.Happiness + pet.Hygiene + pet.Health + pet.Energy) / 5
+}
+
+func (pet *Pet) caredRecently(action string, now time.Time) bool {
+	times := pet.RecentCare[action]
+
+	return len(times) > 0 && now.Sub(times[len(times)-1]) < recentCareWindow
+}
@@ -0,0 +1,136 @@
+package entity
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPet_Speak(t *testing.T) {
+	t.Parallel()
+
+	noon := time.Date(2024, time.March, 1, 14, 0, 0, 0, time.UTC)
+	healthy := func() *Pet {
+		return &Pet{ID: 1, Name: "Гоча", State: PetAlive, Health: 100, Hunger: 100, Happiness: 100, Energy: 100, Hygiene: 100}
+	}
+
+	tests := []struct {
+		name   string
+		pet    func() *Pet
+		lang   string
+		now    time.Time
+		recent []string
+		want   []string // Допустимые фразы.
+	}{
+		{
+			name: "мёртвый питомец молчит",
+			pet: func() *Pet {
+				p := healthy()
+				p.State = PetDead
+
+				return p
+			},
+			lang: "ru",
+			now:  noon,
+			want: []string{""},
+		},
+		{
+			name: "голод вытесняет всё остальное",
+			pet: func() *Pet {
+				p := healthy()
+				p.Hunger = 10
+
+				return p
+			},
+			lang: "ru",
+			now:  noon,
+			want: phrasePacks["ru"]["starving"],
+		},
+		{
+			name: "спящий питомец только сопит",
+			pet: func() *Pet {
+				p := healthy()
+				p.State = PetSleeping
+
+				return p
+			},
+			lang: "en",
+			now:  noon,
+			want: phrasePacks["en"]["sleeping"],
+		},
+		{
+			name: "недавние фразы не повторяются",
+			pet: func() *Pet {
+				p := healthy()
+				p.Hunger = 10
+
+				return p
+			},
+			lang:   "ru",
+			now:    noon,
+			recent: phrasePacks["ru"]["starving"][:2],
+			want:   phrasePacks["ru"]["starving"][2:],
+		},
+		{
+			name: "если всё уже сказано, повтор лучше молчания",
+			pet: func() *Pet {
+				p := healthy()
+				p.Hunger = 10
+
+				return p
+			},
+			lang:   "ru",
+			now:    noon,
+			recent: phrasePacks["ru"]["starving"],
+			want:   phrasePacks["ru"]["starving"],
+		},
+		{
+			name: "после кормления благодарит",
+			pet: func() *Pet {
+				p := healthy()
+				p.Hunger = 60
+				p.RecentCare = map[string][]time.Time{"feed": {noon.Add(-time.Minute)}}
+
+				return p
+			},
+			lang: "de",
+			now:  noon,
+			// Неизвестный язык — русский набор; обычные правила смешиваются с весами.
+			want: slices.Concat(phrasePacks["ru"]["fed"], phrasePacks["ru"]["happy"], phrasePacks["ru"][healthy().Trait()],
+				phrasePacks["ru"]["idle"]),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			for seed := range uint64(20) {
+				pet := tt.pet()
+
+				got := pet.Speak(tt.lang, tt.now, tt.recent, rand.New(rand.NewPCG(seed, seed)))
+				if !slices.Contains(tt.want, got) {
+					t.Fatalf("Speak() = %q, want one of %q", got, tt.want)
+				}
+
+				if pet.Speech != got {
+					t.Errorf("Speech = %q, want %q", pet.Speech, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPet_Trait(t *testing.T) {
+	t.Parallel()
+
+	pet := &Pet{ID: 7, Name: "Гоча"}
+	if pet.Trait() != (&Pet{ID: 7, Name: "Гоча"}).Trait() {
+		t.Error("Trait() differs for the same pet")
+	}
+
+	if !slices.Contains(traits, pet.Trait()) {
+		t.Errorf("Trait() = %q, want one of %q", pet.Trait(), traits)
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// Actor Пользователь, совершивший действие. ID = 0, если пользователь неизвестен.
+type Actor struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username,omitempty"` // Имя в Telegram без @, по нему находят получателя подарка.
+}
+
+// Streak Серия дней подряд хотя бы с одним действием ухода.
+type Streak struct {
+	Current int       `json:"current"`
+	Best    int       `json:"best"`
+	Freezes int       `json:"freezes"` // Заморозки прощают пропущенный день.
+	LastDay time.Time `json:"lastDay"`
+}
+
+// StreakInfo Серии чата и пользователя вместе с его монетами.
+type StreakInfo struct {
+	Chat      Streak `json:"chat"`
+	User      Streak `json:"user"`
+	Coins     int    `json:"coins"`
+	Milestone int    `json:"nextMilestone"` // Сколько дней нужно до следующей награды пользователю.
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Visit Встреча питомцев двух чатов: гость приходит к хозяину по приглашению.
+type Visit struct {
+	HostChatID    int       `json:"hostChatId"`
+	GuestChatID   int       `json:"guestChatId"`
+	HostPet       string    `json:"hostPet"`
+	GuestPet      string    `json:"guestPet"`
+	Compatibility int       `json:"compatibility"` // Совместимость характеров в процентах.
+	Bonus         int       `json:"bonus"`         // Прибавка счастья каждому питомцу.
+	At            time.Time `json:"at"`
+}
+
+// PlaydateInvite Приглашение в гости: код, который чат-хозяин передаёт другому чату.
+type PlaydateInvite struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
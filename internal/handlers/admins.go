@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/rs/zerolog"
+)
+
+// ChatAdmins Проверяет, администрирует ли пользователь группу.
+type ChatAdmins interface {
+	IsChatAdmin(ctx context.Context, chatID, userID int64) bool
+}
+
+// TelegramChatAdmins Узнаёт администраторов группы у Telegram.
+type TelegramChatAdmins struct {
+	bot    *telego.Bot
+	logger zerolog.Logger
+}
+
+func NewTelegramChatAdmins(bot *telego.Bot, logger zerolog.Logger) *TelegramChatAdmins {
+	return &TelegramChatAdmins{bot: bot, logger: logger}
+}
+
+// IsChatAdmin Администратор или создатель группы.
+func (a *TelegramChatAdmins) IsChatAdmin(ctx context.Context, chatID, userID int64) bool {
+	member, err := a.bot.GetChatMember(ctx, &telego.GetChatMemberParams{ChatID: tu.ID(chatID), UserID: userID})
+	if err != nil {
+		a.logger.Error().Err(err).Msg("can't get chat member")
+
+		return false
+	}
+
+	status := member.MemberStatus()
+
+	return status == telego.MemberStatusCreator || status == telego.MemberStatusAdministrator
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gocha/internal/avatar"
+	"gocha/internal/entity"
+	"gocha/internal/service"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	avatarMaxAge = "public, max-age=604800"
+	cardMaxAge   = "public, max-age=300"
+
+	cardSecretLabel = "gocha card links" // Метка ключа ссылок на карточки, выводимого из токена бота.
+)
+
+type AvatarHandlers struct {
+	s          *service.Service
+	compositor *avatar.Compositor
+	logger     zerolog.Logger
+	baseUrl    string
+	secret     []byte // Ключ подписи публичных ссылок на карточки.
+	botToken   string // Ключ проверки initData.
+	isDev      bool
+}
+
+func NewAvatarHandlers(logger zerolog.Logger, s *service.Service, compositor *avatar.Compositor, baseUrl, secret, botToken string,
+	isDev bool,
+) *AvatarHandlers {
+	return &AvatarHandlers{
+		logger:     logger,
+		s:          s,
+		compositor: compositor,
+		baseUrl:    baseUrl,
+		secret:     cardSecret(secret, botToken),
+		botToken:   botToken,
+		isDev:      isDev,
+	}
+}
+
+// ComposedAvatarHandler Отдаёт аватар с аксессуарами: /static/avatar/<настроение>/<предмет>+<предмет>.png.
+// Картинка однозначно задаётся адресом, поэтому её можно кешировать в браузере.
+func (h *AvatarHandlers) ComposedAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	mood, list, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/static/avatar/"), "/")
+	if !ok || !strings.HasSuffix(list, ".png") {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	items, ok := parseCosmetics(strings.TrimSuffix(list, ".png"))
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	etag := `"` + mood + "/" + strings.TrimSuffix(list, ".png") + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	data, err := h.compositor.Compose(mood, items)
+	if err != nil {
+		if errors.Is(err, avatar.ErrUnknownMood) {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("can't compose avatar")
+		http.Error(w, "Не удалось собрать аватар", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", avatarMaxAge)
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write(data)
+}
+
+// PetCardLinkHandler Публичная ссылка на карточку питомца, которой можно поделиться в другом чате.
+func (h *AvatarHandlers) PetCardLinkHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.ShareCard]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.ShareCard]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	chatID := getPetID(parseData)
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.ShareCard]{
+		Success: true,
+		Data: entity.ShareCard{
+			ImageURL: fmt.Sprintf("%s/card/%d/%s.png", h.baseUrl, chatID, h.cardToken(chatID)),
+		},
+	})
+}
+
+// PetCardHandler Отдаёт PNG-карточку питомца по подписанной ссылке /card/<chat_id>/<подпись>.png.
+func (h *AvatarHandlers) PetCardHandler(w http.ResponseWriter, r *http.Request) {
+	id, token, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/card/"), ".png"), "/")
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	chatID, err := strconv.Atoi(id)
+	if err != nil || !hmac.Equal([]byte(token), []byte(h.cardToken(chatID))) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	data, err := renderPetCard(r.Context(), h.s, h.compositor, chatID)
+	if err != nil {
+		if errors.Is(err, service.ErrPetNotFound) {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("can't render card")
+		http.Error(w, "Не удалось нарисовать карточку", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", cardMaxAge)
+	_, _ = w.Write(data)
+}
+
+// cardSecret Ключ подписи ссылок на карточки: заданный в настройках, а без него — выведенный из токена бота.
+// Сам токен ключом не служит: подпись ссылки не должна ничего говорить о ключе, которым бот подписывает initData.
+func cardSecret(secret, botToken string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+
+	mac := hmac.New(sha256.New, []byte(botToken))
+	mac.Write([]byte(cardSecretLabel))
+
+	return mac.Sum(nil)
+}
+
+func (h *AvatarHandlers) cardToken(chatID int) string {
+	mac := hmac.New(sha256.New, h.secret)
+	_, _ = fmt.Fprintf(mac, "card:%d", chatID)
+
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// renderPetCard Карточка питомца чата: общая для Mini App и ответов бота.
+func renderPetCard(ctx context.Context, s *service.Service, compositor *avatar.Compositor, chatID int) ([]byte, error) {
+	pet, err := s.LoadPet(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	pet.GetAvatar("")
+	speech := s.Speak(ctx, chatID, pet, "")
+
+	var items []entity.Cosmetic
+
+	if pet.State != entity.PetDead {
+		for _, id := range pet.Cosmetics {
+			if item, ok := service.LookupCosmetic(id); ok {
+				items = append(items, item)
+			}
+		}
+	}
+
+	// В шрифтах Go нет эмодзи, поэтому подписи на карточке без них.
+	return compositor.Card(avatar.Card{
+		Name:     pet.Name,
+		Subtitle: fmt.Sprintf("Возраст: %d дн. · Уровень %d", pet.Age, pet.Level()),
+		Speech:   speech,
+		Mood:     pet.Avatar.Base,
+		Items:    items,
+		Stats: []avatar.CardStat{
+			{Title: "Сытость", Value: pet.Hunger},
+			{Title: "Счастье", Value: pet.Happiness},
+			{Title: "Энергия", Value: pet.Energy},
+			{Title: "Гигиена", Value: pet.Hygiene},
+			{Title: "Здоровье", Value: pet.Health},
+		},
+		Footer: "Gocha · Тамагочи в Telegram",
+	})
+}
+
+// parseCosmetics Разбирает список предметов; на каждое место допускается один предмет.
+func parseCosmetics(list string) ([]entity.Cosmetic, bool) {
+	var items []entity.Cosmetic
+
+	for _, id := range strings.Split(list, "+") {
+		item, ok := service.LookupCosmetic(id)
+		if !ok {
+			return nil, false
+		}
+
+		if slices.ContainsFunc(items, func(c entity.Cosmetic) bool { return c.Slot == item.Slot }) {
+			return nil, false
+		}
+
+		items = append(items, item)
+	}
+
+	return items, true
+}
@@ -1,17 +1,995 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gocha/internal/avatar"
+	"gocha/internal/entity"
+	"gocha/internal/notify"
+	"gocha/internal/service"
+
 	"github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
 	tu "github.com/mymmrac/telego/telegoutil"
+	"github.com/rs/zerolog"
+)
+
+const (
+	alertCallbackPrefix    = "alert:"    // Кнопки предупреждений; за префиксом — имя действия.
+	settingsCallbackPrefix = "settings:" // Кнопки меню настроек; за префиксом — что поменять.
+	thresholdStep          = 5
 )
 
-func RunApp(bh *th.BotHandler) {
+type BotHandlers struct {
+	s          *service.Service
+	compositor *avatar.Compositor
+	logger     zerolog.Logger
+}
+
+func NewBotHandlers(logger zerolog.Logger, s *service.Service, compositor *avatar.Compositor) *BotHandlers {
+	return &BotHandlers{logger: logger, s: s, compositor: compositor}
+}
+
+func (h *BotHandlers) RunApp(bh *th.BotHandler) {
+	// Запоминаем, где видели каждого пользователя, чтобы ему можно было дарить подарки по @username.
+	bh.Use(func(ctx *th.Context, update telego.Update) error {
+		if update.Message != nil {
+			h.s.RememberUser(ctx, int(update.Message.Chat.ID), messageActor(*update.Message))
+		}
+
+		return ctx.Next(update)
+	})
+
 	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
 		handleWebAppCommand(ctx, message.Chat.ID)
 
 		return nil
 	}, th.CommandEqual("start"))
+
+	bh.HandleMessage(h.handleStatusCommand, th.CommandEqual("status"))
+	bh.HandleMessage(h.handleCardCommand, th.CommandEqual("card"))
+	bh.HandleMessage(h.handleStreakCommand, th.CommandEqual("streak"))
+	bh.HandleMessage(h.handleQuestsCommand, th.CommandEqual("quests"))
+	bh.HandleMessage(h.handleTimezoneCommand, th.CommandEqual("timezone"))
+	bh.HandleMessage(h.handlePlaydateCommand, th.CommandEqual("playdate"))
+	bh.HandleMessage(h.handleVisitCommand, th.CommandEqual("visit"))
+	bh.HandleMessage(h.handleVisitsCommand, th.CommandEqual("visits"))
+	bh.HandleMessage(h.handleGiftCommand, th.CommandEqual("gift"))
+	bh.HandleMessage(h.handleGiftsCommand, th.CommandEqual("gifts"))
+	bh.HandleMessage(h.handleNotifyCommand, th.CommandEqual("notify"))
+	bh.HandleMessage(h.handleTopCommand, th.CommandEqual("top"))
+	bh.HandleMessage(h.handleRoleCommand, th.CommandEqual("role"))
+	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
+		return h.handleResolveGiftCommand(ctx, message, h.s.AcceptGift)
+	}, th.CommandEqual("accept"))
+	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
+		return h.handleResolveGiftCommand(ctx, message, h.s.DeclineGift)
+	}, th.CommandEqual("decline"))
+
+	bh.HandleMessage(h.handleSettingsCommand, th.CommandEqual("settings"))
+	bh.HandleCallbackQuery(h.handleAlertCallback, th.CallbackDataPrefix(alertCallbackPrefix))
+	bh.HandleCallbackQuery(h.handleSettingsCallback, th.CallbackDataPrefix(settingsCallbackPrefix))
+
+	// Каждое действие из реестра доступно и командой: /feed, /train sit, /activity walk.
+	for _, action := range service.Actions() {
+		bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
+			return h.handleActionCommand(ctx, message, action)
+		}, th.CommandEqual(action.Name))
+	}
+}
+
+// SetCommands Публикует меню команд бота из реестра действий.
+func (h *BotHandlers) SetCommands(ctx context.Context, bot *telego.Bot) error {
+	commands := []telego.BotCommand{
+		{Command: "start", Description: "Открыть Тамагочи"},
+		{Command: "status", Description: "Как дела у питомца"},
+		{Command: "card", Description: "Карточка питомца, чтобы поделиться"},
+		{Command: "streak", Description: "Серии ухода и награды"},
+		{Command: "quests", Description: "Задания на сегодня"},
+		{Command: "timezone", Description: "Часовой пояс чата: /timezone Europe/Moscow"},
+		{Command: "playdate", Description: "Пригласить питомца из другого чата в гости"},
+		{Command: "visit", Description: "Сходить в гости по коду: /visit КОД"},
+		{Command: "visits", Description: "Последние встречи с другими питомцами"},
+		{Command: "gift", Description: "Подарок другому питомцу: /gift @user 50 или /gift @user hat_cap"},
+		{Command: "gifts", Description: "Подарки, ждущие ответа"},
+		{Command: "accept", Description: "Принять подарок: /accept номер"},
+		{Command: "decline", Description: "Отклонить подарок: /decline номер"},
+		{Command: "settings", Description: "Тихие часы, пороги и режим предупреждений"},
+		{Command: "notify", Description: "Копии уведомлений: /notify webhook URL, /notify email адрес, /notify off webhook"},
+		{Command: "top", Description: "Рейтинги питомцев и участников: /top hide, /top show"},
+		{Command: "role", Description: "Роли в группе: ответьте на сообщение участника /role cook или /role off"},
+	}
+
+	for _, action := range service.Actions() {
+		commands = append(commands, telego.BotCommand{Command: action.Name, Description: action.Description})
+	}
+
+	return bot.SetMyCommands(ctx, &telego.SetMyCommandsParams{Commands: commands})
+}
+
+func (h *BotHandlers) handleActionCommand(ctx *th.Context, message telego.Message, action service.Action) error {
+	params := service.ActionParams{}
+
+	_, _, args := tu.ParseCommand(message.Text)
+	if action.Param != "" && len(args) > 0 {
+		params[action.Param] = strings.ToLower(args[0])
+	}
+
+	text := h.performAction(ctx, message.Chat.ID, messageActor(message), messageLanguage(message), action, params)
+
+	_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+// handleAlertCallback Выполняет действие по кнопке из предупреждения и отвечает в чат, как на команду.
+func (h *BotHandlers) handleAlertCallback(ctx *th.Context, query telego.CallbackQuery) error {
+	action, ok := service.LookupAction(strings.TrimPrefix(query.Data, alertCallbackPrefix))
+	if !ok || query.Message == nil {
+		return ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID).WithText("Действие недоступно"))
+	}
+
+	err := ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID))
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("can't answer callback query")
+	}
+
+	chatID := query.Message.GetChat().ID
+	actor := entity.Actor{ID: query.From.ID, Name: query.From.FirstName, Username: query.From.Username}
+
+	text := h.performAction(ctx, chatID, actor, query.From.LanguageCode, action, service.ActionParams{})
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(chatID), text))
+
+	return err
+}
+
+// performAction Выполняет действие с питомцем чата и собирает текст ответа: отклик, реплику питомца и новости.
+func (h *BotHandlers) performAction(ctx context.Context, chatID int64, actor entity.Actor, language string,
+	action service.Action, params service.ActionParams,
+) string {
+	result, err := h.s.PerformAction(ctx, int(chatID), actor, action.Name, params)
+	if err != nil {
+		if !errors.Is(err, service.ErrActionDenied) {
+			h.logger.Warn().Err(err).Msgf("bot action %s failed", action.Name)
+		}
+
+		text := actionErrorMessage(err, result)
+		if errors.Is(err, service.ErrUnknownTrick) || errors.Is(err, service.ErrUnknownActivity) {
+			text += ". Варианты: " + strings.Join(action.Choices, ", ")
+		}
+
+		return text
+	}
+
+	text := result.ActionFeedback + "\n" + result.Result.Message
+	if speech := h.s.Speak(ctx, int(chatID), result.Pet, language); speech != "" {
+		text += "\n💬 «" + speech + "»"
+	}
+
+	if len(result.Notices) > 0 {
+		text += "\n\n" + strings.Join(result.Notices, "\n")
+	}
+
+	return text
+}
+
+func (h *BotHandlers) handleStatusCommand(ctx *th.Context, message telego.Message) error {
+	pet, err := h.s.LoadPet(ctx, int(message.Chat.ID))
+	if err != nil {
+		text := "Ошибка загрузки питомца"
+		if errors.Is(err, service.ErrPetNotFound) {
+			text = PetNotFindErr
+		} else {
+			h.logger.Error().Err(err).Msg("can't load pet")
+		}
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+		return err
+	}
+
+	pet.UpdateStatus()
+
+	text := fmt.Sprintf("%s\n%s\n🍖 %d · 😊 %d · ⚡ %d · 🧼 %d · ❤️ %d",
+		pet.Name, pet.Status.StatusMessage, pet.Hunger, pet.Happiness, pet.Energy, pet.Hygiene, pet.Health)
+	if speech := h.s.Speak(ctx, int(message.Chat.ID), pet, messageLanguage(message)); speech != "" {
+		text += "\n💬 «" + speech + "»"
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+func (h *BotHandlers) handleCardCommand(ctx *th.Context, message telego.Message) error {
+	data, err := renderPetCard(ctx, h.s, h.compositor, int(message.Chat.ID))
+	if err != nil {
+		text := "Не удалось нарисовать карточку"
+		if errors.Is(err, service.ErrPetNotFound) {
+			text = PetNotFindErr
+		} else {
+			h.logger.Error().Err(err).Msg("can't render card")
+		}
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+		return err
+	}
+
+	_, err = ctx.Bot().SendPhoto(ctx, tu.Photo(tu.ID(message.Chat.ID), tu.FileFromBytes(data, "gocha.png")))
+
+	return err
+}
+
+func (h *BotHandlers) handleStreakCommand(ctx *th.Context, message telego.Message) error {
+	info, err := h.s.Streaks(ctx, int(message.Chat.ID), messageActor(message))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load streaks")
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Ошибка загрузки серий"))
+
+		return err
+	}
+
+	text := fmt.Sprintf("🔥 Серия чата: %d дн. (рекорд %d)\n👤 Ваша серия: %d дн. (рекорд %d)\n🧊 Заморозки: %d\n🪙 Монеты: %d",
+		info.Chat.Current, info.Chat.Best, info.User.Current, info.User.Best, info.User.Freezes, info.Coins)
+	if info.Milestone > 0 {
+		text += fmt.Sprintf("\n🏆 До следующей награды: %d дн.", info.Milestone)
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+// handleTopCommand Показывает рейтинги, а с аргументом hide/show скрывает чат из общих рейтингов или возвращает.
+func (h *BotHandlers) handleTopCommand(ctx *th.Context, message telego.Message) error {
+	chatID := int(message.Chat.ID)
+	_, _, args := tu.ParseCommand(message.Text)
+
+	if len(args) > 0 {
+		var text string
+
+		switch arg := strings.ToLower(args[0]); {
+		case arg != "hide" && arg != "show":
+			text = "Использование: /top, /top hide, /top show"
+		case !h.canManageChat(ctx, message.Chat, message.From):
+			text = "Скрывать чат из общих рейтингов могут только администраторы чата"
+		default:
+			hidden := arg == "hide"
+
+			text = "🏆 Чат снова участвует в общих рейтингах"
+			if hidden {
+				text = "🙈 Чат скрыт из общих рейтингов"
+			}
+
+			err := h.s.SetTopHidden(ctx, chatID, hidden)
+			if err != nil {
+				h.logger.Error().Err(err).Msg("can't change leaderboard privacy")
+
+				text = "Ошибка сохранения настройки"
+			}
+		}
+
+		_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+		return err
+	}
+
+	boards, err := h.s.Leaderboards(ctx, chatID, messageActor(message))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load leaderboards")
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Ошибка загрузки рейтингов"))
+
+		return err
+	}
+
+	lines := []string{"🏆 Рейтинги всех чатов"}
+	lines = append(lines, leaderboardLines(boards.Global)...)
+
+	if boards.Hidden {
+		lines = append(lines, "", "🙈 Ваш чат скрыт из общих рейтингов: /top show")
+	}
+
+	lines = append(lines, "", "👥 Рейтинги этого чата")
+	lines = append(lines, leaderboardLines(boards.Chat)...)
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), strings.Join(lines, "\n")))
+
+	return err
+}
+
+// handleRoleCommand Показывает роли чата. Администратор назначает роль ответом на сообщение участника:
+// /role cook, /role off — снять.
+func (h *BotHandlers) handleRoleCommand(ctx *th.Context, message telego.Message) error {
+	chatID := int(message.Chat.ID)
+	_, _, args := tu.ParseCommand(message.Text)
+
+	var text string
+
+	switch {
+	case message.Chat.Type == telego.ChatTypePrivate:
+		text = "Роли назначаются в групповых чатах"
+	case len(args) == 0:
+		text = h.rolesText(ctx, chatID)
+	case message.ReplyToMessage == nil || message.ReplyToMessage.From == nil:
+		text = "Ответьте командой на сообщение участника: /role cook или /role off"
+	case !h.isChatAdmin(ctx, message.Chat.ID, message.From):
+		text = "Назначать роли могут только администраторы чата"
+	default:
+		member := messageActor(*message.ReplyToMessage)
+
+		if strings.EqualFold(args[0], "off") {
+			text = "Роль снята: " + member.Name
+
+			err := h.s.RemoveRole(ctx, chatID, member.ID)
+			if err != nil {
+				text = h.roleErrorText(err)
+			}
+
+			break
+		}
+
+		cr, err := h.s.AssignRole(ctx, chatID, member, strings.ToLower(args[0]))
+		if err != nil {
+			text = h.roleErrorText(err)
+
+			break
+		}
+
+		def, _ := service.LookupRole(cr.Role)
+		text = fmt.Sprintf("%s %s теперь %s", def.Emoji, cr.Name, strings.ToLower(def.Title))
+	}
+
+	_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+// rolesText Кому какие роли назначены и что они дают.
+func (h *BotHandlers) rolesText(ctx context.Context, chatID int) string {
+	chatRoles, err := h.s.ChatRoles(ctx, chatID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load roles")
+
+		return "Ошибка загрузки ролей"
+	}
+
+	holders := make(map[entity.Role][]string)
+	for _, cr := range chatRoles {
+		holders[cr.Role] = append(holders[cr.Role], cr.Name)
+	}
+
+	lines := []string{"👥 Роли в уходе за питомцем"}
+
+	for _, def := range service.Roles() {
+		action, _ := service.LookupAction(string(def.Care))
+
+		line := fmt.Sprintf("%s %s (%s) — %s сильнее", def.Emoji, def.Title, def.Role, strings.ToLower(action.Title))
+		if def.Exclusive {
+			line += ", а если роль занята — только с ней"
+		}
+
+		names := "никто"
+		if len(holders[def.Role]) > 0 {
+			names = strings.Join(holders[def.Role], ", ")
+		}
+
+		lines = append(lines, line+": "+names)
+	}
+
+	lines = append(lines, "", "Администратор назначает роль ответом на сообщение участника: /role cook, снимает — /role off")
+
+	return strings.Join(lines, "\n")
+}
+
+// canManageChat Менять настройки чата может администратор группы, а в личном чате — сам собеседник.
+func (h *BotHandlers) canManageChat(ctx *th.Context, chat telego.Chat, user *telego.User) bool {
+	if chat.Type == telego.ChatTypePrivate {
+		return user != nil && user.ID == chat.ID
+	}
+
+	return h.isChatAdmin(ctx, chat.ID, user)
+}
+
+// isChatAdmin Администратор или создатель группы.
+func (h *BotHandlers) isChatAdmin(ctx *th.Context, chatID int64, user *telego.User) bool {
+	if user == nil {
+		return false
+	}
+
+	member, err := ctx.Bot().GetChatMember(ctx, &telego.GetChatMemberParams{ChatID: tu.ID(chatID), UserID: user.ID})
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't get chat member")
+
+		return false
+	}
+
+	status := member.MemberStatus()
+
+	return status == telego.MemberStatusCreator || status == telego.MemberStatusAdministrator
+}
+
+func (h *BotHandlers) roleErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrUnknownRole), errors.Is(err, service.ErrNoRole):
+		return "Не получилось: " + err.Error()
+	default:
+		h.logger.Error().Err(err).Msg("role change failed")
+
+		return "Ошибка назначения роли"
+	}
+}
+
+// leaderboardLines Первые места каждого рейтинга; свои места отмечены.
+func leaderboardLines(boards []entity.Leaderboard) []string {
+	const shown = 5
+
+	var lines []string
+
+	for _, board := range boards {
+		lines = append(lines, board.Title)
+
+		if len(board.Entries) == 0 {
+			lines = append(lines, "  пока пусто")
+		}
+
+		for _, e := range board.Entries[:min(len(board.Entries), shown)] {
+			line := fmt.Sprintf("  %d. %s — %d %s", e.Rank, e.Name, e.Value, board.Unit)
+			if e.Own {
+				line += " 👈"
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func (h *BotHandlers) handleQuestsCommand(ctx *th.Context, message telego.Message) error {
+	board, err := h.s.Quests(ctx, int(message.Chat.ID))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load quests")
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Ошибка загрузки заданий"))
+
+		return err
+	}
+
+	icons := map[entity.QuestStatus]string{
+		entity.QuestActive: "⬜",
+		entity.QuestDone:   "✅",
+		entity.QuestFailed: "❌",
+	}
+
+	lines := []string{"📜 Задания на сегодня:"}
+	for _, quest := range board.Quests {
+		lines = append(lines, fmt.Sprintf("%s %s — %d/%d (+%d 🪙)", icons[quest.Status], quest.Title, quest.Progress, quest.Target, quest.Reward))
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), strings.Join(lines, "\n")))
+
+	return err
+}
+
+func (h *BotHandlers) handleTimezoneCommand(ctx *th.Context, message telego.Message) error {
+	_, _, args := tu.ParseCommand(message.Text)
+	if len(args) == 0 {
+		_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Укажите часовой пояс: /timezone Europe/Moscow"))
+
+		return err
+	}
+
+	if !h.canManageChat(ctx, message.Chat, message.From) {
+		_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Менять часовой пояс могут только администраторы чата"))
+
+		return err
+	}
+
+	text := "Часовой пояс чата: " + args[0]
+
+	err := h.s.SetTimezone(ctx, int(message.Chat.ID), args[0])
+	if err != nil {
+		if !errors.Is(err, service.ErrUnknownTimezone) {
+			h.logger.Error().Err(err).Msg("can't set timezone")
+		}
+
+		text = "Не удалось сменить часовой пояс: " + err.Error()
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+func (h *BotHandlers) handlePlaydateCommand(ctx *th.Context, message telego.Message) error {
+	invite, err := h.s.InvitePlaydate(ctx, int(message.Chat.ID))
+	if err != nil {
+		text := "Не удалось создать приглашение"
+		if errors.Is(err, service.ErrPetNotFound) {
+			text = PetNotFindErr
+		} else {
+			h.logger.Error().Err(err).Msg("can't invite playdate")
+		}
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+		return err
+	}
+
+	text := fmt.Sprintf("🏡 Приглашение в гости готово! Перешлите в другой чат команду:\n/visit %s\nКод действует до %s.",
+		invite.Code, invite.ExpiresAt.Format("02.01 15:04"))
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+func (h *BotHandlers) handleVisitCommand(ctx *th.Context, message telego.Message) error {
+	_, _, args := tu.ParseCommand(message.Text)
+	if len(args) == 0 {
+		_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Укажите код приглашения: /visit КОД"))
+
+		return err
+	}
+
+	// При успехе оба чата получают общее сообщение от сервиса.
+	_, err := h.s.AcceptPlaydate(ctx, int(message.Chat.ID), args[0])
+	if err == nil {
+		return nil
+	}
+
+	text := "Не удалось сходить в гости: " + err.Error()
+
+	switch {
+	case errors.Is(err, service.ErrPetNotFound):
+		text = PetNotFindErr
+	case errors.Is(err, service.ErrInviteNotFound), errors.Is(err, service.ErrSelfPlaydate),
+		errors.Is(err, service.ErrPlaydateDenied), errors.Is(err, service.ErrPlaydateTooSoon):
+	default:
+		h.logger.Error().Err(err).Msg("can't accept playdate")
+
+		text = "Не удалось сходить в гости"
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+func (h *BotHandlers) handleVisitsCommand(ctx *th.Context, message telego.Message) error {
+	visits, err := h.s.Visits(ctx, int(message.Chat.ID), 5)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load visits")
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Ошибка загрузки встреч"))
+
+		return err
+	}
+
+	lines := []string{"🏡 Последние встречи:"}
+	for _, v := range visits {
+		lines = append(lines, fmt.Sprintf("%s — %s в гостях у питомца %s, совместимость %d%%, +%d 😊",
+			v.At.Format("02.01"), v.GuestPet, v.HostPet, v.Compatibility, v.Bonus))
+	}
+
+	if len(visits) == 0 {
+		lines = append(lines, "Пока никто не ходил в гости. Пригласите друга: /playdate")
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), strings.Join(lines, "\n")))
+
+	return err
+}
+
+// handleSettingsCommand Присылает меню настроек предупреждений чата. Кнопки получает только тот, кто может
+// менять настройки; остальным — просто текущие настройки.
+func (h *BotHandlers) handleSettingsCommand(ctx *th.Context, message telego.Message) error {
+	text, menu := settingsMenu(h.s.NotifySettings(ctx, int(message.Chat.ID)))
+
+	params := tu.Message(tu.ID(message.Chat.ID), text)
+	if h.canManageChat(ctx, message.Chat, message.From) {
+		params = params.WithReplyMarkup(menu)
+	} else {
+		params.Text += "\n\nМенять настройки могут только администраторы чата."
+	}
+
+	_, err := ctx.Bot().SendMessage(ctx, params)
+
+	return err
+}
+
+// handleSettingsCallback Меняет настройку по кнопке меню и перерисовывает меню.
+func (h *BotHandlers) handleSettingsCallback(ctx *th.Context, query telego.CallbackQuery) error {
+	if query.Message == nil {
+		return ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID))
+	}
+
+	chat := query.Message.GetChat()
+	chatID := chat.ID
+
+	if !h.canManageChat(ctx, chat, &query.From) {
+		return ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID).WithText("Менять настройки могут только администраторы чата"))
+	}
+
+	update, ok := changeSettings(h.s.NotifySettings(ctx, int(chatID)), strings.TrimPrefix(query.Data, settingsCallbackPrefix))
+	if !ok {
+		return ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID))
+	}
+
+	settings, err := h.s.UpdateNotifySettings(ctx, int(chatID), update)
+	if err != nil {
+		if !errors.Is(err, service.ErrBadSettings) {
+			h.logger.Error().Err(err).Msg("can't save notify settings")
+		}
+
+		return ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID).WithText("Не удалось сохранить настройки"))
+	}
+
+	err = ctx.Bot().AnswerCallbackQuery(ctx, tu.CallbackQuery(query.ID))
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("can't answer callback query")
+	}
+
+	text, menu := settingsMenu(settings)
+
+	_, err = ctx.Bot().EditMessageText(ctx, &telego.EditMessageTextParams{
+		ChatID:      tu.ID(chatID),
+		MessageID:   query.Message.GetMessageID(),
+		Text:        text,
+		ReplyMarkup: menu,
+	})
+
+	return err
+}
+
+// settingsMenu Текст и кнопки меню настроек предупреждений.
+func settingsMenu(settings entity.NotifySettings) (string, *telego.InlineKeyboardMarkup) {
+	quiet := "выключены"
+	if settings.QuietFrom != settings.QuietTo {
+		quiet = fmt.Sprintf("%02d:00–%02d:00", settings.QuietFrom, settings.QuietTo)
+	}
+
+	mode := "каждое сразу"
+	if settings.Mode == entity.AlertDigest {
+		mode = "сводкой"
+	}
+
+	text := fmt.Sprintf("🔔 Предупреждения\nТихие часы: %s (%s)\nРежим: %s\nПорог — предупреждать, когда стат опустился до него.",
+		quiet, settings.Timezone, mode)
+
+	button := func(label, data string) telego.InlineKeyboardButton {
+		return tu.InlineKeyboardButton(label).WithCallbackData(settingsCallbackPrefix + data)
+	}
+
+	rows := [][]telego.InlineKeyboardButton{
+		tu.InlineKeyboardRow(button("🌙 Тихие часы: "+quiet, "quiet")),
+	}
+
+	if settings.QuietFrom != settings.QuietTo {
+		rows = append(rows,
+			tu.InlineKeyboardRow(button("➖", "from:-1"), button(fmt.Sprintf("с %02d:00", settings.QuietFrom), "noop"), button("➕", "from:1")),
+			tu.InlineKeyboardRow(button("➖", "to:-1"), button(fmt.Sprintf("до %02d:00", settings.QuietTo), "noop"), button("➕", "to:1")),
+		)
+	}
+
+	rows = append(rows, tu.InlineKeyboardRow(button("📬 Режим: "+mode, "mode")))
+
+	for _, pref := range settings.Alerts {
+		mark := "🔕"
+		if pref.Enabled {
+			mark = "✅"
+		}
+
+		rows = append(rows, tu.InlineKeyboardRow(
+			button(fmt.Sprintf("%s %s ≤ %d", mark, pref.Title, pref.Threshold), "alert:"+pref.Type),
+			button("➖", "th:"+pref.Type+":-"+strconv.Itoa(thresholdStep)),
+			button("➕", "th:"+pref.Type+":"+strconv.Itoa(thresholdStep)),
+		))
+	}
+
+	return text, tu.InlineKeyboard(rows...)
+}
+
+// changeSettings Изменение настроек по нажатой кнопке; false, если менять нечего.
+func changeSettings(settings entity.NotifySettings, op string) (entity.NotifySettingsUpdate, bool) {
+	var update entity.NotifySettingsUpdate
+
+	parts := strings.Split(op, ":")
+
+	switch parts[0] {
+	case "quiet":
+		from, to := 23, 8
+		if settings.QuietFrom != settings.QuietTo {
+			from, to = 0, 0
+		}
+
+		update.QuietFrom, update.QuietTo = &from, &to
+	case "from", "to":
+		if len(parts) != 2 {
+			return update, false
+		}
+
+		delta, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return update, false
+		}
+
+		hour, other := settings.QuietFrom, settings.QuietTo
+		if parts[0] == "to" {
+			hour, other = other, hour
+		}
+
+		hour = (hour + delta + 24) % 24
+		if hour == other {
+			// Совпавшие часы выключили бы тихое время — перешагиваем.
+			hour = (hour + delta + 24) % 24
+		}
+
+		if parts[0] == "to" {
+			update.QuietTo = &hour
+		} else {
+			update.QuietFrom = &hour
+		}
+	case "mode":
+		mode := entity.AlertDigest
+		if settings.Mode == entity.AlertDigest {
+			mode = entity.AlertInstant
+		}
+
+		update.Mode = &mode
+	case "alert", "th":
+		if len(parts) < 2 {
+			return update, false
+		}
+
+		for _, pref := range settings.Alerts {
+			if pref.Type != parts[1] {
+				continue
+			}
+
+			change := entity.AlertPreferenceUpdate{Type: pref.Type}
+
+			if parts[0] == "alert" {
+				enabled := !pref.Enabled
+				change.Enabled = &enabled
+				update.Alerts = append(update.Alerts, change)
+
+				return update, true
+			}
+
+			if len(parts) != 3 {
+				return update, false
+			}
+
+			delta, err := strconv.Atoi(parts[2])
+			if err != nil {
+				return update, false
+			}
+
+			threshold := min(max(pref.Threshold+delta, 0), 100)
+			if threshold == pref.Threshold {
+				return update, false
+			}
+
+			change.Threshold = &threshold
+			update.Alerts = append(update.Alerts, change)
+
+			return update, true
+		}
+
+		return update, false
+	default:
+		return update, false
+	}
+
+	return update, true
+}
+
+// handleNotifyCommand Показывает, подключает и отключает дополнительные каналы уведомлений чата. Менять
+// каналы может только администратор: копии уходят на внешние адреса.
+func (h *BotHandlers) handleNotifyCommand(ctx *th.Context, message telego.Message) error {
+	chatID := int(message.Chat.ID)
+	_, _, args := tu.ParseCommand(message.Text)
+
+	var text string
+
+	switch {
+	case len(args) > 1 && !h.canManageChat(ctx, message.Chat, message.From):
+		text = "Подключать и отключать каналы могут только администраторы чата"
+	case len(args) == 0:
+		channels, err := h.s.NotifyChannels(ctx, chatID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("can't load notify channels")
+
+			text = "Ошибка загрузки каналов"
+
+			break
+		}
+
+		lines := []string{"🔔 Уведомления приходят в этот чат."}
+		for _, ch := range channels {
+			lines = append(lines, fmt.Sprintf("Копия: %s → %s", ch.Kind, ch.Target))
+		}
+
+		if len(channels) == 0 {
+			lines = append(lines, "Копии можно слать на вебхук или почту: /notify webhook URL, /notify email адрес")
+		}
+
+		text = strings.Join(lines, "\n")
+	case strings.EqualFold(args[0], "off") && len(args) > 1:
+		text = "Канал " + args[1] + " отключён"
+
+		err := h.s.RemoveNotifyChannel(ctx, chatID, args[1])
+		if err != nil {
+			text = h.notifyErrorText(err)
+		}
+	case len(args) > 1:
+		ch, err := h.s.SetNotifyChannel(ctx, chatID, args[0], args[1])
+		if err != nil {
+			text = h.notifyErrorText(err)
+
+			break
+		}
+
+		text = fmt.Sprintf("🔔 Копии уведомлений пойдут: %s → %s", ch.Kind, ch.Target)
+		if ch.Secret != "" {
+			text += h.sendWebhookSecret(ctx, message, ch)
+		}
+	default:
+		text = "Использование: /notify webhook URL, /notify email адрес, /notify off webhook"
+	}
+
+	_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+// sendWebhookSecret Отправляет секрет подписи вебхука администратору в личные сообщения, а не в группу.
+// Если личка закрыта, вебхук отключается: без секрета его подпись не проверить. Возвращает приписку для чата.
+func (h *BotHandlers) sendWebhookSecret(ctx *th.Context, message telego.Message, ch entity.NotifyChannel) string {
+	secret := fmt.Sprintf("🔐 Секрет подписи вебхука %s (заголовок %s): %s — сохраните его, больше он не покажется.",
+		ch.Target, notify.SignatureHeader, ch.Secret)
+
+	_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.From.ID), secret))
+	if err == nil {
+		if message.Chat.Type == telego.ChatTypePrivate {
+			return ""
+		}
+
+		return "\nСекрет подписи отправлен вам в личные сообщения."
+	}
+
+	h.logger.Warn().Err(err).Msg("can't send webhook secret privately")
+
+	err = h.s.RemoveNotifyChannel(ctx, int(message.Chat.ID), string(ch.Kind))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't remove webhook without delivered secret")
+	}
+
+	return "\nНе получилось отправить секрет подписи в личные сообщения, вебхук отключён. " +
+		"Напишите боту в личку и подключите вебхук снова."
+}
+
+func (h *BotHandlers) notifyErrorText(err error) string {
+	switch {
+	case errors.Is(err, service.ErrUnknownChannel), errors.Is(err, service.ErrBadChannelTarget),
+		errors.Is(err, service.ErrEmailDisabled), errors.Is(err, service.ErrNoChannel):
+		return "Не получилось: " + err.Error()
+	default:
+		h.logger.Error().Err(err).Msg("notify channel failed")
+
+		return "Ошибка настройки уведомлений"
+	}
+}
+
+func (h *BotHandlers) handleGiftCommand(ctx *th.Context, message telego.Message) error {
+	_, _, args := tu.ParseCommand(message.Text)
+	if len(args) < 2 {
+		_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Кому и что подарить: /gift @user 50 или /gift @user hat_cap"))
+
+		return err
+	}
+
+	gift, err := h.s.SendGift(ctx, int(message.Chat.ID), messageActor(message), args[0], args[1])
+
+	text := fmt.Sprintf("🎁 Подарок «%s» отправлен %s. Если его отклонят, он вернётся.", gift.Title, args[0])
+	if err != nil {
+		text = h.giftErrorText(err)
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+func (h *BotHandlers) handleGiftsCommand(ctx *th.Context, message telego.Message) error {
+	gifts, err := h.s.GiftInbox(ctx, int(message.Chat.ID))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load gifts")
+
+		_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Ошибка загрузки подарков"))
+
+		return err
+	}
+
+	lines := []string{"🎁 Подарки, ждущие ответа:"}
+	for _, gift := range gifts {
+		lines = append(lines, fmt.Sprintf("№%d — %s от %s: /accept %d или /decline %d", gift.ID, gift.Title, gift.FromName, gift.ID, gift.ID))
+	}
+
+	if len(gifts) == 0 {
+		lines = []string{"🎁 Новых подарков нет."}
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), strings.Join(lines, "\n")))
+
+	return err
+}
+
+func (h *BotHandlers) handleResolveGiftCommand(ctx *th.Context, message telego.Message,
+	resolve func(ctx context.Context, chatID int, giftID int64) (entity.Gift, error),
+) error {
+	_, _, args := tu.ParseCommand(message.Text)
+
+	var giftID int64
+	if len(args) > 0 {
+		giftID, _ = strconv.ParseInt(strings.TrimPrefix(args[0], "№"), 10, 64)
+	}
+
+	if giftID == 0 {
+		_, err := ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), "Укажите номер подарка из /gifts"))
+
+		return err
+	}
+
+	gift, err := resolve(ctx, int(message.Chat.ID), giftID)
+
+	text := fmt.Sprintf("🎉 Подарок «%s» принят!", gift.Title)
+	if gift.Status == entity.GiftDeclined {
+		text = fmt.Sprintf("↩️ Подарок «%s» возвращён отправителю.", gift.Title)
+	}
+
+	if err != nil {
+		text = h.giftErrorText(err)
+	}
+
+	_, err = ctx.Bot().SendMessage(ctx, tu.Message(tu.ID(message.Chat.ID), text))
+
+	return err
+}
+
+func (h *BotHandlers) giftErrorText(err error) string {
+	if giftUserError(err) {
+		return "Не получилось: " + err.Error()
+	}
+
+	h.logger.Error().Err(err).Msg("gift failed")
+
+	return "Ошибка подарков"
+}
+
+func messageActor(message telego.Message) entity.Actor {
+	if message.From == nil {
+		return entity.Actor{}
+	}
+
+	return entity.Actor{ID: message.From.ID, Name: message.From.FirstName, Username: message.From.Username}
+}
+
+func messageLanguage(message telego.Message) string {
+	if message.From == nil {
+		return ""
+	}
+
+	return message.From.LanguageCode
 }
 
 func handleWebAppCommand(ctx *th.Context, chatID int64) {
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"gocha/internal/entity"
 	"gocha/internal/service"
@@ -17,17 +20,23 @@ import (
 
 const (
 	PetNotFindErr = "Питомец не найден"
+
+	manageChatDenied = "Менять настройки чата может только администратор"
+
+	initDataExpiry = 24 * time.Hour // Сколько действительна initData Mini App после auth_date.
 )
 
 type PetHandlers struct {
-	s       *service.Service
-	logger  zerolog.Logger
-	baseUrl string
-	isDev   bool
+	s        *service.Service
+	admins   ChatAdmins
+	logger   zerolog.Logger
+	baseUrl  string
+	botToken string
+	isDev    bool
 }
 
-func NewPetHandlers(logger zerolog.Logger, s *service.Service, baseUrl string, isDev bool) *PetHandlers {
-	return &PetHandlers{logger: logger, s: s, baseUrl: baseUrl, isDev: isDev}
+func NewPetHandlers(logger zerolog.Logger, s *service.Service, admins ChatAdmins, baseUrl, botToken string, isDev bool) *PetHandlers {
+	return &PetHandlers{logger: logger, s: s, admins: admins, baseUrl: baseUrl, botToken: botToken, isDev: isDev}
 }
 
 func (h *PetHandlers) PetNewHandler(w http.ResponseWriter, r *http.Request) {
@@ -58,7 +67,7 @@ func (h *PetHandlers) PetNewHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parseData, err := initdata.Parse(tgData)
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
 	if err != nil {
 		json.NewEncoder(w).Encode(entity.APIResponse[entity.Pet]{
 			Success: false,
@@ -80,6 +89,7 @@ func (h *PetHandlers) PetNewHandler(w http.ResponseWriter, r *http.Request) {
 
 	pet.GetAvatar(fmt.Sprintf("%s/%s", h.baseUrl, "static"))
 	pet.UpdateStatus()
+	h.s.Speak(ctx, getPetID(parseData), pet, parseData.User.LanguageCode)
 
 	json.NewEncoder(w).Encode(entity.APIResponse[entity.Pet]{
 		Success: true,
@@ -104,7 +114,7 @@ func (h *PetHandlers) PetInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parseData, err := initdata.Parse(tgData)
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
 	if err != nil {
 		json.NewEncoder(w).Encode(entity.APIResponse[entity.Pet]{
 			Success: false,
@@ -114,7 +124,9 @@ func (h *PetHandlers) PetInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pet, err := h.s.LoadPet(ctx, getPetID(parseData))
+	h.s.RememberUser(ctx, getPetID(parseData), getActor(parseData))
+
+	pet, err := h.s.PetForUser(ctx, getPetID(parseData), getActor(parseData))
 	if err != nil {
 		if errors.Is(err, service.ErrPetNotFound) {
 			json.NewEncoder(w).Encode(entity.APIResponse[entity.Pet]{
@@ -133,6 +145,7 @@ func (h *PetHandlers) PetInfoHandler(w http.ResponseWriter, r *http.Request) {
 
 	pet.GetAvatar(fmt.Sprintf("%s/%s", h.baseUrl, "static"))
 	pet.UpdateStatus()
+	h.s.Speak(ctx, getPetID(parseData), pet, parseData.User.LanguageCode)
 
 	json.NewEncoder(w).Encode(entity.APIResponse[entity.Pet]{
 		Success: true,
@@ -140,108 +153,809 @@ func (h *PetHandlers) PetInfoHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (h *PetHandlers) handlePetAction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, petID int) (entity.PetActionResult, error), actionName string) {
+// PetReportHandler Табель качества ухода за сегодня и прошедшую неделю.
+func (h *PetHandlers) PetReportHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := context.Background()
+
 	w.Header().Set("Content-Type", "application/json")
 
 	tgData := r.Header.Get("X-Telegram-Init-Data")
 	if tgData == "" {
-		json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.CareReportCard]{
 			Success: false,
 			Message: "Нет initData",
 		})
+
 		return
 	}
 
-	parseData, err := initdata.Parse(tgData)
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
 	if err != nil {
-		json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.CareReportCard]{
 			Success: false,
 			Message: "Не удалось прочитать tg-init-data",
 		})
+
+		return
+	}
+
+	card, err := h.s.CareReport(ctx, getPetID(parseData))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't build care report")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.CareReportCard]{
+			Success: false,
+			Message: "Ошибка загрузки отчёта",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.CareReportCard]{
+		Success: true,
+		Data:    card,
+	})
+}
+
+// StreakHandler Серии ухода чата и текущего пользователя.
+func (h *PetHandlers) StreakHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.StreakInfo]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
 		return
 	}
 
-	petID := getPetID(parseData)
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.StreakInfo]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
 
-	// Сначала загружаем питомца для проверки возможности действия
-	pet, err := h.s.LoadPet(ctx, petID)
+	info, err := h.s.Streaks(ctx, getPetID(parseData), getActor(parseData))
 	if err != nil {
-		if errors.Is(err, service.ErrPetNotFound) {
-			json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+		h.logger.Error().Err(err).Msg("can't load streaks")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.StreakInfo]{
+			Success: false,
+			Message: "Ошибка загрузки серий",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.StreakInfo]{
+		Success: true,
+		Data:    info,
+	})
+}
+
+// SchedulerStatsHandler Глубина очереди замеров и отставание планировщика для мониторинга. Доступен только
+// в dev-режиме.
+func (h *PetHandlers) SchedulerStatsHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.SchedulerStats]{
+		Success: true,
+		Data:    h.s.SchedulerStats(),
+	})
+}
+
+// ActiveEventsHandler События календаря, идущие сейчас в чате.
+func (h *PetHandlers) ActiveEventsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.ActiveEvent]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.ActiveEvent]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	events, err := h.s.ActiveEvents(ctx, getPetID(parseData))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load events")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.ActiveEvent]{
+			Success: false,
+			Message: "Ошибка загрузки событий",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[[]entity.ActiveEvent]{
+		Success: true,
+		Data:    events,
+	})
+}
+
+// QuestsHandler Ежедневные задания чата с прогрессом.
+func (h *PetHandlers) QuestsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.QuestBoard]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.QuestBoard]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	board, err := h.s.Quests(ctx, getPetID(parseData))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load quests")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.QuestBoard]{
+			Success: false,
+			Message: "Ошибка загрузки заданий",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.QuestBoard]{
+		Success: true,
+		Data:    board,
+	})
+}
+
+// PetDiaryHandler Дневник питомца на языке пользователя.
+func (h *PetHandlers) PetDiaryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.DiaryEntry]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.DiaryEntry]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	entries, err := h.s.Diary(ctx, getPetID(parseData), parseData.User.LanguageCode)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load diary")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.DiaryEntry]{
+			Success: false,
+			Message: "Ошибка загрузки дневника",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[[]entity.DiaryEntry]{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+// PetHistoryHandler Журнал действий с питомцем по страницам: ?before=<курсор из прошлой страницы>.
+func (h *PetHandlers) PetHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.HistoryPage]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.HistoryPage]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	var before int64
+
+	if cursor := r.URL.Query().Get("before"); cursor != "" {
+		before, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || before < 0 {
+			json.NewEncoder(w).Encode(entity.APIResponse[entity.HistoryPage]{
 				Success: false,
-				Message: PetNotFindErr,
+				Message: "Некорректный курсор страницы",
 			})
+
+			return
+		}
+	}
+
+	page, err := h.s.History(ctx, getPetID(parseData), before)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load history")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.HistoryPage]{
+			Success: false,
+			Message: "Ошибка загрузки журнала",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.HistoryPage]{
+		Success: true,
+		Data:    page,
+	})
+}
+
+// PetStatsHandler Графики статов питомца: ?window=day (по умолчанию) или week.
+func (h *PetHandlers) PetStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.StatSeries]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.StatSeries]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	window := r.URL.Query().Get("window")
+	if window == "" {
+		window = "day"
+	}
+
+	series, err := h.s.StatSeries(ctx, getPetID(parseData), window)
+	if err != nil {
+		message := "Ошибка загрузки графиков"
+		if errors.Is(err, service.ErrUnknownWindow) {
+			message = err.Error()
 		} else {
-			json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
-				Success: false,
-				Message: "Ошибка загрузки питомца",
-			})
+			h.logger.Error().Err(err).Msg("can't load stat series")
 		}
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.StatSeries]{
+			Success: false,
+			Message: message,
+		})
+
 		return
 	}
 
-	pet.UpdateStatus()
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.StatSeries]{
+		Success: true,
+		Data:    series,
+	})
+}
 
-	// Проверяем возможность выполнения действия
-	canPerform, reason := pet.CanPerformAction(actionName)
-	if !canPerform {
-		json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+// WardrobeHandler Каталог аксессуаров с отметками о покупках чата.
+func (h *PetHandlers) WardrobeHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleWardrobe(w, r, func(ctx context.Context, chatID int, actor entity.Actor, _ cosmeticRequest) (entity.Wardrobe, error) {
+		return h.s.Wardrobe(ctx, chatID, actor)
+	})
+}
+
+// BuyCosmeticHandler Покупка аксессуара на монеты чата или личные монеты покупателя: {"item": "hat_cap"}.
+func (h *PetHandlers) BuyCosmeticHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleWardrobe(w, r, func(ctx context.Context, chatID int, actor entity.Actor, req cosmeticRequest) (entity.Wardrobe, error) {
+		return h.s.BuyCosmetic(ctx, chatID, actor, req.Item)
+	})
+}
+
+// EquipCosmeticHandler Надеть или снять аксессуар: {"item": "hat_cap", "equip": true}.
+func (h *PetHandlers) EquipCosmeticHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleWardrobe(w, r, func(ctx context.Context, chatID int, actor entity.Actor, req cosmeticRequest) (entity.Wardrobe, error) {
+		return h.s.EquipCosmetic(ctx, chatID, actor, req.Item, req.Equip)
+	})
+}
+
+// GiftsHandler Подарки, ждущие ответа чата.
+func (h *PetHandlers) GiftsHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleGifts(w, r, func(ctx context.Context, chatID int, _ entity.Actor, _ giftRequest) error {
+		return nil
+	})
+}
+
+// SendGiftHandler Подарок питомцу другого чата: {"to": "@user", "what": "50"} или {"to": "@user", "what": "hat_cap"}.
+func (h *PetHandlers) SendGiftHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleGifts(w, r, func(ctx context.Context, chatID int, actor entity.Actor, req giftRequest) error {
+		_, err := h.s.SendGift(ctx, chatID, actor, req.To, req.What)
+
+		return err
+	})
+}
+
+// AcceptGiftHandler Принять подарок: {"id": 1}.
+func (h *PetHandlers) AcceptGiftHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleGifts(w, r, func(ctx context.Context, chatID int, _ entity.Actor, req giftRequest) error {
+		_, err := h.s.AcceptGift(ctx, chatID, req.ID)
+
+		return err
+	})
+}
+
+// DeclineGiftHandler Отклонить подарок: {"id": 1}.
+func (h *PetHandlers) DeclineGiftHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleGifts(w, r, func(ctx context.Context, chatID int, _ entity.Actor, req giftRequest) error {
+		_, err := h.s.DeclineGift(ctx, chatID, req.ID)
+
+		return err
+	})
+}
+
+type giftRequest struct {
+	ID   int64  `json:"id"`
+	To   string `json:"to"`
+	What string `json:"what"`
+}
+
+// handleGifts Выполняет действие с подарками и отвечает обновлённым списком входящих.
+func (h *PetHandlers) handleGifts(w http.ResponseWriter, r *http.Request,
+	do func(ctx context.Context, chatID int, actor entity.Actor, req giftRequest) error,
+) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req giftRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.respondWithError(w, http.StatusBadRequest, "Failed to decode request")
+
+		return
+	}
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.Gift]{
 			Success: false,
-			Message: reason,
+			Message: "Нет initData",
 		})
 
 		return
 	}
 
-	// Выполняем действие
-	result, err := action(ctx, petID)
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
 	if err != nil {
-		if errors.Is(err, service.ErrPetNotFound) {
-			json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
-				Success: false,
-				Message: PetNotFindErr,
-			})
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.Gift]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	chatID := getPetID(parseData)
+
+	err = do(ctx, chatID, getActor(parseData), req)
+	if err != nil {
+		message := "Ошибка подарков"
+
+		if giftUserError(err) {
+			message = err.Error()
+		} else {
+			h.logger.Error().Err(err).Msg("gift failed")
+		}
+
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.Gift]{
+			Success: false,
+			Message: message,
+		})
+
+		return
+	}
+
+	gifts, err := h.s.GiftInbox(ctx, chatID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load gifts")
+
+		json.NewEncoder(w).Encode(entity.APIResponse[[]entity.Gift]{
+			Success: false,
+			Message: "Ошибка загрузки подарков",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[[]entity.Gift]{
+		Success: true,
+		Data:    gifts,
+	})
+}
+
+// SettingsHandler Настройки предупреждений чата: GET — прочитать, POST с изменёнными полями в теле — сохранить.
+func (h *PetHandlers) SettingsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var update *entity.NotifySettingsUpdate
+
+	if r.Method == http.MethodPost {
+		err := json.NewDecoder(r.Body).Decode(&update)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Failed to decode request")
+
+			return
+		}
+	}
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.NotifySettings]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.NotifySettings]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	chatID := getPetID(parseData)
+
+	if update == nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.NotifySettings]{
+			Success: true,
+			Data:    h.s.NotifySettings(ctx, chatID),
+		})
+
+		return
+	}
+
+	if !h.canManageChat(ctx, parseData) {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.NotifySettings]{
+			Success: false,
+			Message: manageChatDenied,
+		})
+
+		return
+	}
+
+	settings, err := h.s.UpdateNotifySettings(ctx, chatID, *update)
+	if err != nil {
+		message := "Ошибка сохранения настроек"
+
+		if errors.Is(err, service.ErrBadSettings) {
+			message = err.Error()
 		} else {
-			json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+			h.logger.Error().Err(err).Msg("can't save notify settings")
+		}
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.NotifySettings]{
+			Success: false,
+			Message: message,
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.NotifySettings]{
+		Success: true,
+		Data:    settings,
+	})
+}
+
+// leaderboardRequest Тело POST /api/leaderboard/: скрыть чат из общих рейтингов или вернуть.
+type leaderboardRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+// LeaderboardHandler GET — рейтинги, POST — переключает участие чата в общих рейтингах.
+func (h *PetHandlers) LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var update *leaderboardRequest
+
+	if r.Method == http.MethodPost {
+		err := json.NewDecoder(r.Body).Decode(&update)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Failed to decode request")
+
+			return
+		}
+	}
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Leaderboards]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Leaderboards]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	chatID := getPetID(parseData)
+
+	if update != nil && !h.canManageChat(ctx, parseData) {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Leaderboards]{
+			Success: false,
+			Message: manageChatDenied,
+		})
+
+		return
+	}
+
+	if update != nil {
+		err = h.s.SetTopHidden(ctx, chatID, update.Hidden)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("can't change leaderboard privacy")
+
+			json.NewEncoder(w).Encode(entity.APIResponse[entity.Leaderboards]{
 				Success: false,
-				Message: "Ошибка при выполнении действия",
+				Message: "Ошибка сохранения настройки",
 			})
+
+			return
 		}
-		return
 	}
 
-	result.GetAvatar(fmt.Sprintf("%s/%s", h.baseUrl, "static"))
-	result.GenerateActionFeedback(actionName)
+	boards, err := h.s.Leaderboards(ctx, chatID, getActor(parseData))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("can't load leaderboards")
 
-	json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Leaderboards]{
+			Success: false,
+			Message: "Ошибка загрузки рейтингов",
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.Leaderboards]{
 		Success: true,
-		Data:    result,
+		Data:    boards,
 	})
 }
 
-func (h *PetHandlers) PetFeedHandler(w http.ResponseWriter, r *http.Request) {
-	h.handlePetAction(w, r, h.s.PetFeed, "feed")
+// giftUserError Ошибки подарков, которые стоит показать пользователю как есть.
+func giftUserError(err error) bool {
+	for _, target := range []error{
+		service.ErrRecipientNotFound, service.ErrSelfGift, service.ErrGiftNotFound, service.ErrGiftLimit,
+		service.ErrGiftItemNotOwned, service.ErrGiftItemOwned, service.ErrAnonymousGift, service.ErrBadGift,
+		service.ErrNotEnoughCoins,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (h *PetHandlers) PetHealHandler(w http.ResponseWriter, r *http.Request) {
-	h.handlePetAction(w, r, h.s.PetHeal, "heal")
+type cosmeticRequest struct {
+	Item  string `json:"item"`
+	Equip bool   `json:"equip"`
 }
 
-func (h *PetHandlers) PetPlayHandler(w http.ResponseWriter, r *http.Request) {
-	h.handlePetAction(w, r, h.s.PetPlay, "play")
+func (h *PetHandlers) handleWardrobe(w http.ResponseWriter, r *http.Request,
+	do func(ctx context.Context, chatID int, actor entity.Actor, req cosmeticRequest) (entity.Wardrobe, error),
+) {
+	ctx := context.Background()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req cosmeticRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.respondWithError(w, http.StatusBadRequest, "Failed to decode request")
+
+		return
+	}
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Wardrobe]{
+			Success: false,
+			Message: "Нет initData",
+		})
+
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Wardrobe]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+
+		return
+	}
+
+	wardrobe, err := do(ctx, getPetID(parseData), getActor(parseData), req)
+	if err != nil {
+		message := "Ошибка гардероба"
+
+		switch {
+		case errors.Is(err, service.ErrUnknownCosmetic), errors.Is(err, service.ErrCosmeticOwned),
+			errors.Is(err, service.ErrCosmeticNotOwned), errors.Is(err, service.ErrNotEnoughCoins),
+			errors.Is(err, service.ErrCosmeticUnavailable):
+			message = err.Error()
+		default:
+			h.logger.Error().Err(err).Msg("wardrobe failed")
+		}
+
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.Wardrobe]{
+			Success: false,
+			Message: message,
+		})
+
+		return
+	}
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.Wardrobe]{
+		Success: true,
+		Data:    wardrobe,
+	})
 }
 
-func (h *PetHandlers) PetCleanHandler(w http.ResponseWriter, r *http.Request) {
-	h.handlePetAction(w, r, h.s.PetClean, "clean")
+// PetActionHandler Обработчик действия из реестра: /api/pet/<name>/.
+// Тело запроса необязательно и содержит параметры действия, например {"trick": "sit"}.
+func (h *PetHandlers) PetActionHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.handlePetAction(w, r, name)
+	}
 }
 
-func (h *PetHandlers) PetSleepHandler(w http.ResponseWriter, r *http.Request) {
-	h.handlePetAction(w, r, h.s.PetSleep, "sleep")
+func (h *PetHandlers) handlePetAction(w http.ResponseWriter, r *http.Request, actionName string) {
+	ctx := context.Background()
+	w.Header().Set("Content-Type", "application/json")
+
+	params := service.ActionParams{}
+
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.respondWithError(w, http.StatusBadRequest, "Failed to decode request")
+
+		return
+	}
+
+	tgData := r.Header.Get("X-Telegram-Init-Data")
+	if tgData == "" {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+			Success: false,
+			Message: "Нет initData",
+		})
+		return
+	}
+
+	parseData, err := parseInitData(tgData, h.botToken, h.isDev)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+			Success: false,
+			Message: "Не удалось прочитать tg-init-data",
+		})
+		return
+	}
+
+	result, err := h.s.PerformAction(ctx, getPetID(parseData), getActor(parseData), actionName, params)
+	if err != nil {
+		json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+			Success: false,
+			Message: actionErrorMessage(err, result),
+		})
+		return
+	}
+
+	result.GetAvatar(fmt.Sprintf("%s/%s", h.baseUrl, "static"))
+	h.s.Speak(ctx, getPetID(parseData), result.Pet, parseData.User.LanguageCode)
+
+	json.NewEncoder(w).Encode(entity.APIResponse[entity.PetActionResult]{
+		Success: true,
+		Data:    result,
+	})
 }
 
-func (h *PetHandlers) PetWakeUpHandler(w http.ResponseWriter, r *http.Request) {
-	h.handlePetAction(w, r, h.s.PetWakeUp, "wakeup")
+// actionErrorMessage Сообщение пользователю об ошибке действия.
+func actionErrorMessage(err error, result entity.PetActionResult) string {
+	switch {
+	case errors.Is(err, service.ErrPetNotFound):
+		return PetNotFindErr
+	case errors.Is(err, service.ErrActionDenied):
+		return result.Result.Message
+	case errors.Is(err, service.ErrUnknownAction):
+		return "Неизвестное действие"
+	case errors.Is(err, service.ErrUnknownTrick):
+		return "Неизвестный трюк"
+	case errors.Is(err, service.ErrUnknownActivity):
+		return "Неизвестное занятие"
+	case errors.Is(err, service.ErrConcurrentUpdate):
+		return service.ErrConcurrentUpdate.Error()
+	default:
+		return "Ошибка при выполнении действия"
+	}
 }
 
 func (h *PetHandlers) DebugMockInitDataHandler(w http.ResponseWriter, r *http.Request) {
@@ -341,6 +1055,43 @@ func (h *PetHandlers) respondWithError(w http.ResponseWriter, status int, messag
 	}
 }
 
+// parseInitData Проверяет подпись initData ключом бота и срок с auth_date, затем разбирает её: только
+// проверенной initData можно верить, от чьего имени запрос. В dev-режиме тестовая initData не подписана и
+// не проверяется.
+func parseInitData(raw, botToken string, isDev bool) (initdata.InitData, error) {
+	if !isDev {
+		err := initdata.Validate(raw, botToken, initDataExpiry)
+		if err != nil {
+			return initdata.InitData{}, err
+		}
+	}
+
+	return initdata.Parse(raw)
+}
+
+// canManageChat Менять настройки чата из Mini App может администратор группы, из которой открыто приложение,
+// а в личном чате — сам собеседник. Пользователь берётся из проверенной initData. Если группа неизвестна —
+// приложение открыто по chat_instance, — проверить права не у кого, и изменение запрещено.
+func (h *PetHandlers) canManageChat(ctx context.Context, data initdata.InitData) bool {
+	if data.User.ID == 0 {
+		return false
+	}
+
+	if getPetID(data) == int(data.User.ID) {
+		return true
+	}
+
+	if data.Chat.ID == 0 {
+		return false
+	}
+
+	return h.admins.IsChatAdmin(ctx, data.Chat.ID, data.User.ID)
+}
+
+func getActor(data initdata.InitData) entity.Actor {
+	return entity.Actor{ID: data.User.ID, Name: data.User.FirstName, Username: data.User.Username}
+}
+
 func getPetID(data initdata.InitData) int {
 	if data.ChatInstance != 0 {
 		return int(data.ChatInstance)
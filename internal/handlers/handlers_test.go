@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+// fakeChatAdmins Администраторы групп: группа — её администраторы.
+type fakeChatAdmins map[int64][]int64
+
+func (a fakeChatAdmins) IsChatAdmin(_ context.Context, chatID, userID int64) bool {
+	for _, admin := range a[chatID] {
+		if admin == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// groupInitData Неподписанная initData пользователя userID, открывшего приложение из группы chatID.
+func groupInitData(userID, chatID int64) string {
+	values := url.Values{}
+	values.Set("user", `{"id":`+strconv.FormatInt(userID, 10)+`,"first_name":"Аня"}`)
+	values.Set("chat", `{"id":`+strconv.FormatInt(chatID, 10)+`,"type":"group","title":"Гоча"}`)
+	values.Set("auth_date", strconv.FormatInt(time.Now().Unix(), 10))
+
+	return values.Encode()
+}
+
+func TestPetHandlers_SettingsHandler_NotAdmin(t *testing.T) {
+	t.Parallel()
+
+	const (
+		chatID = -100
+		admin  = 1
+		member = 2
+	)
+
+	// Сервиса нет: отказ должен прийти раньше, чем настройки читаются или сохраняются.
+	h := NewPetHandlers(zerolog.Nop(), nil, fakeChatAdmins{chatID: {admin}}, "", "", true)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/settings/", strings.NewReader(`{"mode":"digest"}`))
+	r.Header.Set("X-Telegram-Init-Data", groupInitData(member, chatID))
+
+	w := httptest.NewRecorder()
+	h.SettingsHandler(w, r)
+
+	var response entity.APIResponse[entity.NotifySettings]
+
+	err := json.NewDecoder(w.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Success || response.Message != manageChatDenied {
+		t.Errorf("SettingsHandler() = %+v, want the change refused", response)
+	}
+}
+
+func TestPetHandlers_LeaderboardHandler_NotAdmin(t *testing.T) {
+	t.Parallel()
+
+	h := NewPetHandlers(zerolog.Nop(), nil, fakeChatAdmins{-100: {1}}, "", "", true)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/leaderboard/", strings.NewReader(`{"hidden":true}`))
+	r.Header.Set("X-Telegram-Init-Data", groupInitData(2, -100))
+
+	w := httptest.NewRecorder()
+	h.LeaderboardHandler(w, r)
+
+	var response entity.APIResponse[entity.Leaderboards]
+
+	err := json.NewDecoder(w.Body).Decode(&response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Success || response.Message != manageChatDenied {
+		t.Errorf("LeaderboardHandler() = %+v, want the change refused", response)
+	}
+}
+
+func TestPetHandlers_CanManageChat(t *testing.T) {
+	t.Parallel()
+
+	h := NewPetHandlers(zerolog.Nop(), nil, fakeChatAdmins{-100: {1}}, "", "", true)
+
+	tests := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{name: "администратор группы", raw: groupInitData(1, -100), want: true},
+		{name: "участник группы", raw: groupInitData(2, -100)},
+		{name: "личный чат", raw: "user=" + url.QueryEscape(`{"id":2,"first_name":"Аня"}`), want: true},
+		{name: "группа неизвестна", raw: "chat_instance=42&user=" + url.QueryEscape(`{"id":1,"first_name":"Аня"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := parseInitData(tt.raw, "", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := h.canManageChat(context.Background(), data); got != tt.want {
+				t.Errorf("canManageChat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCardSecret(t *testing.T) {
+	t.Parallel()
+
+	const token = "123:bot-token"
+
+	if got := string(cardSecret("card-key", token)); got != "card-key" {
+		t.Errorf("cardSecret() = %q, want the configured key", got)
+	}
+
+	derived := cardSecret("", token)
+	if len(derived) == 0 || strings.Contains(string(derived), token) {
+		t.Errorf("cardSecret() = %x, want a key derived from the token", derived)
+	}
+
+	if string(cardSecret("", "456:other-token")) == string(derived) {
+		t.Error("cardSecret() derives the same key for different tokens")
+	}
+}
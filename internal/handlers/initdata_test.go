@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	initdata "github.com/telegram-mini-apps/init-data-golang"
+)
+
+// signedInitData initData от пользователя userID, подписанная ключом token в момент authDate.
+func signedInitData(token string, userID int64, authDate time.Time) string {
+	user := `{"id":` + strconv.FormatInt(userID, 10) + `,"first_name":"Аня"}`
+
+	values := url.Values{}
+	values.Set("user", user)
+	values.Set("chat_instance", "42")
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("hash", initdata.Sign(map[string]string{"user": user, "chat_instance": "42"}, token, authDate))
+
+	return values.Encode()
+}
+
+func TestParseInitData(t *testing.T) {
+	t.Parallel()
+
+	const token = "123:bot-token"
+
+	now := time.Now()
+	valid := signedInitData(token, 7, now)
+
+	// Чужой пользователь подставлен в подписанную initData.
+	forged, _ := url.ParseQuery(valid)
+	forged.Set("user", `{"id":1,"first_name":"Мэлори"}`)
+
+	tests := []struct {
+		name    string
+		raw     string
+		isDev   bool
+		wantErr bool
+	}{
+		{name: "подписанная ключом бота", raw: valid},
+		{name: "подписанная чужим ключом", raw: signedInitData("456:other-token", 7, now), wantErr: true},
+		{name: "подменённый пользователь", raw: forged.Encode(), wantErr: true},
+		{name: "просроченная", raw: signedInitData(token, 7, now.Add(-initDataExpiry-time.Minute)), wantErr: true},
+		{name: "без подписи", raw: "user=%7B%22id%22%3A7%7D&auth_date=" + strconv.FormatInt(now.Unix(), 10), wantErr: true},
+		{name: "в dev-режиме не проверяется", raw: forged.Encode(), isDev: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := parseInitData(tt.raw, token, tt.isDev)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseInitData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && getActor(data).ID == 0 {
+				t.Errorf("parseInitData() without user: %+v", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+
+	"gocha/internal/entity"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// TelegramNotifier Отправляет сообщения сервиса в чат через бота.
+type TelegramNotifier struct {
+	bot *telego.Bot
+}
+
+func NewTelegramNotifier(bot *telego.Bot) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot}
+}
+
+func (n *TelegramNotifier) Notify(ctx context.Context, chatID int, message string) error {
+	_, err := n.bot.SendMessage(ctx, tu.Message(tu.ID(int64(chatID)), message))
+
+	return err
+}
+
+// Alert Отправляет предупреждение с кнопками действий: нажатие выполняет действие прямо из сообщения.
+func (n *TelegramNotifier) Alert(ctx context.Context, chatID int, alert entity.Alert) error {
+	message := tu.Message(tu.ID(int64(chatID)), alert.Message)
+
+	if len(alert.Actions) > 0 {
+		row := make([]telego.InlineKeyboardButton, 0, len(alert.Actions))
+		for _, action := range alert.Actions {
+			row = append(row, tu.InlineKeyboardButton(action.Emoji+" "+action.Title).WithCallbackData(alertCallbackPrefix+action.Name))
+		}
+
+		message = message.WithReplyMarkup(tu.InlineKeyboard(row))
+	}
+
+	_, err := n.bot.SendMessage(ctx, message)
+
+	return err
+}
@@ -0,0 +1,14 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTemporary Доставка не удалась, но может получиться позже: сеть, 429 и 5xx вебхука, отказ 4xx почтового
+// сервера. Остальные ошибки повторять бесполезно.
+var ErrTemporary = errors.New("temporary delivery failure")
+
+func temporary(err error) error {
+	return fmt.Errorf("%w: %w", ErrTemporary, err)
+}
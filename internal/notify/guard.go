@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress Адрес вебхука ведёт в петлю, частную или локальную сеть.
+var ErrForbiddenAddress = errors.New("адрес вебхука во внутренней сети")
+
+// NewWebhookClient HTTP-клиент для вебхуков, который соединяется только с публичными адресами. Проверяется
+// адрес, к которому идёт соединение после разрешения имени, поэтому обойти запрет не помогут ни DNS, ни
+// перенаправления. Прокси из окружения не используется по той же причине.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublicOnly}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !PublicAddr(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// PublicAddr Адрес из интернета: не петля, не частная сеть, не локальный для канала, не групповой и не
+// нулевой.
+func PublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return ip.IsValid() && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
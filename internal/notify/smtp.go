@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// smtpDialTimeout Сколько ждать соединения с почтовым сервером, если срок контекста не наступит раньше.
+const smtpDialTimeout = 10 * time.Second
+
+// SMTPConfig Почтовый сервер для отправки уведомлений.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Mailer Шлёт уведомления письмом на адрес to.
+type Mailer struct {
+	cfg SMTPConfig
+	to  string
+}
+
+func NewMailer(cfg SMTPConfig, to string) *Mailer {
+	return &Mailer{cfg: cfg, to: to}
+}
+
+func (m *Mailer) Notify(ctx context.Context, chatID int, message string) error {
+	return m.send(ctx, "Новости питомца", fmt.Sprintf("%s\n\nЧат: %d", message, chatID))
+}
+
+// Alert Письмо с предупреждением; кнопок в почте нет, поэтому действия перечислены текстом.
+func (m *Mailer) Alert(ctx context.Context, chatID int, alert entity.Alert) error {
+	body := alert.Message
+
+	if len(alert.Actions) > 0 {
+		actions := make([]string, 0, len(alert.Actions))
+		for _, action := range alert.Actions {
+			actions = append(actions, fmt.Sprintf("%s %s (/%s)", action.Emoji, action.Title, action.Name))
+		}
+
+		body += "\n\nЧто поможет: " + strings.Join(actions, ", ")
+	}
+
+	return m.send(ctx, alert.Message, fmt.Sprintf("%s\n\nЧат: %d", body, chatID))
+}
+
+func (m *Mailer) send(ctx context.Context, subject, body string) error {
+	msg, err := m.message(subject, body)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: smtpDialTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port)))
+	if err != nil {
+		return temporary(err)
+	}
+	defer conn.Close()
+
+	// net/smtp не знает о контексте: весь разговор с сервером укладываем в его срок, а отмена рвёт соединение.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		return temporary(err)
+	}
+	defer c.Close()
+
+	err = m.deliver(c, msg)
+	if err != nil {
+		return smtpError(err)
+	}
+
+	// Письмо уже принято: сбой прощания не повод слать его второй раз.
+	return c.Quit()
+}
+
+// deliver Разговор с сервером, как в smtp.SendMail: STARTTLS, если сервер его умеет, вход и само письмо.
+func (m *Mailer) deliver(c *smtp.Client, msg []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err := c.StartTLS(&tls.Config{ServerName: m.cfg.Host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+
+		err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.Mail(m.cfg.From)
+	if err != nil {
+		return err
+	}
+
+	err = c.Rcpt(m.to)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// smtpError Окончательны только отказы сервера 5xx; 4xx, сетевые ошибки и истёкший срок можно повторить.
+func smtpError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return err
+	}
+
+	return temporary(err)
+}
+
+func (m *Mailer) message(subject, body string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+
+	_, err := w.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
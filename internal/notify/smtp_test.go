@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// smtpStandIn Минимальный SMTP-сервер на одно письмо: принимает всё и отдаёт получателей и текст письма.
+func smtpStandIn(t *testing.T) (SMTPConfig, <-chan []string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	result := make(chan []string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		_ = tp.PrintfLine("220 localhost ESMTP")
+
+		var got []string
+
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "RCPT":
+				got = append(got, line)
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+
+				data, _ := tp.ReadDotBytes()
+				got = append(got, string(data))
+				_ = tp.PrintfLine("250 OK")
+			case "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				result <- got
+
+				return
+			default:
+				_ = tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+
+	return SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "gocha@example.com"}, result
+}
+
+func TestMailer_Alert(t *testing.T) {
+	t.Parallel()
+
+	cfg, result := smtpStandIn(t)
+
+	alert := entity.Alert{
+		Type:    "hunger",
+		Message: "⚠️ Питомец очень голоден!",
+		Actions: []entity.ActionInfo{{Name: "feed", Title: "Покормить", Emoji: "🍎"}},
+	}
+
+	err := NewMailer(cfg, "team@example.com").Alert(context.Background(), 42, alert)
+	if err != nil {
+		t.Fatalf("Alert() error = %v", err)
+	}
+
+	got := <-result
+	if len(got) != 2 || !strings.Contains(got[0], "<team@example.com>") {
+		t.Fatalf("unexpected envelope %q", got)
+	}
+
+	// ReadDotBytes уже заменил CRLF на LF.
+	_, body, _ := strings.Cut(got[1], "\n\n")
+
+	text, err := io.ReadAll(quotedprintable.NewReader(bufio.NewReader(strings.NewReader(body))))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+
+	for _, want := range []string{"голоден", "Покормить", "/feed", strconv.Itoa(42)} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("mail body %q should contain %q", text, want)
+		}
+	}
+}
+
+func TestMailer_Errors(t *testing.T) {
+	t.Parallel()
+
+	// server Отвечает на соединение строками replies по одной на команду и молчит, когда они кончаются.
+	server := func(t *testing.T, replies ...string) SMTPConfig {
+		t.Helper()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+
+		t.Cleanup(func() { _ = ln.Close() })
+
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			tp := textproto.NewConn(conn)
+			for i, reply := range replies {
+				if i > 0 {
+					if _, err := tp.ReadLine(); err != nil {
+						return
+					}
+				}
+
+				_ = tp.PrintfLine("%s", reply)
+			}
+
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+
+		return SMTPConfig{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, From: "gocha@example.com"}
+	}
+
+	tests := []struct {
+		name          string
+		replies       []string
+		wantTemporary bool
+	}{
+		{name: "сервер молчит дольше срока", wantTemporary: true},
+		{name: "временный отказ", replies: []string{"220 localhost", "250 localhost", "451 try later"}, wantTemporary: true},
+		{name: "адрес отвергнут", replies: []string{"220 localhost", "250 localhost", "250 OK", "550 no such user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+
+			err := NewMailer(server(t, tt.replies...), "team@example.com").Notify(ctx, 1, "привет")
+			if err == nil || errors.Is(err, ErrTemporary) != tt.wantTemporary {
+				t.Errorf("Notify() error = %v, want temporary %v", err, tt.wantTemporary)
+			}
+
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Notify() took %v, deadline ignored", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// SignatureHeader Заголовок с подписью тела вебхука: "sha256=" и HMAC-SHA256 тела в hex по секрету канала.
+const SignatureHeader = "X-Gocha-Signature"
+
+// Webhook Шлёт уведомления POST-запросом с JSON, одна попытка на вызов. Сетевые ошибки, 429 и 5xx
+// возвращаются как ErrTemporary — повторять их решает очередь доставки; остальные ответы окончательные:
+// запрос уже не станет правильнее.
+type Webhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhook(url, secret string, client *http.Client) *Webhook {
+	return &Webhook{url: url, secret: secret, client: client}
+}
+
+// WebhookPayload Тело запроса вебхука.
+type WebhookPayload struct {
+	Type    string        `json:"type"` // message или alert
+	ChatID  int           `json:"chatId"`
+	Message string        `json:"message"`
+	Alert   *entity.Alert `json:"alert,omitempty"`
+	SentAt  time.Time     `json:"sentAt"`
+}
+
+func (w *Webhook) Notify(ctx context.Context, chatID int, message string) error {
+	return w.send(ctx, WebhookPayload{Type: "message", ChatID: chatID, Message: message, SentAt: time.Now()})
+}
+
+// Alert Отправляет предупреждение вместе с действиями, которые его исправляют.
+func (w *Webhook) Alert(ctx context.Context, chatID int, alert entity.Alert) error {
+	return w.send(ctx, WebhookPayload{Type: "alert", ChatID: chatID, Message: alert.Message, Alert: &alert, SentAt: time.Now()})
+}
+
+func (w *Webhook) send(ctx context.Context, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(w.secret, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrForbiddenAddress) {
+			return err
+		}
+
+		return temporary(err)
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < http.StatusMultipleChoices:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return temporary(fmt.Errorf("webhook responded %s", resp.Status))
+	default:
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+}
+
+// Sign Подпись тела вебхука; получатель считает её сам по общему секрету и сравнивает с заголовком.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"gocha/internal/entity"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("тело подписано секретом канала", func(t *testing.T) {
+		var (
+			payload   WebhookPayload
+			signature string
+			body      []byte
+		)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			signature = r.Header.Get(SignatureHeader)
+			_ = json.Unmarshal(body, &payload)
+		}))
+		defer srv.Close()
+
+		err := NewWebhook(srv.URL, "secret", srv.Client()).Notify(context.Background(), 42, "привет")
+		if err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+
+		if payload.Type != "message" || payload.ChatID != 42 || payload.Message != "привет" {
+			t.Errorf("unexpected payload %+v", payload)
+		}
+
+		if signature != Sign("secret", body) {
+			t.Errorf("signature %q does not match body", signature)
+		}
+
+		if signature == Sign("other", body) {
+			t.Errorf("signature should depend on the secret")
+		}
+	})
+
+	tests := []struct {
+		name          string
+		status        int
+		wantErr       bool
+		wantTemporary bool
+	}{
+		{name: "успех", status: http.StatusNoContent},
+		{name: "ошибка сервера временная", status: http.StatusBadGateway, wantErr: true, wantTemporary: true},
+		{name: "слишком часто — временная", status: http.StatusTooManyRequests, wantErr: true, wantTemporary: true},
+		{name: "ошибка клиента окончательная", status: http.StatusBadRequest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			// Повторы — забота очереди доставки: вебхук делает ровно одну попытку.
+			err := NewWebhook(srv.URL, "secret", srv.Client()).Notify(context.Background(), 1, "")
+			if (err != nil) != tt.wantErr || errors.Is(err, ErrTemporary) != tt.wantTemporary {
+				t.Errorf("Notify() error = %v, wantErr %v, temporary %v", err, tt.wantErr, tt.wantTemporary)
+			}
+
+			if calls.Load() != 1 {
+				t.Errorf("expected 1 attempt, got %d", calls.Load())
+			}
+		})
+	}
+
+	t.Run("сервер недоступен — временная", func(t *testing.T) {
+		t.Parallel()
+
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		err := NewWebhook(url, "secret", srv.Client()).Notify(context.Background(), 1, "")
+		if !errors.Is(err, ErrTemporary) {
+			t.Errorf("Notify() error = %v, want temporary", err)
+		}
+	})
+}
+
+func TestWebhook_Alert(t *testing.T) {
+	t.Parallel()
+
+	var payload WebhookPayload
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	alert := entity.Alert{Type: "hunger", Message: "голоден", Actions: []entity.ActionInfo{{Name: "feed"}}}
+
+	err := NewWebhook(srv.URL, "secret", srv.Client()).Alert(context.Background(), 7, alert)
+	if err != nil {
+		t.Fatalf("Alert() error = %v", err)
+	}
+
+	if payload.Type != "alert" || payload.Alert == nil || payload.Alert.Type != "hunger" || len(payload.Alert.Actions) != 1 {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+// testGiftLimits Лимиты, которые тесты не упирают, если не проверяют их сами.
+var testGiftLimits = entity.GiftLimits{PerDay: 10, CoinsPerDay: 1000, PendingPerTo: 10}
+
+func TestRepository_SendGift_Coins(t *testing.T) {
+	t.Parallel()
+
+	r := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	from, to, userID := int(newTestID()), int(newTestID()), newTestID()
+	coins := func(amount int) entity.Gift {
+		return entity.Gift{FromChatID: from, FromUserID: userID, FromName: "Аня", ToChatID: to, Kind: entity.GiftCoins,
+			Amount: amount, Title: "🪙", CreatedAt: now}
+	}
+	balance := func(chatID int, userID int64) int {
+		t.Helper()
+
+		got, err := r.GetCoins(ctx, chatID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return got
+	}
+
+	if _, err := r.AddCoins(ctx, from, userID, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// Не хватает монет: подарок не записан, кошелёк не тронут.
+	if _, err := r.SendGift(ctx, coins(150), testGiftLimits); !errors.Is(err, repo.ErrNotEnoughCoins) {
+		t.Fatalf("SendGift() error = %v, want %v", err, repo.ErrNotEnoughCoins)
+	}
+
+	accepted, err := r.SendGift(ctx, coins(30), testGiftLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	declined, err := r.SendGift(ctx, coins(20), testGiftLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := balance(from, userID); got != 50 {
+		t.Errorf("sender coins = %d, want 50", got)
+	}
+
+	// Монет за сутки больше лимита: откат.
+	limits := testGiftLimits
+	limits.Since, limits.CoinsPerDay = now.Add(-time.Hour), 60
+
+	if _, err = r.SendGift(ctx, coins(20), limits); !errors.Is(err, repo.ErrGiftLimit) {
+		t.Fatalf("coins limit error = %v, want %v", err, repo.ErrGiftLimit)
+	}
+
+	pending, err := r.GetGifts(ctx, to, entity.GiftPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending) != 2 || balance(from, userID) != 50 {
+		t.Fatalf("after rejected gifts: pending = %d, coins = %d, want 2 and 50", len(pending), balance(from, userID))
+	}
+
+	if _, err = r.ResolveGift(ctx, accepted, to, entity.GiftAccepted, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.ResolveGift(ctx, declined, to, entity.GiftDeclined, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Принятые монеты — в общем кошельке чата, отклонённые — снова у отправителя.
+	if got := balance(to, 0); got != 30 {
+		t.Errorf("recipient chat coins = %d, want 30", got)
+	}
+
+	if got := balance(from, userID); got != 70 {
+		t.Errorf("sender coins = %d, want 70", got)
+	}
+
+	if _, err = r.ResolveGift(ctx, accepted, to, entity.GiftDeclined, now); !errors.Is(err, repo.ErrGiftNotFound) {
+		t.Errorf("second answer error = %v, want %v", err, repo.ErrGiftNotFound)
+	}
+}
+
+func TestRepository_ResolveGift_Item(t *testing.T) {
+	t.Parallel()
+
+	r := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	from, to, userID := int(newTestID()), int(newTestID()), newTestID()
+	hat := entity.Gift{FromChatID: from, FromUserID: userID, FromName: "Аня", ToChatID: to, Kind: entity.GiftItem,
+		Item: "hat_cap", Slot: entity.SlotHat, Amount: 30, Title: "🧢 Кепка", CreatedAt: now}
+	owns := func(chatID int) bool {
+		t.Helper()
+
+		items, err := r.GetCosmetics(ctx, chatID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, ok := items[hat.Item]
+
+		return ok
+	}
+
+	if err := r.AddCosmetic(ctx, from, hat.Item, hat.Slot); err != nil {
+		t.Fatal(err)
+	}
+
+	// Надетый предмет не дарится.
+	if err := r.EquipCosmetic(ctx, from, hat.Slot, hat.Item); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.SendGift(ctx, hat, testGiftLimits); !errors.Is(err, repo.ErrItemNotOwned) {
+		t.Fatalf("equipped item error = %v, want %v", err, repo.ErrItemNotOwned)
+	}
+
+	if err := r.EquipCosmetic(ctx, from, hat.Slot, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := r.SendGift(ctx, hat, testGiftLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if owns(from) {
+		t.Fatal("sender still owns the gifted item")
+	}
+
+	// У получателя такой предмет уже есть: принять нельзя, подарок ждёт ответа дальше.
+	if err = r.AddCosmetic(ctx, to, hat.Item, hat.Slot); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.ResolveGift(ctx, first, to, entity.GiftAccepted, now); !errors.Is(err, repo.ErrItemOwned) {
+		t.Fatalf("accept owned item error = %v, want %v", err, repo.ErrItemOwned)
+	}
+
+	// Отказ возвращает предмет отправителю.
+	g, err := r.ResolveGift(ctx, first, to, entity.GiftDeclined, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Refunded || !owns(from) {
+		t.Fatalf("declined item: refunded = %v, sender owns = %v", g.Refunded, owns(from))
+	}
+
+	// Пока подарок ждал, отправитель купил такой же: при отказе ему возвращается цена.
+	second, err := r.SendGift(ctx, hat, testGiftLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.AddCosmetic(ctx, from, hat.Item, hat.Slot); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err = r.ResolveGift(ctx, second, to, entity.GiftDeclined, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coins, err := r.GetCoins(ctx, from, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !g.Refunded || coins != hat.Amount {
+		t.Errorf("refund: refunded = %v, coins = %d, want %d", g.Refunded, coins, hat.Amount)
+	}
+}
+
+func TestRepository_SendGift_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const senders = 6
+
+	r := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	from, to, userID := int(newTestID()), int(newTestID()), newTestID()
+	if _, err := r.AddCoins(ctx, from, userID, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	limits := testGiftLimits
+	limits.Since, limits.PerDay = now.Add(-time.Hour), 2
+
+	// Одновременные подарки одного пользователя идут по очереди и не обходят дневной лимит.
+	var (
+		wg   sync.WaitGroup
+		sent atomic.Int32
+	)
+
+	for range senders {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			g := entity.Gift{FromChatID: from, FromUserID: userID, FromName: "Аня", ToChatID: to, Kind: entity.GiftCoins,
+				Amount: 10, Title: "10 🪙", CreatedAt: now}
+
+			_, err := r.SendGift(ctx, g, limits)
+			switch {
+			case err == nil:
+				sent.Add(1)
+			case !errors.Is(err, repo.ErrGiftLimit):
+				t.Errorf("SendGift() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	coins, err := r.GetCoins(ctx, from, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sent.Load(); got != 2 || coins != 80 {
+		t.Errorf("sent %d gifts, coins = %d, want 2 and 80", got, coins)
+	}
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+func TestRepository_SavePlaydate(t *testing.T) {
+	t.Parallel()
+
+	r := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	host, guest, other := int(newTestID()), int(newTestID()), int(newTestID())
+	invite := func(chatID int, expiresAt time.Time) string {
+		code := fmt.Sprintf("T%d", newTestID())
+
+		err := r.SavePlaydateInvite(ctx, chatID, entity.PlaydateInvite{Code: code, ExpiresAt: expiresAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return code
+	}
+	visit := func(host, guest int, at time.Time) entity.Visit {
+		return entity.Visit{HostChatID: host, GuestChatID: guest, HostPet: "Гоча", GuestPet: "Мурка", Compatibility: 50, Bonus: 5, At: at}
+	}
+
+	expired := invite(host, now.Add(-time.Minute))
+	if err := r.SavePlaydate(ctx, expired, visit(host, guest, now), time.Hour); !errors.Is(err, repo.ErrInviteNotFound) {
+		t.Fatalf("expired invite error = %v, want %v", err, repo.ErrInviteNotFound)
+	}
+
+	code := invite(host, now.Add(time.Hour))
+	if err := r.SavePlaydate(ctx, code, visit(other, guest, now), time.Hour); !errors.Is(err, repo.ErrInviteNotFound) {
+		t.Fatalf("someone else's invite error = %v, want %v", err, repo.ErrInviteNotFound)
+	}
+
+	if err := r.SavePlaydate(ctx, code, visit(host, guest, now), time.Hour); err != nil {
+		t.Fatalf("SavePlaydate() error = %v", err)
+	}
+
+	// Код одноразовый.
+	if err := r.SavePlaydate(ctx, code, visit(host, guest, now.Add(2*time.Hour)), time.Hour); !errors.Is(err, repo.ErrInviteNotFound) {
+		t.Fatalf("reused invite error = %v, want %v", err, repo.ErrInviteNotFound)
+	}
+
+	// Гость недавно был в гостях: встреча откатывается, приглашение остаётся.
+	code = invite(other, now.Add(time.Hour))
+	if err := r.SavePlaydate(ctx, code, visit(other, guest, now.Add(time.Minute)), time.Hour); !errors.Is(err, repo.ErrPlaydateTooSoon) {
+		t.Fatalf("cooldown error = %v, want %v", err, repo.ErrPlaydateTooSoon)
+	}
+
+	if chatID, err := r.GetPlaydateInvite(ctx, code, now); err != nil || chatID != other {
+		t.Errorf("invite after rollback: chat = %d, err = %v", chatID, err)
+	}
+
+	visits, err := r.GetVisits(ctx, guest, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(visits) != 1 || visits[0].HostChatID != host || !visits[0].At.Equal(now) {
+		t.Errorf("visits of guest = %+v, want the one with host", visits)
+	}
+}
+
+func TestRepository_SavePlaydate_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const hosts = 4
+
+	r := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	guest := int(newTestID())
+
+	// Один гость принимает приглашения всех хозяев сразу: перерыв пропускает только одну встречу.
+	var (
+		wg    sync.WaitGroup
+		saved atomic.Int32
+	)
+
+	for range hosts {
+		host := int(newTestID())
+		code := fmt.Sprintf("T%d", newTestID())
+
+		err := r.SavePlaydateInvite(ctx, host, entity.PlaydateInvite{Code: code, ExpiresAt: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v := entity.Visit{HostChatID: host, GuestChatID: guest, HostPet: "Гоча", GuestPet: "Мурка", At: now}
+
+			err := r.SavePlaydate(ctx, code, v, time.Hour)
+			switch {
+			case err == nil:
+				saved.Add(1)
+			case !errors.Is(err, repo.ErrPlaydateTooSoon):
+				t.Errorf("SavePlaydate() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := saved.Load(); got != 1 {
+		t.Errorf("saved %d playdates, want 1", got)
+	}
+}
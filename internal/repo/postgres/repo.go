@@ -10,6 +10,8 @@ import (
 	"gocha/internal/entity"
 	"gocha/internal/repo"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 )
@@ -26,12 +28,195 @@ var sqlSavePet string
 //go:embed sql/load_pet.sql
 var sqlLoadPet string
 
+//go:embed sql/load_pets.sql
+var sqlLoadPets string
+
 //go:embed sql/get_chats.sql
 var sqlGetChats string
 
 //go:embed sql/deactivate_pets.sql
 var sqlDeactivatePets string
 
+//go:embed sql/get_care_reports.sql
+var sqlGetCareReports string
+
+//go:embed sql/save_care_report.sql
+var sqlSaveCareReport string
+
+//go:embed sql/get_streak.sql
+var sqlGetStreak string
+
+//go:embed sql/extend_streak.sql
+var sqlExtendStreak string
+
+//go:embed sql/add_coins.sql
+var sqlAddCoins string
+
+//go:embed sql/get_coins.sql
+var sqlGetCoins string
+
+//go:embed sql/spend_coins.sql
+var sqlSpendCoins string
+
+//go:embed sql/get_cosmetics.sql
+var sqlGetCosmetics string
+
+//go:embed sql/add_cosmetic.sql
+var sqlAddCosmetic string
+
+//go:embed sql/mark_event_announced.sql
+var sqlMarkEventAnnounced string
+
+//go:embed sql/save_playdate_invite.sql
+var sqlSavePlaydateInvite string
+
+//go:embed sql/get_playdate_invite.sql
+var sqlGetPlaydateInvite string
+
+//go:embed sql/take_playdate_invite.sql
+var sqlTakePlaydateInvite string
+
+//go:embed sql/save_visit.sql
+var sqlSaveVisit string
+
+//go:embed sql/lock_playdate_chat.sql
+var sqlLockPlaydateChat string
+
+//go:embed sql/count_recent_visits.sql
+var sqlCountRecentVisits string
+
+//go:embed sql/get_visits.sql
+var sqlGetVisits string
+
+//go:embed sql/save_username.sql
+var sqlSaveUsername string
+
+//go:embed sql/get_username_chat.sql
+var sqlGetUsernameChat string
+
+//go:embed sql/lock_gift_sender.sql
+var sqlLockGiftSender string
+
+//go:embed sql/get_gift_usage.sql
+var sqlGetGiftUsage string
+
+//go:embed sql/count_pending_gifts.sql
+var sqlCountPendingGifts string
+
+//go:embed sql/take_cosmetic.sql
+var sqlTakeCosmetic string
+
+//go:embed sql/save_gift.sql
+var sqlSaveGift string
+
+//go:embed sql/resolve_gift.sql
+var sqlResolveGift string
+
+//go:embed sql/get_gifts.sql
+var sqlGetGifts string
+
+//go:embed sql/equip_cosmetic.sql
+var sqlEquipCosmetic string
+
+//go:embed sql/get_daily_quests.sql
+var sqlGetDailyQuests string
+
+//go:embed sql/save_daily_quests.sql
+var sqlSaveDailyQuests string
+
+//go:embed sql/get_chat_timezone.sql
+var sqlGetChatTimezone string
+
+//go:embed sql/set_chat_timezone.sql
+var sqlSetChatTimezone string
+
+//go:embed sql/get_notify_settings.sql
+var sqlGetNotifySettings string
+
+//go:embed sql/save_notify_settings.sql
+var sqlSaveNotifySettings string
+
+//go:embed sql/get_diary.sql
+var sqlGetDiary string
+
+//go:embed sql/save_diary_entry.sql
+var sqlSaveDiaryEntry string
+
+//go:embed sql/get_last_alert.sql
+var sqlGetLastAlert string
+
+//go:embed sql/update_last_alert.sql
+var sqlUpdateLastAlert string
+
+//go:embed sql/get_notify_channels.sql
+var sqlGetNotifyChannels string
+
+//go:embed sql/save_notify_channel.sql
+var sqlSaveNotifyChannel string
+
+//go:embed sql/delete_notify_channel.sql
+var sqlDeleteNotifyChannel string
+
+//go:embed sql/add_history.sql
+var sqlAddHistory string
+
+//go:embed sql/get_history.sql
+var sqlGetHistory string
+
+//go:embed sql/add_stat_sample.sql
+var sqlAddStatSample string
+
+//go:embed sql/compact_stat_samples.sql
+var sqlCompactStatSamples string
+
+//go:embed sql/delete_stat_samples.sql
+var sqlDeleteStatSamples string
+
+//go:embed sql/get_stat_series.sql
+var sqlGetStatSeries string
+
+//go:embed sql/top_age.sql
+var sqlTopAge string
+
+//go:embed sql/top_level.sql
+var sqlTopLevel string
+
+//go:embed sql/top_care.sql
+var sqlTopCare string
+
+//go:embed sql/top_streak.sql
+var sqlTopStreak string
+
+//go:embed sql/chat_top_care.sql
+var sqlChatTopCare string
+
+//go:embed sql/chat_top_streak.sql
+var sqlChatTopStreak string
+
+//go:embed sql/get_top_hidden.sql
+var sqlGetTopHidden string
+
+//go:embed sql/set_top_hidden.sql
+var sqlSetTopHidden string
+
+//go:embed sql/getUserRole.sql
+var sqlGetUserRole string
+
+//go:embed sql/get_chat_roles.sql
+var sqlGetChatRoles string
+
+//go:embed sql/set_user_role.sql
+var sqlSetUserRole string
+
+//go:embed sql/delete_user_role.sql
+var sqlDeleteUserRole string
+
+//go:embed sql/mark_care_report_sent.sql
+var sqlMarkCareReportSent string
+
+//go:embed sql/unmark_care_report_sent.sql
+var sqlUnmarkCareReportSent string
+
 type Repository struct {
 	logger *zerolog.Logger
 	db     *pgxpool.Pool
@@ -58,7 +243,7 @@ func (r *Repository) NewPet(ctx context.Context, p *entity.Pet, chatID int) erro
 		return err
 	}
 
-	_, err = r.db.Exec(ctx, sqlNewPet, chatID, p.Name, p.Health, p.Hunger, p.Happiness, p.Energy, p.Hygiene, time.Now())
+	err = r.db.QueryRow(ctx, sqlNewPet, chatID, p.Name, p.Health, p.Hunger, p.Happiness, p.Energy, p.Hygiene, time.Now()).Scan(&p.ID)
 	if err != nil {
 		return err
 	}
@@ -66,36 +251,116 @@ func (r *Repository) NewPet(ctx context.Context, p *entity.Pet, chatID int) erro
 	return nil
 }
 
+// SavePet Сохраняет снимок питомца, если с его загрузки никто не сохранял питомца раньше; иначе
+// repo.ErrVersionConflict. При успехе p.Version становится новой версией.
 func (r *Repository) SavePet(ctx context.Context, p *entity.Pet, chatID int) error {
-	_, err := r.db.Exec(ctx, sqlSavePet, chatID, p.Name, p.Health, p.Hunger, p.Happiness, p.Energy, p.Hygiene,
-		p.State, p.SleepStartTime, p.Config.HungerDecayRate, p.Config.EnergyDecayRate, p.Config.HygieneDecayRate, p.Config.HappinessDecayRate, time.Now())
+	return savedVersion(r.db.QueryRow(ctx, sqlSavePet, savePetArgs(p, chatID)...), p)
+}
+
+func savePetArgs(p *entity.Pet, chatID int) []any {
+	return []any{p.ID, chatID, p.Name, p.Health, p.Hunger, p.Happiness, p.Energy, p.Hygiene,
+		p.State, p.SleepStartTime, p.Config.HungerDecayRate, p.Config.EnergyDecayRate, p.Config.HygieneDecayRate, p.Config.HappinessDecayRate, p.LastUpdated,
+		p.Skills, p.Activity, p.RecentCare, p.Version}
+}
+
+// savedVersion Читает новую версию после условного сохранения; нет строки — версия уже другая.
+func savedVersion(row pgx.Row, p *entity.Pet) error {
+	err := row.Scan(&p.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.ErrVersionConflict
+	}
 
 	return err
 }
 
 func (r *Repository) LoadPet(ctx context.Context, chatID int) (*entity.Pet, error) {
+	p, err := scanPet(r.db.QueryRow(ctx, sqlLoadPet, chatID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrPetNotFound
+		}
+
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// LoadPets Живые питомцы нескольких чатов одним запросом: chat_id -> питомец. Чатов без питомца в ответе нет.
+func (r *Repository) LoadPets(ctx context.Context, chatIDs []int) (map[int]*entity.Pet, error) {
+	rows, err := r.db.Query(ctx, sqlLoadPets, chatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	pets := make(map[int]*entity.Pet, len(chatIDs))
+	for rows.Next() {
+		var chatID int
+
+		p, err := scanPet(rows, &chatID)
+		if err != nil {
+			return nil, err
+		}
+
+		pets[chatID] = p
+	}
+
+	return pets, rows.Err()
+}
+
+// SavePets Сохраняет питомцев одним пакетом, как SavePet каждого. Возвращает чаты, чьих питомцев успели
+// изменить после загрузки: их снимки не записаны.
+func (r *Repository) SavePets(ctx context.Context, pets map[int]*entity.Pet) ([]int, error) {
+	batch := &pgx.Batch{}
+	chatIDs := make([]int, 0, len(pets))
+
+	for chatID, p := range pets {
+		batch.Queue(sqlSavePet, savePetArgs(p, chatID)...)
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	results := r.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	var conflicts []int
+
+	for _, chatID := range chatIDs {
+		err := savedVersion(results.QueryRow(), pets[chatID])
+
+		switch {
+		case errors.Is(err, repo.ErrVersionConflict):
+			conflicts = append(conflicts, chatID)
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	return conflicts, results.Close()
+}
+
+// scanPet Читает питомца в порядке колонок load_pet.sql; extra — колонки после id.
+func scanPet(row pgx.Row, extra ...any) (*entity.Pet, error) {
 	var (
 		p         entity.Pet
 		petConfig entity.PetConfig
 	)
 
 	createdAt := time.Time{}
-	err := r.db.QueryRow(ctx, sqlLoadPet, chatID).Scan(
+	err := row.Scan(append([]any{
 		&p.Name, &p.Health, &p.Hunger, &p.Happiness, &p.Energy, &p.Hygiene,
 		&p.State, &p.SleepStartTime, &petConfig.HungerDecayRate, &petConfig.EnergyDecayRate, &petConfig.HygieneDecayRate, &petConfig.HappinessDecayRate, &p.LastUpdated,
-		&createdAt,
-	)
+		&createdAt, &p.Skills, &p.Activity, &p.RecentCare, &p.Version, &p.ID,
+	}, extra...)...)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, repo.ErrPetNotFound
-		}
-
 		return nil, err
 	}
 
 	age := time.Since(createdAt).Hours() / 24
 
 	p.Age = int(age)
+	p.CreatedAt = createdAt
 
 	p.Config = petConfig
 
@@ -125,30 +390,786 @@ func (r *Repository) GetChats(ctx context.Context) ([]int, error) {
 	return chats, nil
 }
 
-func (r *Repository) GetLastAlert(ctx context.Context, chatID int, alertType string) (time.Time, error) {
-	var lastAlert time.Time
+func (r *Repository) GetCareReports(ctx context.Context, chatID int, from, to time.Time) ([]entity.CareReport, error) {
+	rows, err := r.db.Query(ctx, sqlGetCareReports, chatID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
 
-	query := `SELECT last_alert FROM alerts WHERE chat_id = ? AND alert_type = ?`
-	err := r.db.QueryRow(ctx, query, chatID, alertType).Scan(&lastAlert)
+	reports := make([]entity.CareReport, 0)
+	for rows.Next() {
+		var c entity.CareReport
 
-	// Если в БД еще нет записи, возвращаем старую дату (чтобы сразу отправить уведомление)
+		err = rows.Scan(&c.Day, &c.Score, &c.ObservedMinutes, &c.WarningMinutes, &c.CriticalMinutes, &c.NeglectMinutes,
+			&c.Alerts, &c.RespondedAlerts, &c.ResponseSeconds, &c.Actions, &c.LastActionAt, &c.PendingAlertAt, &c.LastSampleAt)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, c)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *Repository) SaveCareReport(ctx context.Context, chatID int, c entity.CareReport) error {
+	_, err := r.db.Exec(ctx, sqlSaveCareReport, chatID, c.Day, c.Score, c.ObservedMinutes, c.WarningMinutes, c.CriticalMinutes, c.NeglectMinutes,
+		c.Alerts, c.RespondedAlerts, c.ResponseSeconds, c.Actions, c.LastActionAt, c.PendingAlertAt, c.LastSampleAt)
+
+	return err
+}
+
+// GetStreak Возвращает серию; если записи нет — пустую серию.
+func (r *Repository) GetStreak(ctx context.Context, chatID int, userID int64) (entity.Streak, error) {
+	var st entity.Streak
+
+	err := r.db.QueryRow(ctx, sqlGetStreak, chatID, userID).Scan(&st.Current, &st.Best, &st.Freezes, &st.LastDay)
 	if errors.Is(err, sql.ErrNoRows) {
-		return time.Time{}, nil
+		return entity.Streak{}, nil
 	} else if err != nil {
-		return time.Time{}, err
+		return entity.Streak{}, err
 	}
 
-	return lastAlert, nil
+	return st, nil
 }
 
-func (r *Repository) UpdateLastAlert(ctx context.Context, chatID int, alertType string, now time.Time) error {
-	query := `
-	INSERT INTO alerts (chat_id, alert_type, last_alert) 
-	VALUES (?, ?, ?) 
-	ON CONFLICT(chat_id, alert_type) 
-	DO UPDATE SET last_alert = excluded.last_alert;
-	`
-	_, err := r.db.Exec(ctx, query, chatID, alertType, now)
+// ExtendStreak Сохраняет продлённую серию, только если в базе всё ещё серия с последним днём prevDay (нулевой —
+// серии не было). false — серию успели продлить параллельно, сохранять и награждать нечего.
+func (r *Repository) ExtendStreak(ctx context.Context, chatID int, userID int64, prevDay time.Time, st entity.Streak) (bool, error) {
+	tag, err := r.db.Exec(ctx, sqlExtendStreak, chatID, userID, st.Current, st.Best, st.Freezes, st.LastDay, prevDay)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// AddCoins Начисляет (или списывает при отрицательном amount) монеты и возвращает новый баланс.
+func (r *Repository) AddCoins(ctx context.Context, chatID int, userID int64, amount int) (int, error) {
+	var coins int
+
+	err := r.db.QueryRow(ctx, sqlAddCoins, chatID, userID, amount).Scan(&coins)
+
+	return coins, err
+}
+
+func (r *Repository) GetCoins(ctx context.Context, chatID int, userID int64) (int, error) {
+	var coins int
+
+	err := r.db.QueryRow(ctx, sqlGetCoins, chatID, userID).Scan(&coins)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return coins, err
+}
+
+// GetDailyQuests Возвращает задания чата на день или repo.ErrQuestsNotFound.
+func (r *Repository) GetDailyQuests(ctx context.Context, chatID int, day time.Time) ([]entity.Quest, error) {
+	var quests []entity.Quest
+
+	err := r.db.QueryRow(ctx, sqlGetDailyQuests, chatID, day).Scan(&quests)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repo.ErrQuestsNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return quests, nil
+}
+
+func (r *Repository) SaveDailyQuests(ctx context.Context, chatID int, day time.Time, quests []entity.Quest) error {
+	_, err := r.db.Exec(ctx, sqlSaveDailyQuests, chatID, day, quests)
+
+	return err
+}
+
+// GetChatTimezone Возвращает часовой пояс чата; пустую строку, если он не задан.
+func (r *Repository) GetChatTimezone(ctx context.Context, chatID int) (string, error) {
+	var timezone string
+
+	err := r.db.QueryRow(ctx, sqlGetChatTimezone, chatID).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return timezone, err
+}
+
+func (r *Repository) SetChatTimezone(ctx context.Context, chatID int, timezone string) error {
+	_, err := r.db.Exec(ctx, sqlSetChatTimezone, chatID, timezone)
+
+	return err
+}
+
+// GetNotifySettings Настройки предупреждений чата; nil, если чат их не менял.
+func (r *Repository) GetNotifySettings(ctx context.Context, chatID int) (*entity.NotifySettings, error) {
+	var settings entity.NotifySettings
+
+	err := r.db.QueryRow(ctx, sqlGetNotifySettings, chatID).Scan(&settings)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+func (r *Repository) SaveNotifySettings(ctx context.Context, chatID int, settings entity.NotifySettings) error {
+	_, err := r.db.Exec(ctx, sqlSaveNotifySettings, chatID, settings)
+
+	return err
+}
+
+// GetDiary Записи дневника питомца не позже дня to, от новых к старым.
+func (r *Repository) GetDiary(ctx context.Context, petID int, to time.Time, limit int) ([]entity.DiaryEntry, error) {
+	rows, err := r.db.Query(ctx, sqlGetDiary, petID, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := make([]entity.DiaryEntry, 0)
+	for rows.Next() {
+		var e entity.DiaryEntry
+
+		err = rows.Scan(&e.Day, &e.Facts)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *Repository) SaveDiaryEntry(ctx context.Context, petID int, e entity.DiaryEntry) error {
+	_, err := r.db.Exec(ctx, sqlSaveDiaryEntry, petID, e.Day, e.Facts)
+
+	return err
+}
+
+// AddHistory Дописывает запись в журнал действий. Записи журнала не меняются и не удаляются.
+func (r *Repository) AddHistory(ctx context.Context, chatID, petID int, e entity.HistoryEntry) error {
+	_, err := r.db.Exec(ctx, sqlAddHistory, chatID, petID, e.Actor.ID, e.Actor.Name, e.Action, e.Params, e.Before, e.After,
+		e.Success, e.Message, e.At.UTC())
 
 	return err
 }
+
+// GetHistory Записи журнала питомца с ID меньше before (0 — с самой свежей), от новых к старым.
+func (r *Repository) GetHistory(ctx context.Context, petID int, before int64, limit int) ([]entity.HistoryEntry, error) {
+	rows, err := r.db.Query(ctx, sqlGetHistory, petID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := make([]entity.HistoryEntry, 0)
+	for rows.Next() {
+		var e entity.HistoryEntry
+
+		err = rows.Scan(&e.ID, &e.Actor.ID, &e.Actor.Name, &e.Action, &e.Params, &e.Before, &e.After, &e.Success,
+			&e.Message, &e.At)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// AddStatSamples Записывает замеры статов пачкой: ключ — ID питомца.
+func (r *Repository) AddStatSamples(ctx context.Context, samples map[int][]entity.StatPoint) error {
+	batch := &pgx.Batch{}
+
+	for petID, points := range samples {
+		for _, p := range points {
+			batch.Queue(sqlAddStatSample, petID, p.At.UTC(), p.Health, p.Hunger, p.Happiness, p.Energy, p.Hygiene)
+		}
+	}
+
+	return r.db.SendBatch(ctx, batch).Close()
+}
+
+// CompactStatSamples Сворачивает замеры старше hourlyBefore в часовые средние и удаляет всё старше dropBefore.
+func (r *Repository) CompactStatSamples(ctx context.Context, hourlyBefore, dropBefore time.Time) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, sqlDeleteStatSamples, dropBefore.UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sqlCompactStatSamples, hourlyBefore.UTC())
+
+		return err
+	})
+}
+
+// GetStatSeries Средние статы питомца за [from, to] по отрезкам step, от старых к новым.
+func (r *Repository) GetStatSeries(ctx context.Context, petID int, from, to time.Time, step time.Duration) ([]entity.StatPoint, error) {
+	rows, err := r.db.Query(ctx, sqlGetStatSeries, petID, from.UTC(), to.UTC(), int(step.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	points := make([]entity.StatPoint, 0)
+	for rows.Next() {
+		var p entity.StatPoint
+
+		err = rows.Scan(&p.At, &p.Health, &p.Hunger, &p.Happiness, &p.Energy, &p.Hygiene)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// GetLeaderboard Лучшие питомцы всех чатов, кроме скрытых, по виду рейтинга. Уход считается по дням с since.
+func (r *Repository) GetLeaderboard(ctx context.Context, category entity.LeaderboardCategory, since time.Time, limit int) ([]entity.LeaderboardEntry, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	switch category {
+	case entity.LeaderboardAge:
+		rows, err = r.db.Query(ctx, sqlTopAge, limit)
+	case entity.LeaderboardLevel:
+		rows, err = r.db.Query(ctx, sqlTopLevel, limit)
+	case entity.LeaderboardCare:
+		rows, err = r.db.Query(ctx, sqlTopCare, limit, since)
+	case entity.LeaderboardStreak:
+		rows, err = r.db.Query(ctx, sqlTopStreak, limit)
+	default:
+		return nil, repo.ErrUnknownLeaderboard
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := make([]entity.LeaderboardEntry, 0, limit)
+	for rows.Next() {
+		var e entity.LeaderboardEntry
+
+		err = rows.Scan(&e.ChatID, &e.Name, &e.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetChatLeaderboard Лучшие участники чата по виду рейтинга: уход — успешные действия careActions с since.
+func (r *Repository) GetChatLeaderboard(ctx context.Context, chatID int, category entity.LeaderboardCategory, careActions []string, since time.Time, limit int) ([]entity.LeaderboardEntry, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+
+	switch category {
+	case entity.LeaderboardCare:
+		rows, err = r.db.Query(ctx, sqlChatTopCare, chatID, since.UTC(), careActions, limit)
+	case entity.LeaderboardStreak:
+		rows, err = r.db.Query(ctx, sqlChatTopStreak, chatID, limit)
+	default:
+		return nil, repo.ErrUnknownLeaderboard
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := make([]entity.LeaderboardEntry, 0, limit)
+	for rows.Next() {
+		e := entity.LeaderboardEntry{ChatID: chatID}
+
+		err = rows.Scan(&e.UserID, &e.Name, &e.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetTopHidden Скрыт ли чат из общих рейтингов.
+func (r *Repository) GetTopHidden(ctx context.Context, chatID int) (bool, error) {
+	var hidden bool
+
+	err := r.db.QueryRow(ctx, sqlGetTopHidden, chatID).Scan(&hidden)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return hidden, err
+}
+
+func (r *Repository) SetTopHidden(ctx context.Context, chatID int, hidden bool) error {
+	_, err := r.db.Exec(ctx, sqlSetTopHidden, chatID, hidden)
+
+	return err
+}
+
+// GetUserRole Роль участника чата; пустая, если роль не назначена.
+func (r *Repository) GetUserRole(ctx context.Context, chatID int, userID int64) (entity.Role, error) {
+	var role entity.Role
+
+	err := r.db.QueryRow(ctx, sqlGetUserRole, chatID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return role, err
+}
+
+func (r *Repository) GetChatRoles(ctx context.Context, chatID int) ([]entity.ChatRole, error) {
+	rows, err := r.db.Query(ctx, sqlGetChatRoles, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	roles := make([]entity.ChatRole, 0)
+	for rows.Next() {
+		var cr entity.ChatRole
+
+		err = rows.Scan(&cr.UserID, &cr.Name, &cr.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, cr)
+	}
+
+	return roles, rows.Err()
+}
+
+func (r *Repository) SetUserRole(ctx context.Context, chatID int, cr entity.ChatRole) error {
+	_, err := r.db.Exec(ctx, sqlSetUserRole, chatID, cr.UserID, cr.Role, cr.Name)
+
+	return err
+}
+
+// DeleteUserRole Снимает роль с участника; repo.ErrNoRole, если роли не было.
+func (r *Repository) DeleteUserRole(ctx context.Context, chatID int, userID int64) error {
+	tag, err := r.db.Exec(ctx, sqlDeleteUserRole, chatID, userID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repo.ErrNoRole
+	}
+
+	return nil
+}
+
+// SpendCoins Списывает монеты, только если их хватает, и возвращает новый баланс.
+func (r *Repository) SpendCoins(ctx context.Context, chatID int, userID int64, amount int) (int, error) {
+	var coins int
+
+	err := r.db.QueryRow(ctx, sqlSpendCoins, chatID, userID, amount).Scan(&coins)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.ErrNotEnoughCoins
+	}
+
+	return coins, err
+}
+
+// GetCosmetics Купленные чатом предметы: id -> надет ли.
+func (r *Repository) GetCosmetics(ctx context.Context, chatID int) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, sqlGetCosmetics, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make(map[string]bool)
+	for rows.Next() {
+		var (
+			id       string
+			equipped bool
+		)
+
+		err = rows.Scan(&id, &equipped)
+		if err != nil {
+			return nil, err
+		}
+
+		items[id] = equipped
+	}
+
+	return items, rows.Err()
+}
+
+func (r *Repository) AddCosmetic(ctx context.Context, chatID int, itemID string, slot entity.CosmeticSlot) error {
+	_, err := r.db.Exec(ctx, sqlAddCosmetic, chatID, itemID, slot)
+
+	return err
+}
+
+// EquipCosmetic Надевает предмет на место slot и снимает остальные; пустой itemID освобождает место.
+func (r *Repository) EquipCosmetic(ctx context.Context, chatID int, slot entity.CosmeticSlot, itemID string) error {
+	_, err := r.db.Exec(ctx, sqlEquipCosmetic, chatID, slot, itemID)
+
+	return err
+}
+
+// MarkEventAnnounced Отмечает проведение события объявленным; false, если его уже объявляли.
+func (r *Repository) MarkEventAnnounced(ctx context.Context, chatID int, eventID string, startedAt time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, sqlMarkEventAnnounced, chatID, eventID, startedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkCareReportSent Отмечает, что итоги дня отправлены. false — отчёта за день нет или он уже отправлен.
+func (r *Repository) MarkCareReportSent(ctx context.Context, chatID int, day time.Time, now time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, sqlMarkCareReportSent, chatID, day, now)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// UnmarkCareReportSent Снимает отметку об отправке итогов дня: их не удалось доставить.
+func (r *Repository) UnmarkCareReportSent(ctx context.Context, chatID int, day time.Time) error {
+	_, err := r.db.Exec(ctx, sqlUnmarkCareReportSent, chatID, day)
+
+	return err
+}
+
+func (r *Repository) SavePlaydateInvite(ctx context.Context, chatID int, invite entity.PlaydateInvite) error {
+	_, err := r.db.Exec(ctx, sqlSavePlaydateInvite, invite.Code, chatID, invite.ExpiresAt)
+
+	return err
+}
+
+// GetPlaydateInvite Чат-хозяин действующего приглашения.
+func (r *Repository) GetPlaydateInvite(ctx context.Context, code string, now time.Time) (int, error) {
+	var chatID int
+
+	err := r.db.QueryRow(ctx, sqlGetPlaydateInvite, code, now).Scan(&chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.ErrInviteNotFound
+	}
+
+	return chatID, err
+}
+
+// SavePlaydate В одной транзакции использует приглашение code, проверяет, что оба питомца не виделись с кем-то
+// за cooldown, и записывает встречу. Код одноразовый: второй раз вернётся ErrInviteNotFound.
+func (r *Repository) SavePlaydate(ctx context.Context, code string, v entity.Visit, cooldown time.Duration) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Встречи одного чата идут по очереди, иначе параллельные приглашения обойдут перерыв. Блокируем
+		// в одном порядке, чтобы встречные приглашения не ждали друг друга.
+		for _, chatID := range []int{min(v.HostChatID, v.GuestChatID), max(v.HostChatID, v.GuestChatID)} {
+			_, err := tx.Exec(ctx, sqlLockPlaydateChat, chatID)
+			if err != nil {
+				return err
+			}
+		}
+
+		tag, err := tx.Exec(ctx, sqlTakePlaydateInvite, code, v.HostChatID, v.At)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return repo.ErrInviteNotFound
+		}
+
+		var recent int
+
+		err = tx.QueryRow(ctx, sqlCountRecentVisits, v.HostChatID, v.GuestChatID, v.At.Add(-cooldown)).Scan(&recent)
+		if err != nil {
+			return err
+		}
+
+		if recent > 0 {
+			return repo.ErrPlaydateTooSoon
+		}
+
+		_, err = tx.Exec(ctx, sqlSaveVisit, v.HostChatID, v.GuestChatID, v.HostPet, v.GuestPet, v.Compatibility, v.Bonus, v.At)
+
+		return err
+	})
+}
+
+// GetVisits Последние встречи, в которых чат был хозяином или гостем.
+func (r *Repository) GetVisits(ctx context.Context, chatID int, limit int) ([]entity.Visit, error) {
+	rows, err := r.db.Query(ctx, sqlGetVisits, chatID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	visits := make([]entity.Visit, 0)
+	for rows.Next() {
+		var v entity.Visit
+
+		err = rows.Scan(&v.HostChatID, &v.GuestChatID, &v.HostPet, &v.GuestPet, &v.Compatibility, &v.Bonus, &v.At)
+		if err != nil {
+			return nil, err
+		}
+
+		visits = append(visits, v)
+	}
+
+	return visits, rows.Err()
+}
+
+func (r *Repository) SaveUsername(ctx context.Context, username string, userID int64, chatID int, now time.Time) error {
+	_, err := r.db.Exec(ctx, sqlSaveUsername, username, userID, chatID, now)
+
+	return err
+}
+
+// GetUsernameChat Чат, где пользователя видели последним.
+func (r *Repository) GetUsernameChat(ctx context.Context, username string) (int, error) {
+	var chatID int
+
+	err := r.db.QueryRow(ctx, sqlGetUsernameChat, username).Scan(&chatID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.ErrUserNotFound
+	}
+
+	return chatID, err
+}
+
+// SendGift В одной транзакции проверяет лимиты, списывает монеты или предмет у отправителя и создаёт подарок.
+func (r *Repository) SendGift(ctx context.Context, g entity.Gift, limits entity.GiftLimits) (int64, error) {
+	var id int64
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		// Подарки одного отправителя идут по очереди, иначе параллельные запросы обойдут лимиты.
+		_, err := tx.Exec(ctx, sqlLockGiftSender, g.FromUserID)
+		if err != nil {
+			return err
+		}
+
+		var sent, coins int
+
+		err = tx.QueryRow(ctx, sqlGetGiftUsage, g.FromUserID, limits.Since).Scan(&sent, &coins)
+		if err != nil {
+			return err
+		}
+
+		if sent >= limits.PerDay || g.Kind == entity.GiftCoins && coins+g.Amount > limits.CoinsPerDay {
+			return repo.ErrGiftLimit
+		}
+
+		var pending int
+
+		err = tx.QueryRow(ctx, sqlCountPendingGifts, g.ToChatID).Scan(&pending)
+		if err != nil {
+			return err
+		}
+
+		if pending >= limits.PendingPerTo {
+			return repo.ErrGiftLimit
+		}
+
+		switch g.Kind {
+		case entity.GiftCoins:
+			var balance int
+
+			err = tx.QueryRow(ctx, sqlSpendCoins, g.FromChatID, g.FromUserID, g.Amount).Scan(&balance)
+			if errors.Is(err, sql.ErrNoRows) {
+				return repo.ErrNotEnoughCoins
+			}
+		case entity.GiftItem:
+			var tag pgconn.CommandTag
+
+			tag, err = tx.Exec(ctx, sqlTakeCosmetic, g.FromChatID, g.Item)
+			if err == nil && tag.RowsAffected() == 0 {
+				return repo.ErrItemNotOwned
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, sqlSaveGift, g.FromChatID, g.FromUserID, g.FromName, g.ToChatID, g.Kind,
+			g.Item, g.Slot, g.Amount, g.Title, g.CreatedAt).Scan(&id)
+	})
+
+	return id, err
+}
+
+// ResolveGift В одной транзакции принимает или отклоняет подарок чата chatID: при согласии монеты или предмет
+// достаются получателю, при отказе возвращаются отправителю. Отклонённый предмет, который у отправителя уже есть,
+// возвращается ему ценой: отказ разбирает подарок всегда.
+func (r *Repository) ResolveGift(ctx context.Context, giftID int64, chatID int, status entity.GiftStatus, now time.Time) (entity.Gift, error) {
+	var g entity.Gift
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		err := scanGift(tx.QueryRow(ctx, sqlResolveGift, giftID, chatID, status, now), &g)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ErrGiftNotFound
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// Монеты получает общий кошелёк чата, а вернуть их нужно в личный кошелёк отправителя.
+		toChat, toUser := g.ToChatID, int64(0)
+		if status == entity.GiftDeclined {
+			toChat, toUser = g.FromChatID, g.FromUserID
+		}
+
+		switch g.Kind {
+		case entity.GiftCoins:
+			_, err = tx.Exec(ctx, sqlAddCoins, toChat, toUser, g.Amount)
+		case entity.GiftItem:
+			var tag pgconn.CommandTag
+
+			tag, err = tx.Exec(ctx, sqlAddCosmetic, toChat, g.Item, g.Slot)
+			if err != nil || tag.RowsAffected() > 0 {
+				break
+			}
+
+			if status != entity.GiftDeclined {
+				return repo.ErrItemOwned
+			}
+
+			g.Refunded = true
+			_, err = tx.Exec(ctx, sqlAddCoins, toChat, toUser, g.Amount)
+		}
+
+		return err
+	})
+
+	return g, err
+}
+
+// GetGifts Подарки чату в заданном статусе, новые первыми.
+func (r *Repository) GetGifts(ctx context.Context, chatID int, status entity.GiftStatus) ([]entity.Gift, error) {
+	rows, err := r.db.Query(ctx, sqlGetGifts, chatID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	gifts := make([]entity.Gift, 0)
+	for rows.Next() {
+		var g entity.Gift
+
+		err = scanGift(rows, &g)
+		if err != nil {
+			return nil, err
+		}
+
+		gifts = append(gifts, g)
+	}
+
+	return gifts, rows.Err()
+}
+
+func scanGift(row pgx.Row, g *entity.Gift) error {
+	return row.Scan(&g.ID, &g.FromChatID, &g.FromUserID, &g.FromName, &g.ToChatID, &g.Kind, &g.Item, &g.Slot,
+		&g.Amount, &g.Title, &g.Status, &g.CreatedAt)
+}
+
+func (r *Repository) GetLastAlert(ctx context.Context, chatID int, alertType string) (time.Time, error) {
+	var lastAlert time.Time
+
+	err := r.db.QueryRow(ctx, sqlGetLastAlert, chatID, alertType).Scan(&lastAlert)
+
+	// Если в БД еще нет записи, возвращаем старую дату (чтобы сразу отправить уведомление)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	// Колонка без часового пояса: время в ней хранится в UTC.
+	return time.Date(lastAlert.Year(), lastAlert.Month(), lastAlert.Day(),
+		lastAlert.Hour(), lastAlert.Minute(), lastAlert.Second(), lastAlert.Nanosecond(), time.UTC), nil
+}
+
+func (r *Repository) UpdateLastAlert(ctx context.Context, chatID int, alertType string, now time.Time) error {
+	_, err := r.db.Exec(ctx, sqlUpdateLastAlert, chatID, alertType, now.UTC())
+
+	return err
+}
+
+// GetNotifyChannels Дополнительные каналы уведомлений чата.
+func (r *Repository) GetNotifyChannels(ctx context.Context, chatID int) ([]entity.NotifyChannel, error) {
+	rows, err := r.db.Query(ctx, sqlGetNotifyChannels, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	channels := make([]entity.NotifyChannel, 0)
+	for rows.Next() {
+		var ch entity.NotifyChannel
+
+		err = rows.Scan(&ch.Kind, &ch.Target, &ch.Secret, &ch.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, ch)
+	}
+
+	return channels, rows.Err()
+}
+
+// SaveNotifyChannel Подключает канал или заменяет настройки канала того же вида.
+func (r *Repository) SaveNotifyChannel(ctx context.Context, chatID int, ch entity.NotifyChannel) error {
+	_, err := r.db.Exec(ctx, sqlSaveNotifyChannel, chatID, ch.Kind, ch.Target, ch.Secret, ch.CreatedAt)
+
+	return err
+}
+
+// DeleteNotifyChannel Отключает канал; repo.ErrNoChannel, если его не было.
+func (r *Repository) DeleteNotifyChannel(ctx context.Context, chatID int, kind entity.ChannelKind) error {
+	tag, err := r.db.Exec(ctx, sqlDeleteNotifyChannel, chatID, kind)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repo.ErrNoChannel
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// testDBEnv Строка подключения к базе для тестов хранилища; без неё тесты пропускаются.
+const testDBEnv = "GOCHA_TEST_DB"
+
+var lastTestID atomic.Int64
+
+// testRepository Репозиторий над базой из GOCHA_TEST_DB. Тесты не чистят таблицы, а берут свои chat_id и
+// user_id из newTestID, поэтому одну базу можно использовать для многих запусков.
+func testRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	dsn := os.Getenv(testDBEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDBEnv)
+	}
+
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(pool.Close)
+
+	_, err = pool.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS pets")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := zerolog.Nop()
+
+	return NewRepository(&logger, pool)
+}
+
+// newTestID Идентификатор, которого нет в базе от прошлых запусков.
+func newTestID() int64 {
+	lastTestID.CompareAndSwap(0, time.Now().UnixMicro()*1000)
+
+	return lastTestID.Add(1)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// storedSample Строка pets.stat_samples: час или сырой замер и сколько замеров в ней усреднено.
+type storedSample struct {
+	at      time.Time
+	health  int
+	samples int
+}
+
+func TestRepository_CompactStatSamples(t *testing.T) {
+	t.Parallel()
+
+	r := testRepository(t)
+	ctx := context.Background()
+
+	pet := &entity.Pet{Name: "Гоча", Health: 100, Hunger: 100, Happiness: 100, Energy: 100, Hygiene: 100}
+	if err := r.NewPet(ctx, pet, int(newTestID())); err != nil {
+		t.Fatal(err)
+	}
+
+	hour := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	add := func(at time.Time, health int) {
+		t.Helper()
+
+		err := r.AddStatSamples(ctx, map[int][]entity.StatPoint{pet.ID: {{At: at, Health: health}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stored := func() []storedSample {
+		t.Helper()
+
+		rows, err := r.db.Query(ctx, "SELECT at, health, samples FROM pets.stat_samples WHERE pet_id = $1 ORDER BY at", pet.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer rows.Close()
+
+		var samples []storedSample
+
+		for rows.Next() {
+			var s storedSample
+
+			if err = rows.Scan(&s.at, &s.health, &s.samples); err != nil {
+				t.Fatal(err)
+			}
+
+			samples = append(samples, s)
+		}
+
+		if err = rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		return samples
+	}
+	compact := func(hourlyBefore, dropBefore time.Time, want []storedSample) {
+		t.Helper()
+
+		if err := r.CompactStatSamples(ctx, hourlyBefore, dropBefore); err != nil {
+			t.Fatal(err)
+		}
+
+		got := stored()
+		equal := slices.EqualFunc(got, want, func(a, b storedSample) bool {
+			return a.at.Equal(b.at) && a.health == b.health && a.samples == b.samples
+		})
+
+		if !equal {
+			t.Errorf("samples after compaction = %+v, want %+v", got, want)
+		}
+	}
+
+	add(hour.Add(-3*time.Hour+15*time.Minute), 10)
+	add(hour, 20)
+	add(hour.Add(10*time.Minute), 40)
+	add(hour.Add(20*time.Minute), 60)
+	add(hour.Add(90*time.Minute), 80)
+
+	// Старое удаляется, час сворачивается вместе с замером ровно в начале часа, свежее не трогается.
+	compact(hour.Add(time.Hour), hour.Add(-2*time.Hour), []storedSample{
+		{at: hour, health: 40, samples: 3},
+		{at: hour.Add(90 * time.Minute), health: 80, samples: 1},
+	})
+
+	// Повтор не меняет свёрнутый час.
+	compact(hour.Add(2*time.Hour), hour.Add(-2*time.Hour), []storedSample{
+		{at: hour, health: 40, samples: 3},
+		{at: hour.Add(time.Hour), health: 80, samples: 1},
+	})
+
+	// Опоздавший замер вливается в час с весом по числу замеров.
+	add(hour.Add(30*time.Minute), 100)
+	compact(hour.Add(2*time.Hour), hour.Add(-2*time.Hour), []storedSample{
+		{at: hour, health: 55, samples: 4},
+		{at: hour.Add(time.Hour), health: 80, samples: 1},
+	})
+}
@@ -0,0 +1,5 @@
+INSERT INTO pets.wallets (chat_id, user_id, coins)
+VALUES ($1, $2, $3)
+ON CONFLICT(chat_id, user_id)
+    DO UPDATE SET coins = pets.wallets.coins + EXCLUDED.coins
+RETURNING coins;
\ No newline at end of file
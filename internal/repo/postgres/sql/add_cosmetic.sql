@@ -0,0 +1,3 @@
+INSERT INTO pets.cosmetics (chat_id, item_id, slot)
+VALUES ($1, $2, $3)
+ON CONFLICT(chat_id, item_id) DO NOTHING;
\ No newline at end of file
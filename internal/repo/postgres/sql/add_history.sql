@@ -0,0 +1,2 @@
+INSERT INTO pets.history (chat_id, pet_id, user_id, user_name, action, params, before, after, success, message, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
\ No newline at end of file
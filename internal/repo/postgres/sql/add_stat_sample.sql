@@ -0,0 +1,3 @@
+INSERT INTO pets.stat_samples (pet_id, at, health, hunger, happiness, energy, hygiene)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT(pet_id, at) DO NOTHING;
\ No newline at end of file
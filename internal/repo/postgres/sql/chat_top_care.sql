@@ -0,0 +1,6 @@
+SELECT user_id, (array_agg(user_name ORDER BY id DESC))[1], count(*)::INT AS value
+FROM pets.history
+WHERE chat_id = $1 AND success AND created_at >= $2 AND action = ANY ($3)
+GROUP BY user_id
+ORDER BY value DESC
+LIMIT $4;
\ No newline at end of file
@@ -0,0 +1,10 @@
+SELECT s.user_id, coalesce(h.user_name, ''), s.best
+FROM pets.streaks s
+         LEFT JOIN LATERAL (SELECT user_name
+                            FROM pets.history
+                            WHERE chat_id = s.chat_id AND user_id = s.user_id
+                            ORDER BY id DESC
+                            LIMIT 1) h ON true
+WHERE s.chat_id = $1 AND s.best > 0
+ORDER BY s.best DESC
+LIMIT $2;
\ No newline at end of file
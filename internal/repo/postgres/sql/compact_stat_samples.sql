@@ -0,0 +1,24 @@
+WITH moved AS (
+    DELETE FROM pets.stat_samples
+    WHERE at < $1 AND at <> date_trunc('hour', at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+    RETURNING pet_id, date_trunc('hour', at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour,
+        health, hunger, happiness, energy, hygiene, samples
+)
+INSERT INTO pets.stat_samples AS s (pet_id, at, health, hunger, happiness, energy, hygiene, samples)
+SELECT pet_id,
+       hour,
+       round(sum(health * samples)::NUMERIC / sum(samples)),
+       round(sum(hunger * samples)::NUMERIC / sum(samples)),
+       round(sum(happiness * samples)::NUMERIC / sum(samples)),
+       round(sum(energy * samples)::NUMERIC / sum(samples)),
+       round(sum(hygiene * samples)::NUMERIC / sum(samples)),
+       sum(samples)
+FROM moved
+GROUP BY pet_id, hour
+ON CONFLICT(pet_id, at)
+    DO UPDATE SET health    = round((s.health * s.samples + EXCLUDED.health * EXCLUDED.samples)::NUMERIC / (s.samples + EXCLUDED.samples)),
+                  hunger    = round((s.hunger * s.samples + EXCLUDED.hunger * EXCLUDED.samples)::NUMERIC / (s.samples + EXCLUDED.samples)),
+                  happiness = round((s.happiness * s.samples + EXCLUDED.happiness * EXCLUDED.samples)::NUMERIC / (s.samples + EXCLUDED.samples)),
+                  energy    = round((s.energy * s.samples + EXCLUDED.energy * EXCLUDED.samples)::NUMERIC / (s.samples + EXCLUDED.samples)),
+                  hygiene   = round((s.hygiene * s.samples + EXCLUDED.hygiene * EXCLUDED.samples)::NUMERIC / (s.samples + EXCLUDED.samples)),
+                  samples   = s.samples + EXCLUDED.samples;
\ No newline at end of file
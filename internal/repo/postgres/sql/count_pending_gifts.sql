@@ -0,0 +1,3 @@
+SELECT COUNT(*)
+FROM pets.gifts
+WHERE to_chat_id = $1 AND status = 'pending';
\ No newline at end of file
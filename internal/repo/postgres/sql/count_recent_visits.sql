@@ -0,0 +1,3 @@
+SELECT COUNT(*)
+FROM pets.visits
+WHERE (host_chat_id IN ($1, $2) OR guest_chat_id IN ($1, $2)) AND visited_at > $3;
\ No newline at end of file
@@ -0,0 +1,4 @@
+DELETE
+FROM pets.notify_channels
+WHERE chat_id = $1
+  AND kind = $2
\ No newline at end of file
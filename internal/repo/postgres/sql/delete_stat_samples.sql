@@ -0,0 +1,2 @@
+DELETE FROM pets.stat_samples
+WHERE at < $1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE
+FROM pets.users
+WHERE chat_id = $1 AND user_id = $2;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE pets.cosmetics
+SET equipped = (item_id = $3)
+WHERE chat_id = $1 AND slot = $2;
\ No newline at end of file
@@ -0,0 +1,9 @@
+INSERT INTO pets.streaks (chat_id, user_id, current, best, freezes, last_day)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT(chat_id, user_id)
+    DO UPDATE SET
+                  current  = EXCLUDED.current,
+                  best     = EXCLUDED.best,
+                  freezes  = EXCLUDED.freezes,
+                  last_day = EXCLUDED.last_day
+    WHERE COALESCE(pets.streaks.last_day, '0001-01-01'::date) = $7;
\ No newline at end of file
@@ -0,0 +1,17 @@
+SELECT
+    day,
+    score,
+    observed_minutes,
+    warning_minutes,
+    critical_minutes,
+    neglect_minutes,
+    alerts,
+    responded_alerts,
+    response_seconds,
+    actions,
+    last_action_at,
+    pending_alert_at,
+    last_sample_at
+FROM pets.care_reports
+WHERE chat_id = $1 AND day BETWEEN $2 AND $3
+ORDER BY day;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT user_id, name, role
+FROM pets.users
+WHERE chat_id = $1
+ORDER BY role, name;
\ No newline at end of file
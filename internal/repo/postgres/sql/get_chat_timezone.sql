@@ -0,0 +1,3 @@
+SELECT timezone
+FROM pets.chat_settings
+WHERE chat_id = $1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT coins
+FROM pets.wallets
+WHERE chat_id = $1 AND user_id = $2;
\ No newline at end of file
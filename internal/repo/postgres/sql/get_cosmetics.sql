@@ -0,0 +1,3 @@
+SELECT item_id, equipped
+FROM pets.cosmetics
+WHERE chat_id = $1;
\ No newline at end of file
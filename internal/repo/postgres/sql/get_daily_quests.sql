@@ -0,0 +1,3 @@
+SELECT quests
+FROM pets.daily_quests
+WHERE chat_id = $1 AND day = $2;
\ No newline at end of file
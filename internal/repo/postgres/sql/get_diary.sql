@@ -0,0 +1,5 @@
+SELECT day, facts
+FROM pets.diary
+WHERE pet_id = $1 AND day <= $2
+ORDER BY day DESC
+LIMIT $3;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT COUNT(*), COALESCE(SUM(amount) FILTER (WHERE kind = 'coins'), 0)
+FROM pets.gifts
+WHERE from_user_id = $1 AND created_at >= $2;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT id, from_chat_id, from_user_id, from_name, to_chat_id, kind, item, slot, amount, title, status, created_at
+FROM pets.gifts
+WHERE to_chat_id = $1 AND status = $2
+ORDER BY created_at DESC;
\ No newline at end of file
@@ -0,0 +1,5 @@
+SELECT id, user_id, user_name, action, params, before, after, success, message, created_at
+FROM pets.history
+WHERE pet_id = $1 AND ($2::BIGINT = 0 OR id < $2)
+ORDER BY id DESC
+LIMIT $3;
\ No newline at end of file
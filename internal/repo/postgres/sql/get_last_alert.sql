@@ -0,0 +1,4 @@
+SELECT last_alert
+FROM pets.alerts
+WHERE chat_id = $1
+  AND alert_type = $2
\ No newline at end of file
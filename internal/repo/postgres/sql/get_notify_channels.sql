@@ -0,0 +1,4 @@
+SELECT kind, target, secret, created_at
+FROM pets.notify_channels
+WHERE chat_id = $1
+ORDER BY kind
\ No newline at end of file
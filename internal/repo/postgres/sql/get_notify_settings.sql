@@ -0,0 +1,4 @@
+SELECT notify
+FROM pets.chat_settings
+WHERE chat_id = $1
+  AND notify IS NOT NULL
\ No newline at end of file
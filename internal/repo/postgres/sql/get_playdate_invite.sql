@@ -0,0 +1,3 @@
+SELECT chat_id
+FROM pets.playdate_invites
+WHERE code = $1 AND expires_at > $2;
\ No newline at end of file
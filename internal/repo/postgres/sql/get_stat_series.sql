@@ -0,0 +1,10 @@
+SELECT to_timestamp(floor(extract(EPOCH FROM at) / $4::INT) * $4::INT) AS bucket,
+       round(sum(health * samples)::NUMERIC / sum(samples))::INT,
+       round(sum(hunger * samples)::NUMERIC / sum(samples))::INT,
+       round(sum(happiness * samples)::NUMERIC / sum(samples))::INT,
+       round(sum(energy * samples)::NUMERIC / sum(samples))::INT,
+       round(sum(hygiene * samples)::NUMERIC / sum(samples))::INT
+FROM pets.stat_samples
+WHERE pet_id = $1 AND at >= $2 AND at <= $3
+GROUP BY bucket
+ORDER BY bucket;
\ No newline at end of file
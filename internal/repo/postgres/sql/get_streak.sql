@@ -0,0 +1,3 @@
+SELECT current, best, freezes, COALESCE(last_day, '0001-01-01'::date)
+FROM pets.streaks
+WHERE chat_id = $1 AND user_id = $2;
\ No newline at end of file
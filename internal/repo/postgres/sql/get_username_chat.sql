@@ -0,0 +1,3 @@
+SELECT chat_id
+FROM pets.usernames
+WHERE username = lower($1);
\ No newline at end of file
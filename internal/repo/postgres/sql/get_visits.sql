@@ -0,0 +1,5 @@
+SELECT host_chat_id, guest_chat_id, host_pet, guest_pet, compatibility, bonus, visited_at
+FROM pets.visits
+WHERE host_chat_id = $1 OR guest_chat_id = $1
+ORDER BY visited_at DESC
+LIMIT $2;
\ No newline at end of file
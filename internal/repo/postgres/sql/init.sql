@@ -20,6 +20,18 @@ CREATE TABLE IF NOT EXISTS pets.pets
     is_active            BOOL      DEFAULT TRUE
 );
 
+ALTER TABLE pets.pets
+    ADD COLUMN IF NOT EXISTS skills JSONB NOT NULL DEFAULT '{}'::jsonb; -- Выученные трюки: {"sit": {"level": 20, "lastPracticed": "..."}}
+
+ALTER TABLE pets.pets
+    ADD COLUMN IF NOT EXISTS activity JSONB; -- Текущее занятие: {"kind": "walk", "startedAt": "...", "duration": ...}
+
+ALTER TABLE pets.pets
+    ADD COLUMN IF NOT EXISTS recent_care JSONB NOT NULL DEFAULT '{}'::jsonb; -- Недавние действия ухода: {"feed": ["...", "..."]}
+
+ALTER TABLE pets.pets
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 0; -- Растёт с каждым сохранением: снимок пишется, только если версия не изменилась
+
 -- Таблица пользователей
 CREATE TABLE IF NOT EXISTS pets.users
 (
@@ -30,6 +42,9 @@ CREATE TABLE IF NOT EXISTS pets.users
     UNIQUE (chat_id, user_id) -- Уникальный ключ для пары chat_id и user_id
 );
 
+ALTER TABLE pets.users
+    ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT ''; -- Имя участника на момент назначения роли
+
 -- Таблица оповещений
 CREATE TABLE IF NOT EXISTS pets.alerts
 (
@@ -37,4 +52,197 @@ CREATE TABLE IF NOT EXISTS pets.alerts
     alert_type TEXT   NOT NULL, -- 'health', 'hunger', 'happiness', 'energy', 'hygiene'
     last_alert TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (chat_id, alert_type)
+);
+
+-- Дневные сводки качества ухода
+CREATE TABLE IF NOT EXISTS pets.care_reports
+(
+    chat_id          BIGINT      NOT NULL,
+    day              DATE        NOT NULL,
+    score            INT         NOT NULL DEFAULT 100,
+    observed_minutes INT         NOT NULL DEFAULT 0,
+    warning_minutes  INT         NOT NULL DEFAULT 0,
+    critical_minutes INT         NOT NULL DEFAULT 0,
+    neglect_minutes  INT         NOT NULL DEFAULT 0,
+    alerts           INT         NOT NULL DEFAULT 0,
+    responded_alerts INT         NOT NULL DEFAULT 0,
+    response_seconds INT         NOT NULL DEFAULT 0,
+    actions          INT         NOT NULL DEFAULT 0,
+    last_action_at   TIMESTAMPTZ NOT NULL, -- Нулевое время, если действий ещё не было
+    pending_alert_at TIMESTAMPTZ NOT NULL, -- Предупреждение без реакции
+    last_sample_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (chat_id, day)
+);
+
+ALTER TABLE pets.care_reports
+    ADD COLUMN IF NOT EXISTS reported_at TIMESTAMPTZ; -- Когда итоги дня отправлены в чат, NULL — ещё нет
+
+-- Серии ухода: user_id = 0 — серия всего чата
+CREATE TABLE IF NOT EXISTS pets.streaks
+(
+    chat_id  BIGINT NOT NULL,
+    user_id  BIGINT NOT NULL,
+    current  INT    NOT NULL DEFAULT 0,
+    best     INT    NOT NULL DEFAULT 0,
+    freezes  INT    NOT NULL DEFAULT 0,
+    last_day DATE,
+    PRIMARY KEY (chat_id, user_id)
+);
+
+-- Монеты за награды: user_id = 0 — общий кошелёк чата
+CREATE TABLE IF NOT EXISTS pets.wallets
+(
+    chat_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    coins   INT    NOT NULL DEFAULT 0,
+    PRIMARY KEY (chat_id, user_id)
+);
+
+-- Настройки чата
+CREATE TABLE IF NOT EXISTS pets.chat_settings
+(
+    chat_id  BIGINT PRIMARY KEY,
+    timezone TEXT NOT NULL DEFAULT 'Europe/Moscow' -- Часовой пояс IANA для сброса дневных заданий
+);
+
+ALTER TABLE pets.chat_settings
+    ADD COLUMN IF NOT EXISTS notify JSONB; -- Настройки предупреждений: тихие часы, виды, пороги, сводка
+
+ALTER TABLE pets.chat_settings
+    ADD COLUMN IF NOT EXISTS hide_from_top BOOL NOT NULL DEFAULT false; -- Чат не показывается в общих рейтингах
+
+-- Ежедневные задания чата
+CREATE TABLE IF NOT EXISTS pets.daily_quests
+(
+    chat_id BIGINT NOT NULL,
+    day     DATE   NOT NULL, -- Дата по местному времени чата
+    quests  JSONB  NOT NULL,
+    PRIMARY KEY (chat_id, day)
+);
+
+-- Дневник питомца: факты дня, из которых собирается текст
+CREATE TABLE IF NOT EXISTS pets.diary
+(
+    pet_id INT   NOT NULL REFERENCES pets.pets (id),
+    day    DATE  NOT NULL, -- Дата по местному времени чата
+    facts  JSONB NOT NULL,
+    PRIMARY KEY (pet_id, day)
+);
+
+-- Гардероб чата: купленные аксессуары питомца
+CREATE TABLE IF NOT EXISTS pets.cosmetics
+(
+    chat_id  BIGINT  NOT NULL,
+    item_id  TEXT    NOT NULL,
+    slot     TEXT    NOT NULL,               -- Место на аватаре: background, collar, hat
+    equipped BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (chat_id, item_id)
+);
+
+-- Объявленные события календаря: каждое проведение объявляется в чат один раз
+CREATE TABLE IF NOT EXISTS pets.event_announcements
+(
+    chat_id    BIGINT NOT NULL,
+    event_id   TEXT   NOT NULL,
+    started_on DATE   NOT NULL, -- Первый день проведения по местному времени чата
+    PRIMARY KEY (chat_id, event_id, started_on)
+);
+
+-- Приглашения в гости: код действует до expires_at и сгорает при использовании
+CREATE TABLE IF NOT EXISTS pets.playdate_invites
+(
+    code       TEXT PRIMARY KEY,
+    chat_id    BIGINT      NOT NULL, -- Чат-хозяин
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Встречи питомцев разных чатов: видны обоим владельцам
+CREATE TABLE IF NOT EXISTS pets.visits
+(
+    id            SERIAL PRIMARY KEY,
+    host_chat_id  BIGINT      NOT NULL,
+    guest_chat_id BIGINT      NOT NULL,
+    host_pet      TEXT        NOT NULL,
+    guest_pet     TEXT        NOT NULL,
+    compatibility INT         NOT NULL, -- Совместимость характеров в процентах
+    bonus         INT         NOT NULL, -- Прибавка счастья каждому питомцу
+    visited_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS visits_host_idx ON pets.visits (host_chat_id, visited_at);
+CREATE INDEX IF NOT EXISTS visits_guest_idx ON pets.visits (guest_chat_id, visited_at);
+
+-- Последний чат, где видели пользователя: по @username находят получателя подарка
+CREATE TABLE IF NOT EXISTS pets.usernames
+(
+    username   TEXT PRIMARY KEY, -- В нижнем регистре, без @
+    user_id    BIGINT      NOT NULL,
+    chat_id    BIGINT      NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Подарки между чатами: пока подарок ждёт ответа, монеты или предмет хранятся в нём
+CREATE TABLE IF NOT EXISTS pets.gifts
+(
+    id           BIGSERIAL PRIMARY KEY,
+    from_chat_id BIGINT      NOT NULL,
+    from_user_id BIGINT      NOT NULL,
+    from_name    TEXT        NOT NULL,
+    to_chat_id   BIGINT      NOT NULL,
+    kind         TEXT        NOT NULL,            -- coins или item
+    item         TEXT        NOT NULL DEFAULT '',
+    slot         TEXT        NOT NULL DEFAULT '',
+    amount       INT         NOT NULL DEFAULT 0,
+    title        TEXT        NOT NULL,
+    status       TEXT        NOT NULL,            -- pending, accepted, declined
+    created_at   TIMESTAMPTZ NOT NULL,
+    resolved_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS gifts_to_idx ON pets.gifts (to_chat_id, status);
+CREATE INDEX IF NOT EXISTS gifts_from_idx ON pets.gifts (from_user_id, created_at);
+
+-- Дополнительные каналы уведомлений чата: вебхук и почта
+CREATE TABLE IF NOT EXISTS pets.notify_channels
+(
+    chat_id    BIGINT      NOT NULL,
+    kind       TEXT        NOT NULL,            -- webhook или email
+    target     TEXT        NOT NULL,            -- URL или адрес
+    secret     TEXT        NOT NULL DEFAULT '', -- Ключ подписи вебхука
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (chat_id, kind)
+);
+
+-- Журнал действий с питомцами: записи только добавляются
+CREATE TABLE IF NOT EXISTS pets.history
+(
+    id         BIGSERIAL PRIMARY KEY,
+    chat_id    BIGINT      NOT NULL,
+    pet_id     INT         NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    user_name  TEXT        NOT NULL DEFAULT '',
+    action     TEXT        NOT NULL,
+    params     JSONB,
+    before     JSONB       NOT NULL,            -- Статы до действия
+    after      JSONB       NOT NULL,            -- Статы после действия
+    success    BOOLEAN     NOT NULL,
+    message    TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS history_pet_idx ON pets.history (pet_id, id DESC);
+CREATE INDEX IF NOT EXISTS history_user_idx ON pets.history (chat_id, user_id, id DESC);
+
+-- Замеры статов питомцев: свежие — как есть, старые свёрнуты в часовые средние
+CREATE TABLE IF NOT EXISTS pets.stat_samples
+(
+    pet_id    INT         NOT NULL,
+    at        TIMESTAMPTZ NOT NULL,
+    health    SMALLINT    NOT NULL,
+    hunger    SMALLINT    NOT NULL,
+    happiness SMALLINT    NOT NULL,
+    energy    SMALLINT    NOT NULL,
+    hygiene   SMALLINT    NOT NULL,
+    samples   INT         NOT NULL DEFAULT 1, -- Сколько замеров усреднено в строке
+    PRIMARY KEY (pet_id, at)
 );
\ No newline at end of file
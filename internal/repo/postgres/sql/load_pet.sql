@@ -12,6 +12,11 @@ SELECT
     hygiene_decay_rate,
     happiness_decay_rate,
     last_updated,
-    created_at
+    created_at,
+    skills,
+    activity,
+    recent_care,
+    version,
+    id
 FROM pets.pets
 WHERE chat_id = $1 and is_active = true;
\ No newline at end of file
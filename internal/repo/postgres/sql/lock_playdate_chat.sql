@@ -0,0 +1 @@
+SELECT pg_advisory_xact_lock(hashtextextended('playdate:' || $1::TEXT, 0));
\ No newline at end of file
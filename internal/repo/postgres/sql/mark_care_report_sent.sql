@@ -0,0 +1,3 @@
+UPDATE pets.care_reports
+SET reported_at = $3
+WHERE chat_id = $1 AND day = $2 AND reported_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO pets.event_announcements (chat_id, event_id, started_on)
+VALUES ($1, $2, $3)
+ON CONFLICT(chat_id, event_id, started_on) DO NOTHING;
\ No newline at end of file
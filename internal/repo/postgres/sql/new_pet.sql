@@ -7,4 +7,5 @@ INSERT INTO pets.pets (
     energy,
     hygiene,
     last_updated
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
\ No newline at end of file
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+UPDATE pets.gifts
+SET status      = $3,
+    resolved_at = $4
+WHERE id = $1 AND to_chat_id = $2 AND status = 'pending'
+RETURNING id, from_chat_id, from_user_id, from_name, to_chat_id, kind, item, slot, amount, title, status, created_at;
\ No newline at end of file
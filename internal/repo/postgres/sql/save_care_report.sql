@@ -0,0 +1,32 @@
+INSERT INTO pets.care_reports (
+    chat_id,
+    day,
+    score,
+    observed_minutes,
+    warning_minutes,
+    critical_minutes,
+    neglect_minutes,
+    alerts,
+    responded_alerts,
+    response_seconds,
+    actions,
+    last_action_at,
+    pending_alert_at,
+    last_sample_at
+) VALUES (
+             $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+         )
+ON CONFLICT(chat_id, day)
+    DO UPDATE SET
+                  score            = EXCLUDED.score,
+                  observed_minutes = EXCLUDED.observed_minutes,
+                  warning_minutes  = EXCLUDED.warning_minutes,
+                  critical_minutes = EXCLUDED.critical_minutes,
+                  neglect_minutes  = EXCLUDED.neglect_minutes,
+                  alerts           = EXCLUDED.alerts,
+                  responded_alerts = EXCLUDED.responded_alerts,
+                  response_seconds = EXCLUDED.response_seconds,
+                  actions          = EXCLUDED.actions,
+                  last_action_at   = EXCLUDED.last_action_at,
+                  pending_alert_at = EXCLUDED.pending_alert_at,
+                  last_sample_at   = EXCLUDED.last_sample_at;
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT INTO pets.daily_quests (chat_id, day, quests)
+VALUES ($1, $2, $3)
+ON CONFLICT(chat_id, day)
+    DO UPDATE SET quests = EXCLUDED.quests;
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT INTO pets.diary (pet_id, day, facts)
+VALUES ($1, $2, $3)
+ON CONFLICT(pet_id, day)
+    DO UPDATE SET facts = EXCLUDED.facts;
\ No newline at end of file
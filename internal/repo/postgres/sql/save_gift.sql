@@ -0,0 +1,3 @@
+INSERT INTO pets.gifts (from_chat_id, from_user_id, from_name, to_chat_id, kind, item, slot, amount, title, status, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending', $10)
+RETURNING id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+INSERT INTO pets.notify_channels (chat_id, kind, target, secret, created_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (chat_id, kind) DO UPDATE SET target     = EXCLUDED.target,
+                                          secret     = EXCLUDED.secret,
+                                          created_at = EXCLUDED.created_at
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO pets.chat_settings (chat_id, notify)
+VALUES ($1, $2)
+ON CONFLICT (chat_id) DO UPDATE SET notify = EXCLUDED.notify
\ No newline at end of file
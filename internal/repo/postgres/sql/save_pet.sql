@@ -1,33 +1,22 @@
-INSERT INTO pets.pets (
-    chat_id,
-    name,
-    health,
-    hunger,
-    happiness,
-    energy,
-    hygiene,
-    state,
-    sleep_start_time,
-    hunger_decay_rate,
-    energy_decay_rate,
-    hygiene_decay_rate,
-    happiness_decay_rate,
-    last_updated
-) VALUES (
-             $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
-         )
-ON CONFLICT(chat_id)
-    DO UPDATE SET
-                  name                 = EXCLUDED.name,
-                  health               = EXCLUDED.health,
-                  hunger               = EXCLUDED.hunger,
-                  happiness            = EXCLUDED.happiness,
-                  energy               = EXCLUDED.energy,
-                  hygiene              = EXCLUDED.hygiene,
-                  state                = EXCLUDED.state,
-                  sleep_start_time     = EXCLUDED.sleep_start_time,
-                  hunger_decay_rate    = EXCLUDED.hunger_decay_rate,
-                  energy_decay_rate    = EXCLUDED.energy_decay_rate,
-                  hygiene_decay_rate   = EXCLUDED.hygiene_decay_rate,
-                  happiness_decay_rate = EXCLUDED.happiness_decay_rate,
-                  last_updated         = EXCLUDED.last_updated;
\ No newline at end of file
+UPDATE pets.pets
+SET name                 = $3,
+    health               = $4,
+    hunger               = $5,
+    happiness            = $6,
+    energy               = $7,
+    hygiene              = $8,
+    state                = $9,
+    sleep_start_time     = $10,
+    hunger_decay_rate    = $11,
+    energy_decay_rate    = $12,
+    hygiene_decay_rate   = $13,
+    happiness_decay_rate = $14,
+    last_updated         = $15,
+    skills               = $16,
+    activity             = $17,
+    recent_care          = $18,
+    version              = version + 1
+WHERE id = $1
+  AND chat_id = $2
+  AND version = $19
+RETURNING version
\ No newline at end of file
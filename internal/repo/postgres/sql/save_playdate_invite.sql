@@ -0,0 +1,2 @@
+INSERT INTO pets.playdate_invites (code, chat_id, expires_at)
+VALUES ($1, $2, $3);
\ No newline at end of file
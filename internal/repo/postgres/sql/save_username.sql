@@ -0,0 +1,6 @@
+INSERT INTO pets.usernames (username, user_id, chat_id, updated_at)
+VALUES (lower($1), $2, $3, $4)
+ON CONFLICT(username) DO UPDATE
+    SET user_id    = EXCLUDED.user_id,
+        chat_id    = EXCLUDED.chat_id,
+        updated_at = EXCLUDED.updated_at;
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO pets.visits (host_chat_id, guest_chat_id, host_pet, guest_pet, compatibility, bonus, visited_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT INTO pets.chat_settings (chat_id, timezone)
+VALUES ($1, $2)
+ON CONFLICT(chat_id)
+    DO UPDATE SET timezone = EXCLUDED.timezone;
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO pets.chat_settings (chat_id, hide_from_top)
+VALUES ($1, $2)
+ON CONFLICT (chat_id) DO UPDATE SET hide_from_top = EXCLUDED.hide_from_top
\ No newline at end of file
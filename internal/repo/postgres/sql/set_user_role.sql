@@ -0,0 +1,5 @@
+INSERT INTO pets.users (chat_id, user_id, role, name)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chat_id, user_id)
+    DO UPDATE SET role = EXCLUDED.role,
+                  name = EXCLUDED.name;
\ No newline at end of file
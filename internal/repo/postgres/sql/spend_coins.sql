@@ -0,0 +1,4 @@
+UPDATE pets.wallets
+SET coins = coins - $3
+WHERE chat_id = $1 AND user_id = $2 AND coins >= $3
+RETURNING coins;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DELETE FROM pets.cosmetics
+WHERE chat_id = $1 AND item_id = $2 AND NOT equipped;
\ No newline at end of file
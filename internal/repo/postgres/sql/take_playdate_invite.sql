@@ -0,0 +1,2 @@
+DELETE FROM pets.playdate_invites
+WHERE code = $1 AND chat_id = $2 AND expires_at > $3;
\ No newline at end of file
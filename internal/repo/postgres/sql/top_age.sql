@@ -0,0 +1,6 @@
+SELECT p.chat_id, p.name, extract(DAY FROM LOCALTIMESTAMP - p.created_at)::INT AS value
+FROM pets.pets p
+         LEFT JOIN pets.chat_settings cs ON cs.chat_id = p.chat_id
+WHERE p.is_active AND p.state <> 'dead' AND NOT coalesce(cs.hide_from_top, false)
+ORDER BY p.created_at
+LIMIT $1;
\ No newline at end of file
@@ -0,0 +1,8 @@
+SELECT p.chat_id, p.name, round(avg(r.score))::INT AS value
+FROM pets.care_reports r
+         JOIN pets.pets p ON p.chat_id = r.chat_id AND p.is_active AND p.state <> 'dead'
+         LEFT JOIN pets.chat_settings cs ON cs.chat_id = r.chat_id
+WHERE r.day >= $2 AND r.observed_minutes > 0 AND NOT coalesce(cs.hide_from_top, false)
+GROUP BY p.chat_id, p.name
+ORDER BY value DESC
+LIMIT $1;
\ No newline at end of file
@@ -0,0 +1,9 @@
+SELECT p.chat_id,
+       p.name,
+       1 + extract(DAY FROM LOCALTIMESTAMP - p.created_at)::INT / 7
+           + coalesce((SELECT sum((s.value ->> 'level')::INT) FROM jsonb_each(p.skills) s), 0)::INT / 100 AS value
+FROM pets.pets p
+         LEFT JOIN pets.chat_settings cs ON cs.chat_id = p.chat_id
+WHERE p.is_active AND p.state <> 'dead' AND NOT coalesce(cs.hide_from_top, false)
+ORDER BY value DESC, p.created_at
+LIMIT $1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE pets.care_reports
+SET reported_at = NULL
+WHERE chat_id = $1 AND day = $2;
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO pets.alerts (chat_id, alert_type, last_alert)
+VALUES ($1, $2, $3)
+ON CONFLICT (chat_id, alert_type) DO UPDATE SET last_alert = excluded.last_alert
\ No newline at end of file
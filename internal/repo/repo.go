@@ -12,10 +12,88 @@ type Repository interface {
 	NewPet(ctx context.Context, p *entity.Pet, chatID int) error
 	SavePet(ctx context.Context, p *entity.Pet, chatID int) error
 	LoadPet(ctx context.Context, chatID int) (*entity.Pet, error)
+	LoadPets(ctx context.Context, chatIDs []int) (map[int]*entity.Pet, error)
+	SavePets(ctx context.Context, pets map[int]*entity.Pet) ([]int, error)
 	GetChats(ctx context.Context) ([]int, error)
 
+	GetCareReports(ctx context.Context, chatID int, from, to time.Time) ([]entity.CareReport, error)
+	SaveCareReport(ctx context.Context, chatID int, c entity.CareReport) error
+	MarkCareReportSent(ctx context.Context, chatID int, day time.Time, now time.Time) (bool, error)
+	UnmarkCareReportSent(ctx context.Context, chatID int, day time.Time) error
+
+	GetStreak(ctx context.Context, chatID int, userID int64) (entity.Streak, error)
+	ExtendStreak(ctx context.Context, chatID int, userID int64, prevDay time.Time, st entity.Streak) (bool, error)
+	AddCoins(ctx context.Context, chatID int, userID int64, amount int) (int, error)
+	GetCoins(ctx context.Context, chatID int, userID int64) (int, error)
+	SpendCoins(ctx context.Context, chatID int, userID int64, amount int) (int, error)
+
+	GetDailyQuests(ctx context.Context, chatID int, day time.Time) ([]entity.Quest, error)
+	SaveDailyQuests(ctx context.Context, chatID int, day time.Time, quests []entity.Quest) error
+
+	GetChatTimezone(ctx context.Context, chatID int) (string, error)
+	SetChatTimezone(ctx context.Context, chatID int, timezone string) error
+	GetNotifySettings(ctx context.Context, chatID int) (*entity.NotifySettings, error)
+	SaveNotifySettings(ctx context.Context, chatID int, settings entity.NotifySettings) error
+
+	GetDiary(ctx context.Context, petID int, to time.Time, limit int) ([]entity.DiaryEntry, error)
+	SaveDiaryEntry(ctx context.Context, petID int, e entity.DiaryEntry) error
+
+	AddHistory(ctx context.Context, chatID, petID int, e entity.HistoryEntry) error
+	GetHistory(ctx context.Context, petID int, before int64, limit int) ([]entity.HistoryEntry, error)
+
+	AddStatSamples(ctx context.Context, samples map[int][]entity.StatPoint) error
+	CompactStatSamples(ctx context.Context, hourlyBefore, dropBefore time.Time) error
+	GetStatSeries(ctx context.Context, petID int, from, to time.Time, step time.Duration) ([]entity.StatPoint, error)
+
+	GetLeaderboard(ctx context.Context, category entity.LeaderboardCategory, since time.Time, limit int) ([]entity.LeaderboardEntry, error)
+	GetChatLeaderboard(ctx context.Context, chatID int, category entity.LeaderboardCategory, careActions []string, since time.Time, limit int) ([]entity.LeaderboardEntry, error)
+	GetTopHidden(ctx context.Context, chatID int) (bool, error)
+	SetTopHidden(ctx context.Context, chatID int, hidden bool) error
+
+	GetUserRole(ctx context.Context, chatID int, userID int64) (entity.Role, error)
+	GetChatRoles(ctx context.Context, chatID int) ([]entity.ChatRole, error)
+	SetUserRole(ctx context.Context, chatID int, cr entity.ChatRole) error
+	DeleteUserRole(ctx context.Context, chatID int, userID int64) error
+
+	GetCosmetics(ctx context.Context, chatID int) (map[string]bool, error)
+	AddCosmetic(ctx context.Context, chatID int, itemID string, slot entity.CosmeticSlot) error
+	EquipCosmetic(ctx context.Context, chatID int, slot entity.CosmeticSlot, itemID string) error
+
+	MarkEventAnnounced(ctx context.Context, chatID int, eventID string, startedAt time.Time) (bool, error)
+
+	SavePlaydateInvite(ctx context.Context, chatID int, invite entity.PlaydateInvite) error
+	GetPlaydateInvite(ctx context.Context, code string, now time.Time) (int, error)
+	SavePlaydate(ctx context.Context, code string, v entity.Visit, cooldown time.Duration) error
+	GetVisits(ctx context.Context, chatID int, limit int) ([]entity.Visit, error)
+
+	SaveUsername(ctx context.Context, username string, userID int64, chatID int, now time.Time) error
+	GetUsernameChat(ctx context.Context, username string) (int, error)
+
+	SendGift(ctx context.Context, g entity.Gift, limits entity.GiftLimits) (int64, error)
+	ResolveGift(ctx context.Context, giftID int64, chatID int, status entity.GiftStatus, now time.Time) (entity.Gift, error)
+	GetGifts(ctx context.Context, chatID int, status entity.GiftStatus) ([]entity.Gift, error)
+
 	GetLastAlert(ctx context.Context, chatID int, alertType string) (time.Time, error)
 	UpdateLastAlert(ctx context.Context, chatID int, alertType string, now time.Time) error
+
+	GetNotifyChannels(ctx context.Context, chatID int) ([]entity.NotifyChannel, error)
+	SaveNotifyChannel(ctx context.Context, chatID int, ch entity.NotifyChannel) error
+	DeleteNotifyChannel(ctx context.Context, chatID int, kind entity.ChannelKind) error
 }
 
-var ErrPetNotFound = errors.New("питомец не найден")
+var (
+	ErrPetNotFound        = errors.New("питомец не найден")
+	ErrQuestsNotFound     = errors.New("задания не найдены")
+	ErrNotEnoughCoins     = errors.New("недостаточно монет")
+	ErrInviteNotFound     = errors.New("приглашение не найдено")
+	ErrPlaydateTooSoon    = errors.New("питомец недавно был на встрече")
+	ErrUserNotFound       = errors.New("пользователь не найден")
+	ErrGiftNotFound       = errors.New("подарок не найден")
+	ErrGiftLimit          = errors.New("превышен лимит подарков")
+	ErrItemNotOwned       = errors.New("предмета нет или он надет")
+	ErrItemOwned          = errors.New("предмет уже есть")
+	ErrNoChannel          = errors.New("канал уведомлений не настроен")
+	ErrVersionConflict    = errors.New("питомца успели изменить")
+	ErrUnknownLeaderboard = errors.New("неизвестный рейтинг")
+	ErrNoRole             = errors.New("роль не назначена")
+)
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"slices"
+
+	"gocha/internal/entity"
+	"gocha/pkg/gocha"
+)
+
+// ActionParams Параметры действия: тело запроса Mini App или аргументы команды бота.
+type ActionParams map[string]string
+
+// Action Описание действия с питомцем. Всё, что нужно знать о действии, задаётся здесь один раз:
+// из реестра строятся методы сервиса, HTTP-маршруты, карта доступных действий и команды бота.
+type Action struct {
+	Name        string // Имя в API (/api/pet/<name>/) и команда бота (/<name>).
+	Title       string // Подпись кнопки.
+	Emoji       string
+	Description string // Описание для меню команд бота.
+	Feedback    string // Глагол для сообщения об успехе: «покормили».
+
+	Param      string   // Имя обязательного параметра, если он нужен.
+	Choices    []string // Допустимые значения параметра.
+	ParamError error    // Ошибка при недопустимом значении параметра.
+
+	Care gocha.CareAction // Действие ухода с убывающей отдачей, если применимо.
+
+	Precondition func(p *entity.Pet) (bool, string)
+	Effect       func(p *gocha.Pet, params ActionParams) gocha.Result
+}
+
+var actions = []Action{
+	{
+		Name:        "feed",
+		Title:       "Покормить",
+		Emoji:       "🍎",
+		Description: "Покормить питомца",
+		Feedback:    "покормили",
+		Care:        gocha.CareFeed,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+			if p.Hunger >= 100 {
+				return false, "Питомец не голоден"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, _ ActionParams) gocha.Result {
+			return p.Feed()
+		},
+	},
+	{
+		Name:        "play",
+		Title:       "Играть",
+		Emoji:       "🎮",
+		Description: "Поиграть с питомцем",
+		Feedback:    "поиграли",
+		Care:        gocha.CarePlay,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+			if p.Energy <= 20 {
+				return false, "Питомец слишком устал"
+			}
+			if p.Happiness >= 100 {
+				return false, "Питомец уже счастлив"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, _ ActionParams) gocha.Result {
+			return p.Play()
+		},
+	},
+	{
+		Name:        "clean",
+		Title:       "Помыть",
+		Emoji:       "🛁",
+		Description: "Помыть питомца",
+		Feedback:    "помыли",
+		Care:        gocha.CareClean,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+			if p.Hygiene >= 100 {
+				return false, "Питомец уже чистый"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, _ ActionParams) gocha.Result {
+			return p.Clean()
+		},
+	},
+	{
+		Name:        "heal",
+		Title:       "Лечить",
+		Emoji:       "💊",
+		Description: "Полечить питомца",
+		Feedback:    "вылечили",
+		Care:        gocha.CareHeal,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+			if p.Health >= 100 {
+				return false, "Питомец здоров"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, _ ActionParams) gocha.Result {
+			return p.Heal()
+		},
+	},
+	{
+		Name:        "sleep",
+		Title:       "Спать",
+		Emoji:       "😴",
+		Description: "Уложить питомца спать",
+		Feedback:    "уложили спать",
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец уже спит"
+			}
+			if p.Energy > 30 {
+				return false, "Питомец не устал"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, _ ActionParams) gocha.Result {
+			return p.Sleep()
+		},
+	},
+	{
+		Name:        "wakeup",
+		Title:       "Разбудить",
+		Emoji:       "☀️",
+		Description: "Разбудить питомца",
+		Feedback:    "разбудили",
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State != entity.PetSleeping {
+				return false, "Питомец не спит"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, _ ActionParams) gocha.Result {
+			return p.WakeUp()
+		},
+	},
+	{
+		Name:        "train",
+		Title:       "Учить",
+		Emoji:       "📚",
+		Description: "Научить питомца трюку: /train sit",
+		Feedback:    "потренировали",
+		Param:       "trick",
+		Choices:     trickNames(),
+		ParamError:  ErrUnknownTrick,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+			if p.Energy < gocha.TrainEnergyCost {
+				return false, "Питомец слишком устал"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, params ActionParams) gocha.Result {
+			return p.Train(gocha.Trick(params["trick"]))
+		},
+	},
+	{
+		Name:        "trick",
+		Title:       "Показать",
+		Emoji:       "✨",
+		Description: "Попросить питомца показать трюк: /trick sit",
+		Feedback:    "показали трюк",
+		Param:       "trick",
+		Choices:     trickNames(),
+		ParamError:  ErrUnknownTrick,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, params ActionParams) gocha.Result {
+			return p.PerformTrick(gocha.Trick(params["trick"]))
+		},
+	},
+	{
+		Name:        "activity",
+		Title:       "Занятие",
+		Emoji:       "🗓",
+		Description: "Отправить питомца по делам: /activity walk",
+		Feedback:    "отправили по делам",
+		Param:       "kind",
+		Choices:     []string{string(gocha.ActivityWalk), string(gocha.ActivityVet), string(gocha.ActivitySchool)},
+		ParamError:  ErrUnknownActivity,
+		Precondition: func(p *entity.Pet) (bool, string) {
+			if p.State == entity.PetSleeping {
+				return false, "Питомец спит"
+			}
+
+			return true, ""
+		},
+		Effect: func(p *gocha.Pet, params ActionParams) gocha.Result {
+			return p.StartActivity(gocha.ActivityKind(params["kind"]))
+		},
+	},
+}
+
+// Actions Все зарегистрированные действия.
+func Actions() []Action {
+	return actions
+}
+
+func LookupAction(name string) (Action, bool) {
+	for _, action := range actions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+
+	return Action{}, false
+}
+
+// CanPerform Проверяет, можно ли выполнить действие с питомцем прямо сейчас.
+func (a Action) CanPerform(p *entity.Pet) (bool, string) {
+	if p.State == entity.PetDead {
+		return false, "Питомец мертв"
+	}
+
+	if p.IsBusy() {
+		return false, "Питомец занят: " + p.Activity.Title
+	}
+
+	return a.Precondition(p)
+}
+
+// canPerformAs Проверяет действие для участника: не только питомца, но и не закреплено ли действие за чужой ролью.
+func (a Action) canPerformAs(p *entity.Pet, access roleAccess) (bool, string) {
+	if available, reason := a.CanPerform(p); !available {
+		return false, reason
+	}
+
+	return access.allows(a)
+}
+
+func (a Action) validate(params ActionParams) error {
+	if a.Param == "" || slices.Contains(a.Choices, params[a.Param]) {
+		return nil
+	}
+
+	return a.ParamError
+}
+
+// describeActions Заполняет карту доступных действий и их описание для UI глазами участника с ролями access.
+func describeActions(p *entity.Pet, access roleAccess) {
+	p.AvailableActions = make(map[string]bool, len(actions))
+	p.Actions = make([]entity.ActionInfo, 0, len(actions))
+
+	extPet := PetEntityToGocha(p)
+
+	for _, action := range actions {
+		available, reason := action.canPerformAs(p, access)
+
+		efficacy := 0
+		if action.Care != "" {
+			efficacy = extPet.Efficacy(action.Care) * (100 + access.boost(action)) / 100
+		}
+
+		p.AvailableActions[action.Name] = available
+		p.Actions = append(p.Actions, entity.ActionInfo{
+			Name:      action.Name,
+			Title:     action.Title,
+			Emoji:     action.Emoji,
+			Available: available,
+			Reason:    reason,
+			Efficacy:  efficacy,
+			Param:     action.Param,
+		})
+	}
+}
+
+func trickNames() []string {
+	names := make([]string, 0, len(gocha.Tricks))
+	for _, trick := range gocha.Tricks {
+		names = append(names, string(trick))
+	}
+
+	return names
+}
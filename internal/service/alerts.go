@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+const (
+	defaultAlertThreshold = 20
+	digestAlertType       = "digest" // Тип сводки в каналах уведомлений.
+)
+
+// alertRule Условие предупреждения о состоянии питомца и действия, которые его исправляют.
+type alertRule struct {
+	Type    string
+	Title   string
+	Stat    func(p *entity.Pet) int
+	Message string
+	Actions []string
+}
+
+var alertRules = []alertRule{
+	{Type: "health", Title: "Здоровье", Stat: func(p *entity.Pet) int { return p.Health }, Message: "⚠️ Внимание! Здоровье питомца на критическом уровне!", Actions: []string{"heal"}},
+	{Type: "hunger", Title: "Сытость", Stat: func(p *entity.Pet) int { return p.Hunger }, Message: "⚠️ Внимание! Питомец очень голоден!", Actions: []string{"feed"}},
+	{Type: "happiness", Title: "Счастье", Stat: func(p *entity.Pet) int { return p.Happiness }, Message: "⚠️ Внимание! Питомец очень несчастен!", Actions: []string{"play"}},
+	{Type: "energy", Title: "Энергия", Stat: func(p *entity.Pet) int { return p.Energy }, Message: "⚠️ Внимание! У питомца очень мало энергии!", Actions: []string{"sleep"}},
+	{Type: "hygiene", Title: "Чистота", Stat: func(p *entity.Pet) int { return p.Hygiene }, Message: "⚠️ Внимание! Питомец очень грязный!", Actions: []string{"clean"}},
+}
+
+func lookupAlertRule(alertType string) (alertRule, bool) {
+	for _, rule := range alertRules {
+		if rule.Type == alertType {
+			return rule, true
+		}
+	}
+
+	return alertRule{}, false
+}
+
+// triggeredAlerts Включённые в настройках чата правила, чей порог питомец уже пересёк.
+func triggeredAlerts(settings entity.NotifySettings, pet *entity.Pet) []alertRule {
+	var triggered []alertRule
+
+	for _, pref := range settings.Alerts {
+		rule, ok := lookupAlertRule(pref.Type)
+		if ok && pref.Enabled && rule.Stat(pet) <= pref.Threshold {
+			triggered = append(triggered, rule)
+		}
+	}
+
+	return triggered
+}
+
+// sendWarnings Отправляет предупреждения по сработавшим правилам, если у чата не тихие часы: каждое
+// отдельно или, в режиме сводки, копит их и отправляет одной сводкой в конце окна DigestWindow.
+func (s *Service) sendWarnings(ctx context.Context, chatID int, pet *entity.Pet, settings entity.NotifySettings,
+	triggered []alertRule, now time.Time,
+) {
+	digest := settings.Mode == entity.AlertDigest
+	if digest {
+		s.digests.add(chatID, triggered, pet, now)
+	}
+
+	if len(triggered) == 0 && !digest {
+		return
+	}
+
+	if quietHours(settings, now.In(s.chatLocation(ctx, chatID))) {
+		s.logger.Trace().Msgf("quiet hours for chat_id: %d, alerts postponed", chatID)
+
+		return
+	}
+
+	if digest {
+		s.sendDigestIfDue(ctx, chatID, pet, now)
+
+		return
+	}
+
+	for _, rule := range triggered {
+		s.sendWarningIfNeeded(ctx, chatID, newAlert(rule, pet), now)
+	}
+}
+
+// sendWarningIfNeeded Отправляет предупреждение, если с прошлого предупреждения того же типа прошло
+// AlertCooldown минут.
+func (s *Service) sendWarningIfNeeded(ctx context.Context, chatID int, alert entity.Alert, now time.Time) {
+	// Запрашиваем время последнего предупреждения из БД
+	lastAlert, err := s.repo.GetLastAlert(ctx, chatID, alert.Type)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Ошибка получения последнего предупреждения")
+
+		return
+	}
+
+	// Проверяем, прошло ли достаточно времени с момента последнего предупреждения
+	if now.Sub(lastAlert) < time.Duration(s.cfg.AlertCooldown)*time.Minute {
+		return
+	}
+
+	if !s.sendAlert(ctx, chatID, alert) {
+		return
+	}
+
+	err = s.repo.UpdateLastAlert(ctx, chatID, alert.Type, now) // Обновляем в БД
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't update last alert")
+	}
+}
+
+// sendAlert Отправляет предупреждение во все каналы чата: с кнопками, если канал их умеет, иначе — простым
+// сообщением. Возвращает, дошло ли оно хоть куда-то: отказ одного канала не должен сбивать остальным перерыв.
+func (s *Service) sendAlert(ctx context.Context, chatID int, alert entity.Alert) bool {
+	delivered := false
+
+	for _, notifier := range s.chatNotifiers(ctx, chatID) {
+		var err error
+
+		if n, ok := notifier.(AlertNotifier); ok {
+			err = n.Alert(ctx, chatID, alert)
+		} else {
+			err = notifier.Notify(ctx, chatID, alert.Message)
+		}
+
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("can't send %s alert to chat_id: %d", alert.Type, chatID)
+
+			continue
+		}
+
+		delivered = true
+	}
+
+	return delivered
+}
+
+// newAlert Предупреждение по правилу с теми исправляющими действиями, которые питомцу сейчас доступны.
+func newAlert(rule alertRule, pet *entity.Pet) entity.Alert {
+	return entity.Alert{Type: rule.Type, Message: rule.Message, Actions: alertActions(pet, rule.Actions)}
+}
+
+func alertActions(pet *entity.Pet, names []string) []entity.ActionInfo {
+	var actions []entity.ActionInfo
+
+	for _, name := range names {
+		action, ok := LookupAction(name)
+		if !ok {
+			continue
+		}
+
+		if available, _ := action.CanPerform(pet); !available {
+			continue
+		}
+
+		actions = append(actions, entity.ActionInfo{
+			Name:      action.Name,
+			Title:     action.Title,
+			Emoji:     action.Emoji,
+			Available: true,
+		})
+	}
+
+	return actions
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTriggeredAlerts(t *testing.T) {
+	t.Parallel()
+
+	pet := &entity.Pet{Health: 100, Hunger: 20, Happiness: 10, Energy: 50, Hygiene: 21}
+
+	tests := []struct {
+		name   string
+		change func(settings *entity.NotifySettings)
+		want   []string
+	}{
+		{name: "порог включительно", want: []string{"hunger", "happiness"}},
+		{
+			name:   "выключенное правило молчит",
+			change: func(settings *entity.NotifySettings) { settings.Alerts[2].Enabled = false },
+			want:   []string{"hunger"},
+		},
+		{
+			name:   "свой порог",
+			change: func(settings *entity.NotifySettings) { settings.Alerts[4].Threshold = 30 },
+			want:   []string{"hunger", "happiness", "hygiene"},
+		},
+		{
+			name:   "неизвестный тип пропускается",
+			change: func(settings *entity.NotifySettings) { settings.Alerts[1].Type = "boredom" },
+			want:   []string{"happiness"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			settings := defaultNotifySettings()
+			if tt.change != nil {
+				tt.change(&settings)
+			}
+
+			var got []string
+			for _, rule := range triggeredAlerts(settings, pet) {
+				got = append(got, rule.Type)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("triggeredAlerts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingNotifier Канал, который ничего не доставляет.
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(context.Context, int, string) error {
+	return errors.New("channel is down")
+}
+
+func TestService_SendWarnings(t *testing.T) {
+	t.Parallel()
+
+	const chatID = 1
+
+	noon := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	pet := &entity.Pet{State: entity.PetAlive, Health: 100, Hunger: 10, Happiness: 10, Energy: 100, Hygiene: 100}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		last     map[string]time.Time
+		failing  bool
+		want     int
+		wantLast map[string]time.Time
+	}{
+		{
+			name:     "каждое правило отдельно",
+			now:      noon,
+			want:     2,
+			wantLast: map[string]time.Time{"hunger": noon, "happiness": noon},
+		},
+		{
+			name:     "перерыв не прошёл",
+			now:      noon,
+			last:     map[string]time.Time{"hunger": noon.Add(-29 * time.Minute)},
+			want:     1,
+			wantLast: map[string]time.Time{"hunger": noon.Add(-29 * time.Minute), "happiness": noon},
+		},
+		{
+			name:     "перерыв прошёл ровно",
+			now:      noon,
+			last:     map[string]time.Time{"hunger": noon.Add(-30 * time.Minute), "happiness": noon.Add(-time.Hour)},
+			want:     2,
+			wantLast: map[string]time.Time{"hunger": noon, "happiness": noon},
+		},
+		{
+			name:     "тихие часы откладывают, перерыв не тратится",
+			now:      time.Date(2024, time.March, 1, 23, 30, 0, 0, time.UTC),
+			wantLast: map[string]time.Time{},
+		},
+		{
+			name:     "недоставленное предупреждение не сбивает перерыв",
+			now:      noon,
+			failing:  true,
+			wantLast: map[string]time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newMemoryRepo()
+			store.timezones[chatID] = "UTC"
+			store.lastAlerts[chatID] = map[string]time.Time{}
+
+			for alertType, at := range tt.last {
+				store.lastAlerts[chatID][alertType] = at
+			}
+
+			recorder := &recordingNotifier{messages: map[int][]string{}}
+
+			var notifier Notifier = recorder
+			if tt.failing {
+				notifier = failingNotifier{}
+			}
+
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{AlertCooldown: 30}, &logger, store, notifier)
+
+			settings := defaultNotifySettings()
+			s.sendWarnings(context.Background(), chatID, pet, settings, triggeredAlerts(settings, pet), tt.now)
+
+			if got := len(recorder.messages[chatID]); got != tt.want {
+				t.Errorf("sent %d alerts, want %d: %v", got, tt.want, recorder.messages[chatID])
+			}
+
+			last := store.lastAlerts[chatID]
+			if len(last) != len(tt.wantLast) {
+				t.Fatalf("last alerts = %v, want %v", last, tt.wantLast)
+			}
+
+			for alertType, want := range tt.wantLast {
+				if !last[alertType].Equal(want) {
+					t.Errorf("last %s alert = %v, want %v", alertType, last[alertType], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAlert_Actions(t *testing.T) {
+	t.Parallel()
+
+	feed, _ := lookupAlertRule("hunger")
+
+	tests := []struct {
+		name string
+		pet  *entity.Pet
+		want int
+	}{
+		{name: "голодного можно покормить", pet: &entity.Pet{State: entity.PetAlive, Hunger: 10, Health: 100, Energy: 100}, want: 1},
+		{name: "мёртвому кнопки не нужны", pet: &entity.Pet{State: entity.PetDead}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			alert := newAlert(feed, tt.pet)
+			if alert.Type != "hunger" || len(alert.Actions) != tt.want {
+				t.Errorf("newAlert() = %+v, want %d actions", alert, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+	"gocha/pkg/gocha"
+)
+
+var ErrCosmeticUnavailable = errors.New("предмет продаётся только во время события")
+
+// calendarDate Формат дат праздников в календаре: месяц и день.
+const calendarDate = "01-02"
+
+func percent(value int) *int {
+	return &value
+}
+
+// defaultCalendar Встроенный календарь; заменяется файлом из CALENDAR_FILE.
+var defaultCalendar = []entity.CalendarEvent{
+	{
+		ID:           "new_year",
+		Title:        "Новый год",
+		Emoji:        "🎄",
+		Announcement: "🎄 Новогодние праздники! %s ждёт подарков, а в гардеробе появились колпак Деда Мороза и снежный фон.",
+		Start:        "12-25",
+		End:          "01-08",
+		Decay:        entity.DecayModifiers{Hunger: percent(150), Happiness: percent(50)},
+		Items:        []string{"hat_santa", "bg_snow"},
+		Decor:        []string{"hat_santa"},
+	},
+	{
+		ID:           "halloween",
+		Title:        "Хэллоуин",
+		Emoji:        "🎃",
+		Announcement: "🎃 Хэллоуин! %s не спит по ночам и быстрее устаёт. В гардеробе — шляпа ведьмы и фон с тыквами.",
+		Start:        "10-25",
+		End:          "11-01",
+		Decay:        entity.DecayModifiers{Energy: percent(150)},
+		Items:        []string{"hat_witch", "bg_pumpkins"},
+		Decor:        []string{"hat_witch"},
+	},
+	{
+		ID:           "birthday",
+		Title:        "День рождения",
+		Emoji:        "🎂",
+		Announcement: "🎂 День рождения питомца %s! Весь день именинник не грустит, а в гардеробе появился праздничный фон.",
+		Birthday:     true,
+		Decay:        entity.DecayModifiers{Happiness: percent(0)},
+		Items:        []string{"bg_confetti"},
+		Decor:        []string{"hat_party"},
+	},
+}
+
+// loadCalendar Календарь из JSON-файла path; пустой путь — встроенный календарь.
+func loadCalendar(path string) ([]entity.CalendarEvent, error) {
+	if path == "" {
+		return defaultCalendar, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var calendar []entity.CalendarEvent
+
+	err = json.Unmarshal(data, &calendar)
+	if err != nil {
+		return nil, fmt.Errorf("parse calendar: %w", err)
+	}
+
+	for _, ev := range calendar {
+		err = validateCalendarEvent(ev)
+		if err != nil {
+			return nil, fmt.Errorf("calendar event %q: %w", ev.ID, err)
+		}
+	}
+
+	return calendar, nil
+}
+
+func validateCalendarEvent(ev entity.CalendarEvent) error {
+	if ev.ID == "" {
+		return errors.New("нет идентификатора")
+	}
+
+	err := validateAnnouncement(ev.Announcement)
+	if err != nil {
+		return err
+	}
+
+	if !ev.Birthday {
+		for _, date := range []string{ev.Start, ev.End} {
+			_, err := time.Parse(calendarDate, date)
+			if err != nil {
+				return fmt.Errorf("дата %q не в формате MM-DD", date)
+			}
+		}
+	}
+
+	for _, id := range append(slices.Clone(ev.Items), ev.Decor...) {
+		if _, ok := LookupCosmetic(id); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownCosmetic, id)
+		}
+	}
+
+	return nil
+}
+
+// validateAnnouncement Имя питомца подставляется в объявление через fmt.Sprintf, поэтому в нём ровно один %s,
+// а знак процента записывается как %%.
+func validateAnnouncement(text string) error {
+	rest := strings.ReplaceAll(text, "%%", "")
+	if strings.Count(rest, "%s") != 1 {
+		return errors.New("в объявлении должен быть ровно один %s для имени питомца")
+	}
+
+	if strings.Contains(strings.Replace(rest, "%s", "", 1), "%") {
+		return errors.New("в объявлении лишний знак %, его нужно записать как %%")
+	}
+
+	return nil
+}
+
+// ActiveEvents События, идущие сейчас в чате по его местному времени.
+func (s *Service) ActiveEvents(ctx context.Context, chatID int) ([]entity.ActiveEvent, error) {
+	s.logger.Trace().Msg("active events")
+
+	// Без питомца нет дня рождения, но праздники идут всё равно.
+	var (
+		createdAt time.Time
+		name      string
+	)
+
+	pet, err := s.repo.LoadPet(ctx, chatID)
+	switch {
+	case err == nil:
+		createdAt, name = pet.CreatedAt, pet.Name
+	case !errors.Is(err, repo.ErrPetNotFound):
+		return nil, err
+	}
+
+	return s.chatEvents(ctx, chatID, name, createdAt, time.Now()), nil
+}
+
+// chatEvents События календаря, идущие в момент at по местному времени чата.
+func (s *Service) chatEvents(ctx context.Context, chatID int, name string, createdAt, at time.Time) []entity.ActiveEvent {
+	return activeEvents(s.calendar, name, createdAt, at.In(s.chatLocation(ctx, chatID)))
+}
+
+// activeEvents События календаря, идущие в момент now; now задан в местном времени чата.
+func activeEvents(calendar []entity.CalendarEvent, name string, createdAt, now time.Time) []entity.ActiveEvent {
+	events := make([]entity.ActiveEvent, 0)
+
+	for _, ev := range calendar {
+		start, end, ok := occurrence(ev, createdAt, now)
+		if !ok {
+			continue
+		}
+
+		events = append(events, entity.ActiveEvent{
+			ID:           ev.ID,
+			Title:        ev.Title,
+			Emoji:        ev.Emoji,
+			Announcement: fmt.Sprintf(ev.Announcement, name),
+			StartedAt:    start,
+			EndsAt:       end,
+			Decay:        ev.Decay,
+			Items:        ev.Items,
+			Decor:        ev.Decor,
+		})
+	}
+
+	return events
+}
+
+// occurrence Границы текущего проведения события [start, end), если now в него попадает.
+func occurrence(ev entity.CalendarEvent, createdAt, now time.Time) (time.Time, time.Time, bool) {
+	loc := now.Location()
+
+	if ev.Birthday {
+		if createdAt.IsZero() {
+			return time.Time{}, time.Time{}, false
+		}
+
+		born := createdAt.In(loc)
+		start := time.Date(now.Year(), born.Month(), born.Day(), 0, 0, 0, 0, loc)
+
+		// В день появления питомца праздновать ещё нечего.
+		if now.Year() == born.Year() || !sameDay(start, now) {
+			return time.Time{}, time.Time{}, false
+		}
+
+		return start, start.AddDate(0, 0, 1), true
+	}
+
+	from, err := time.Parse(calendarDate, ev.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	to, err := time.Parse(calendarDate, ev.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	// Событие могло начаться в прошлом году и ещё не кончиться, как Новый год.
+	for _, year := range []int{now.Year() - 1, now.Year()} {
+		start := time.Date(year, from.Month(), from.Day(), 0, 0, 0, 0, loc)
+
+		end := time.Date(year, to.Month(), to.Day(), 0, 0, 0, 0, loc)
+		if end.Before(start) {
+			end = end.AddDate(1, 0, 0)
+		}
+
+		end = end.AddDate(0, 0, 1)
+
+		if !now.Before(start) && now.Before(end) {
+			return start, end, true
+		}
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// decayPercent Множители убывания статов от всех идущих событий; nil, если события скорость не меняют.
+func decayPercent(events []entity.ActiveEvent) *gocha.DecayPercent {
+	result := gocha.DecayPercent{Hunger: 100, Energy: 100, Hygiene: 100, Happiness: 100}
+	changed := false
+
+	for _, ev := range events {
+		for _, m := range []struct {
+			dst *int
+			src *int
+		}{
+			{&result.Hunger, ev.Decay.Hunger},
+			{&result.Energy, ev.Decay.Energy},
+			{&result.Hygiene, ev.Decay.Hygiene},
+			{&result.Happiness, ev.Decay.Happiness},
+		} {
+			if m.src != nil {
+				*m.dst = *m.dst * max(*m.src, 0) / 100
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return &result
+}
+
+// eventItem Идущее событие, во время которого продаётся предмет; themed = false для обычных предметов.
+func (s *Service) eventItem(id string, events []entity.ActiveEvent) (active *entity.ActiveEvent, themed bool) {
+	for _, ev := range s.calendar {
+		if slices.Contains(ev.Items, id) {
+			themed = true
+		}
+	}
+
+	for i := range events {
+		if slices.Contains(events[i].Items, id) {
+			return &events[i], true
+		}
+	}
+
+	return nil, themed
+}
+
+// withDecor Добавляет к надетым предметам праздничные украшения на свободные места.
+func withDecor(ids []string, events []entity.ActiveEvent) []string {
+	taken := make(map[entity.CosmeticSlot]bool, len(ids))
+
+	for _, id := range ids {
+		if item, ok := LookupCosmetic(id); ok {
+			taken[item.Slot] = true
+		}
+	}
+
+	for _, ev := range events {
+		for _, id := range ev.Decor {
+			item, ok := LookupCosmetic(id)
+			if !ok || taken[item.Slot] {
+				continue
+			}
+
+			taken[item.Slot] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return sortBySlot(ids)
+}
+
+// announceEvents Объявляет в чат о начале событий: каждое проведение — один раз.
+func (s *Service) announceEvents(ctx context.Context, chatID int, events []entity.ActiveEvent) {
+	for _, ev := range events {
+		first, err := s.repo.MarkEventAnnounced(ctx, chatID, ev.ID, ev.StartedAt)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("can't mark event announced")
+
+			continue
+		}
+
+		if first {
+			s.notify(ctx, chatID, ev.Announcement)
+		}
+	}
+}
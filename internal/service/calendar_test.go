@@ -0,0 +1,242 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/pkg/gocha"
+)
+
+func TestValidateCalendarEvent(t *testing.T) {
+	t.Parallel()
+
+	valid := entity.CalendarEvent{ID: "spring", Announcement: "🌷 Весна! %s радуется солнцу.", Start: "03-01", End: "03-08"}
+
+	tests := []struct {
+		name    string
+		change  func(ev *entity.CalendarEvent)
+		wantErr bool
+	}{
+		{name: "корректное событие", change: func(*entity.CalendarEvent) {}},
+		{name: "нет идентификатора", change: func(ev *entity.CalendarEvent) { ev.ID = "" }, wantErr: true},
+		{name: "дата не в формате", change: func(ev *entity.CalendarEvent) { ev.End = "8 марта" }, wantErr: true},
+		{name: "неизвестный предмет", change: func(ev *entity.CalendarEvent) { ev.Items = []string{"hat_tulip"} }, wantErr: true},
+		{name: "день рождения без дат", change: func(ev *entity.CalendarEvent) { ev.Birthday, ev.Start, ev.End = true, "", "" }},
+		{name: "процент записан как %%", change: func(ev *entity.CalendarEvent) { ev.Announcement = "Скидки 50%%! %s в восторге." }},
+		{name: "нет места для имени", change: func(ev *entity.CalendarEvent) { ev.Announcement = "Весна!" }, wantErr: true},
+		{name: "имя дважды", change: func(ev *entity.CalendarEvent) { ev.Announcement = "%s, %s!" }, wantErr: true},
+		{name: "другой глагол форматирования", change: func(ev *entity.CalendarEvent) { ev.Announcement = "%s съел %d конфет" }, wantErr: true},
+		{name: "одиночный процент", change: func(ev *entity.CalendarEvent) { ev.Announcement = "Скидки 50% для %s" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ev := valid
+			tt.change(&ev)
+
+			if err := validateCalendarEvent(ev); (err != nil) != tt.wantErr {
+				t.Errorf("validateCalendarEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCalendarEvent_Default(t *testing.T) {
+	t.Parallel()
+
+	for _, ev := range defaultCalendar {
+		if err := validateCalendarEvent(ev); err != nil {
+			t.Errorf("default event %q: %v", ev.ID, err)
+		}
+	}
+}
+
+func TestOccurrence(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	date := func(y int, m time.Month, d, h int) time.Time { return time.Date(y, m, d, h, 0, 0, 0, loc) }
+
+	newYear := entity.CalendarEvent{ID: "new_year", Start: "12-25", End: "01-08"}
+	halloween := entity.CalendarEvent{ID: "halloween", Start: "10-25", End: "11-01"}
+	birthday := entity.CalendarEvent{ID: "birthday", Birthday: true}
+
+	tests := []struct {
+		name      string
+		ev        entity.CalendarEvent
+		createdAt time.Time
+		now       time.Time
+		ok        bool
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "Новый год до полуночи 31 декабря",
+			ev:        newYear,
+			now:       date(2024, time.December, 31, 23),
+			ok:        true,
+			wantStart: date(2024, time.December, 25, 0),
+			wantEnd:   date(2025, time.January, 9, 0),
+		},
+		{
+			name:      "Новый год после смены года",
+			ev:        newYear,
+			now:       date(2025, time.January, 3, 12),
+			ok:        true,
+			wantStart: date(2024, time.December, 25, 0),
+			wantEnd:   date(2025, time.January, 9, 0),
+		},
+		{
+			name: "последний день включён, следующий — уже нет",
+			ev:   newYear,
+			now:  date(2025, time.January, 9, 0),
+		},
+		{
+			name:      "первый день события",
+			ev:        halloween,
+			now:       date(2024, time.October, 25, 0),
+			ok:        true,
+			wantStart: date(2024, time.October, 25, 0),
+			wantEnd:   date(2024, time.November, 2, 0),
+		},
+		{
+			name: "до события",
+			ev:   halloween,
+			now:  date(2024, time.October, 24, 23),
+		},
+		{
+			name:      "день рождения",
+			ev:        birthday,
+			createdAt: date(2023, time.May, 10, 15),
+			now:       date(2024, time.May, 10, 9),
+			ok:        true,
+			wantStart: date(2024, time.May, 10, 0),
+			wantEnd:   date(2024, time.May, 11, 0),
+		},
+		{
+			name:      "день рождения по местному времени чата",
+			ev:        birthday,
+			createdAt: time.Date(2023, time.May, 9, 22, 0, 0, 0, time.UTC), // 10 мая 01:00 по UTC+3.
+			now:       date(2024, time.May, 10, 9),
+			ok:        true,
+			wantStart: date(2024, time.May, 10, 0),
+			wantEnd:   date(2024, time.May, 11, 0),
+		},
+		{
+			name:      "в день появления праздновать нечего",
+			ev:        birthday,
+			createdAt: date(2024, time.May, 10, 8),
+			now:       date(2024, time.May, 10, 9),
+		},
+		{
+			name:      "не день рождения",
+			ev:        birthday,
+			createdAt: date(2023, time.May, 10, 8),
+			now:       date(2024, time.May, 11, 9),
+		},
+		{
+			name: "день рождения без питомца",
+			ev:   birthday,
+			now:  date(2024, time.May, 10, 9),
+		},
+		{
+			name:      "родившийся 29 февраля празднует 1 марта",
+			ev:        birthday,
+			createdAt: date(2024, time.February, 29, 12),
+			now:       date(2025, time.March, 1, 12),
+			ok:        true,
+			wantStart: date(2025, time.March, 1, 0),
+			wantEnd:   date(2025, time.March, 2, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			start, end, ok := occurrence(tt.ev, tt.createdAt, tt.now)
+			if ok != tt.ok {
+				t.Fatalf("occurrence() ok = %v, want %v", ok, tt.ok)
+			}
+
+			if ok && (!start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd)) {
+				t.Errorf("occurrence() = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestDecayPercent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		events []entity.ActiveEvent
+		want   *gocha.DecayPercent
+	}{
+		{
+			name: "нет событий",
+		},
+		{
+			name:   "событие не меняет скорость",
+			events: []entity.ActiveEvent{{ID: "plain"}},
+		},
+		{
+			name:   "одно событие",
+			events: []entity.ActiveEvent{{Decay: entity.DecayModifiers{Hunger: percent(150)}}},
+			want:   &gocha.DecayPercent{Hunger: 150, Energy: 100, Hygiene: 100, Happiness: 100},
+		},
+		{
+			name: "модификаторы складываются умножением",
+			events: []entity.ActiveEvent{
+				{Decay: entity.DecayModifiers{Hunger: percent(150), Happiness: percent(50)}},
+				{Decay: entity.DecayModifiers{Hunger: percent(200), Energy: percent(150)}},
+			},
+			want: &gocha.DecayPercent{Hunger: 300, Energy: 150, Hygiene: 100, Happiness: 50},
+		},
+		{
+			name: "ноль побеждает всё",
+			events: []entity.ActiveEvent{
+				{Decay: entity.DecayModifiers{Happiness: percent(0)}},
+				{Decay: entity.DecayModifiers{Happiness: percent(150)}},
+			},
+			want: &gocha.DecayPercent{Hunger: 100, Energy: 100, Hygiene: 100, Happiness: 0},
+		},
+		{
+			name:   "отрицательный множитель считается нулём",
+			events: []entity.ActiveEvent{{Decay: entity.DecayModifiers{Hygiene: percent(-50)}}},
+			want:   &gocha.DecayPercent{Hunger: 100, Energy: 100, Hygiene: 0, Happiness: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := decayPercent(tt.events)
+
+			switch {
+			case got == nil || tt.want == nil:
+				if got != tt.want {
+					t.Errorf("decayPercent() = %v, want %v", got, tt.want)
+				}
+			case *got != *tt.want:
+				t.Errorf("decayPercent() = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveEvents_Announcement(t *testing.T) {
+	t.Parallel()
+
+	calendar := []entity.CalendarEvent{{ID: "sale", Announcement: "Скидки 50%%! %s в восторге.", Start: "03-01", End: "03-01"}}
+
+	events := activeEvents(calendar, "Гоча", time.Time{}, time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+	if len(events) != 1 || events[0].Announcement != "Скидки 50%! Гоча в восторге." {
+		t.Errorf("activeEvents() = %+v", events)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/notify"
+	"gocha/internal/repo"
+)
+
+var (
+	ErrUnknownChannel   = errors.New("неизвестный канал: webhook или email")
+	ErrBadChannelTarget = errors.New("нужен адрес http(s) для вебхука или адрес почты")
+	ErrEmailDisabled    = errors.New("отправка почты не настроена на сервере")
+	ErrNoChannel        = errors.New("этот канал не подключён")
+)
+
+// webhookTimeout Сколько ждать ответа вебхука на одну попытку.
+const webhookTimeout = 10 * time.Second
+
+// NotifyChannels Дополнительные каналы уведомлений чата.
+func (s *Service) NotifyChannels(ctx context.Context, chatID int) ([]entity.NotifyChannel, error) {
+	s.logger.Trace().Msg("notify channels")
+
+	return s.repo.GetNotifyChannels(ctx, chatID)
+}
+
+// SetNotifyChannel Подключает к чату вебхук или почту. Для вебхука создаётся новый секрет подписи —
+// его показывают один раз, при подключении. Права проверяет вызывающий: подключать каналы может только
+// администратор чата.
+func (s *Service) SetNotifyChannel(ctx context.Context, chatID int, kind, target string) (entity.NotifyChannel, error) {
+	s.logger.Trace().Msg("set notify channel")
+
+	ch := entity.NotifyChannel{Kind: entity.ChannelKind(strings.ToLower(kind)), Target: strings.TrimSpace(target), CreatedAt: time.Now()}
+
+	switch ch.Kind {
+	case entity.ChannelWebhook:
+		u, err := url.Parse(ch.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || internalHost(u.Hostname()) {
+			return entity.NotifyChannel{}, ErrBadChannelTarget
+		}
+
+		secret := make([]byte, 16)
+
+		_, err = rand.Read(secret)
+		if err != nil {
+			return entity.NotifyChannel{}, err
+		}
+
+		ch.Secret = hex.EncodeToString(secret)
+	case entity.ChannelEmail:
+		if s.cfg.SMTPHost == "" {
+			return entity.NotifyChannel{}, ErrEmailDisabled
+		}
+
+		addr, err := mail.ParseAddress(ch.Target)
+		if err != nil {
+			return entity.NotifyChannel{}, ErrBadChannelTarget
+		}
+
+		ch.Target = addr.Address
+	default:
+		return entity.NotifyChannel{}, ErrUnknownChannel
+	}
+
+	err := s.repo.SaveNotifyChannel(ctx, chatID, ch)
+	if err != nil {
+		return entity.NotifyChannel{}, err
+	}
+
+	return ch, nil
+}
+
+// RemoveNotifyChannel Отключает канал чата.
+func (s *Service) RemoveNotifyChannel(ctx context.Context, chatID int, kind string) error {
+	s.logger.Trace().Msg("remove notify channel")
+
+	err := s.repo.DeleteNotifyChannel(ctx, chatID, entity.ChannelKind(strings.ToLower(kind)))
+	if errors.Is(err, repo.ErrNoChannel) {
+		return ErrNoChannel
+	}
+
+	return err
+}
+
+// chatNotifiers Куда доставлять уведомления чата: в сам чат и во все его дополнительные каналы.
+func (s *Service) chatNotifiers(ctx context.Context, chatID int) []Notifier {
+	notifiers := make([]Notifier, 0, 1)
+	if s.notifier != nil {
+		notifiers = append(notifiers, s.notifier)
+	}
+
+	channels, err := s.repo.GetNotifyChannels(ctx, chatID)
+	if err != nil {
+		s.logger.Error().Err(err).Msgf("can't get notify channels for chat_id: %d", chatID)
+
+		return notifiers
+	}
+
+	// Внешние каналы доставляются через очередь: отправка сюда только ставит сообщение в неё.
+	for _, ch := range channels {
+		switch ch.Kind {
+		case entity.ChannelWebhook:
+			notifiers = append(notifiers, &queuedNotifier{queue: s.deliveries, target: notify.NewWebhook(ch.Target, ch.Secret, s.webhooks)})
+		case entity.ChannelEmail:
+			if s.cfg.SMTPHost != "" {
+				notifiers = append(notifiers, &queuedNotifier{queue: s.deliveries, target: notify.NewMailer(s.smtpConfig(), ch.Target)})
+			}
+		}
+	}
+
+	return notifiers
+}
+
+func (s *Service) smtpConfig() notify.SMTPConfig {
+	return notify.SMTPConfig{
+		Host:     s.cfg.SMTPHost,
+		Port:     s.cfg.SMTPPort,
+		Username: s.cfg.SMTPUser,
+		Password: s.cfg.SMTPPassword,
+		From:     s.cfg.SMTPFrom,
+	}
+}
+
+// internalHost Адрес, заведомо ведущий во внутреннюю сеть. Имена проверяются ещё раз при каждой отправке, уже
+// по адресу, в который они разрешились.
+func internalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+
+	ip, err := netip.ParseAddr(host)
+
+	return err == nil && !notify.PublicAddr(ip)
+}
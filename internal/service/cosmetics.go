@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+var (
+	ErrUnknownCosmetic  = errors.New("неизвестный предмет")
+	ErrCosmeticOwned    = errors.New("предмет уже куплен")
+	ErrCosmeticNotOwned = errors.New("предмет не куплен")
+	ErrNotEnoughCoins   = errors.New("недостаточно монет")
+)
+
+var cosmetics = []entity.Cosmetic{
+	{ID: "bg_sky", Slot: entity.SlotBackground, Title: "Небо", Emoji: "🌤", Price: 20},
+	{ID: "bg_sunset", Slot: entity.SlotBackground, Title: "Закат", Emoji: "🌅", Price: 40},
+	{ID: "bg_meadow", Slot: entity.SlotBackground, Title: "Луг", Emoji: "🌿", Price: 40},
+	{ID: "collar_red", Slot: entity.SlotCollar, Title: "Красный ошейник", Emoji: "🔴", Price: 15},
+	{ID: "collar_bell", Slot: entity.SlotCollar, Title: "Ошейник с колокольчиком", Emoji: "🔔", Price: 35},
+	{ID: "hat_cap", Slot: entity.SlotHat, Title: "Кепка", Emoji: "🧢", Price: 30},
+	{ID: "hat_party", Slot: entity.SlotHat, Title: "Праздничный колпак", Emoji: "🥳", Price: 50},
+	{ID: "hat_crown", Slot: entity.SlotHat, Title: "Корона", Emoji: "👑", Price: 150},
+	// Тематические предметы продаются только во время событий календаря.
+	{ID: "bg_snow", Slot: entity.SlotBackground, Title: "Снегопад", Emoji: "❄️", Price: 30},
+	{ID: "bg_pumpkins", Slot: entity.SlotBackground, Title: "Тыквы", Emoji: "🎃", Price: 30},
+	{ID: "bg_confetti", Slot: entity.SlotBackground, Title: "Конфетти", Emoji: "🎊", Price: 10},
+	{ID: "hat_santa", Slot: entity.SlotHat, Title: "Колпак Деда Мороза", Emoji: "🎅", Price: 40},
+	{ID: "hat_witch", Slot: entity.SlotHat, Title: "Шляпа ведьмы", Emoji: "🧙", Price: 40},
+}
+
+// LookupCosmetic Предмет каталога по идентификатору.
+func LookupCosmetic(id string) (entity.Cosmetic, bool) {
+	for _, item := range cosmetics {
+		if item.ID == id {
+			return item, true
+		}
+	}
+
+	return entity.Cosmetic{}, false
+}
+
+// Wardrobe Каталог предметов с отметками о покупках чата. Тематические предметы видны, пока идёт их событие
+// или если уже куплены.
+func (s *Service) Wardrobe(ctx context.Context, chatID int, actor entity.Actor) (entity.Wardrobe, error) {
+	s.logger.Trace().Msg("wardrobe")
+
+	owned, err := s.repo.GetCosmetics(ctx, chatID)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	events, err := s.ActiveEvents(ctx, chatID)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	coins, err := s.repo.GetCoins(ctx, chatID, chatStreakUser)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	var own int
+	if actor.ID != chatStreakUser {
+		own, err = s.repo.GetCoins(ctx, chatID, actor.ID)
+		if err != nil {
+			return entity.Wardrobe{}, err
+		}
+	}
+
+	items := make([]entity.Cosmetic, 0, len(cosmetics))
+	for _, item := range cosmetics {
+		item.Equipped, item.Owned = owned[item.ID]
+
+		ev, themed := s.eventItem(item.ID, events)
+		if themed && ev == nil && !item.Owned {
+			continue
+		}
+
+		if ev != nil {
+			item.Event = ev.Emoji + " " + ev.Title
+		}
+
+		items = append(items, item)
+	}
+
+	return entity.Wardrobe{Coins: coins, OwnCoins: own, Items: items}, nil
+}
+
+// BuyCosmetic Покупает предмет на монеты чата, а если их не хватает — на личные монеты покупателя.
+func (s *Service) BuyCosmetic(ctx context.Context, chatID int, buyer entity.Actor, id string) (entity.Wardrobe, error) {
+	s.logger.Trace().Msg("buy cosmetic")
+
+	item, ok := LookupCosmetic(id)
+	if !ok {
+		return entity.Wardrobe{}, ErrUnknownCosmetic
+	}
+
+	owned, err := s.repo.GetCosmetics(ctx, chatID)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	if _, ok = owned[id]; ok {
+		return entity.Wardrobe{}, ErrCosmeticOwned
+	}
+
+	events, err := s.ActiveEvents(ctx, chatID)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	if ev, themed := s.eventItem(id, events); themed && ev == nil {
+		return entity.Wardrobe{}, ErrCosmeticUnavailable
+	}
+
+	wallet, err := s.spendCoins(ctx, chatID, buyer, item.Price)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	err = s.repo.AddCosmetic(ctx, chatID, item.ID, item.Slot)
+	if err != nil {
+		// Возвращаем монеты в тот кошелёк, из которого за предмет заплатили.
+		_, refundErr := s.repo.AddCoins(ctx, chatID, wallet, item.Price)
+		if refundErr != nil {
+			s.logger.Error().Err(refundErr).Msg("can't refund cosmetic")
+		}
+
+		return entity.Wardrobe{}, err
+	}
+
+	return s.Wardrobe(ctx, chatID, buyer)
+}
+
+// spendCoins Списывает монеты с кошелька чата, а если их не хватает — с личного кошелька покупателя, куда идут
+// награды за его серию. Возвращает user_id кошелька, с которого списано.
+func (s *Service) spendCoins(ctx context.Context, chatID int, buyer entity.Actor, amount int) (int64, error) {
+	wallets := []int64{chatStreakUser}
+	if buyer.ID != chatStreakUser {
+		wallets = append(wallets, buyer.ID)
+	}
+
+	for _, wallet := range wallets {
+		_, err := s.repo.SpendCoins(ctx, chatID, wallet, amount)
+		if err == nil {
+			return wallet, nil
+		}
+
+		if !errors.Is(err, repo.ErrNotEnoughCoins) {
+			return 0, err
+		}
+	}
+
+	return 0, ErrNotEnoughCoins
+}
+
+// EquipCosmetic Надевает купленный предмет (снимая другой с того же места) или снимает его.
+func (s *Service) EquipCosmetic(ctx context.Context, chatID int, actor entity.Actor, id string, equip bool) (entity.Wardrobe, error) {
+	s.logger.Trace().Msg("equip cosmetic")
+
+	item, ok := LookupCosmetic(id)
+	if !ok {
+		return entity.Wardrobe{}, ErrUnknownCosmetic
+	}
+
+	owned, err := s.repo.GetCosmetics(ctx, chatID)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	equipped, ok := owned[id]
+	if !ok {
+		return entity.Wardrobe{}, ErrCosmeticNotOwned
+	}
+
+	if equipped == equip {
+		return s.Wardrobe(ctx, chatID, actor)
+	}
+
+	target := item.ID
+	if !equip {
+		target = ""
+	}
+
+	err = s.repo.EquipCosmetic(ctx, chatID, item.Slot, target)
+	if err != nil {
+		return entity.Wardrobe{}, err
+	}
+
+	return s.Wardrobe(ctx, chatID, actor)
+}
+
+// equippedCosmetics Надетые предметы в порядке слоёв аватара.
+func (s *Service) equippedCosmetics(ctx context.Context, chatID int) []string {
+	owned, err := s.repo.GetCosmetics(ctx, chatID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load cosmetics")
+
+		return nil
+	}
+
+	var ids []string
+
+	for _, item := range cosmetics {
+		if owned[item.ID] {
+			ids = append(ids, item.ID)
+		}
+	}
+
+	return sortBySlot(ids)
+}
+
+// sortBySlot Упорядочивает предметы по слоям аватара.
+func sortBySlot(ids []string) []string {
+	slot := func(id string) int {
+		item, _ := LookupCosmetic(id)
+
+		return slices.Index(entity.CosmeticSlots, item.Slot)
+	}
+
+	slices.SortStableFunc(ids, func(a, b string) int {
+		return slot(a) - slot(b)
+	})
+
+	return ids
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSortBySlot(t *testing.T) {
+	t.Parallel()
+
+	got := sortBySlot([]string{"hat_cap", "collar_red", "bg_sky"})
+	if want := []string{"bg_sky", "collar_red", "hat_cap"}; !slices.Equal(got, want) {
+		t.Errorf("sortBySlot() = %v, want %v", got, want)
+	}
+}
+
+func TestService_BuyCosmetic(t *testing.T) {
+	t.Parallel()
+
+	buyer := entity.Actor{ID: 42}
+
+	tests := []struct {
+		name      string
+		item      string
+		owned     []string
+		coins     int
+		addFails  bool
+		wantErr   error
+		wantCoins int
+		wantOwned bool
+	}{
+		{
+			name:      "покупка",
+			item:      "hat_cap",
+			coins:     100,
+			wantCoins: 70,
+			wantOwned: true,
+		},
+		{
+			name:      "неизвестный предмет",
+			item:      "hat_unknown",
+			coins:     100,
+			wantErr:   ErrUnknownCosmetic,
+			wantCoins: 100,
+		},
+		{
+			name:      "уже куплен",
+			item:      "hat_cap",
+			owned:     []string{"hat_cap"},
+			coins:     100,
+			wantErr:   ErrCosmeticOwned,
+			wantCoins: 100,
+			wantOwned: true,
+		},
+		{
+			name:      "не хватает монет",
+			item:      "hat_crown",
+			coins:     100,
+			wantErr:   ErrNotEnoughCoins,
+			wantCoins: 100,
+		},
+		{
+			name:      "тематический предмет вне события",
+			item:      "hat_witch",
+			coins:     100,
+			wantErr:   ErrCosmeticUnavailable,
+			wantCoins: 100,
+		},
+		{
+			name:      "монеты возвращаются, если предмет не достался",
+			item:      "hat_cap",
+			coins:     100,
+			addFails:  true,
+			wantErr:   errors.New("db is down"),
+			wantCoins: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newMemoryRepo()
+			store.coins[walletKey{1, chatStreakUser}] = tt.coins
+			store.cosmetics[1] = map[string]ownedCosmetic{}
+
+			if tt.addFails {
+				store.addCosmeticErr = errors.New("db is down")
+			}
+
+			for _, id := range tt.owned {
+				item, _ := LookupCosmetic(id)
+				store.cosmetics[1][id] = ownedCosmetic{slot: item.Slot}
+			}
+
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, store, nil)
+			// Событие со шляпой ведьмы прошло позавчера.
+			past := time.Now().AddDate(0, 0, -2).Format(calendarDate)
+			s.calendar = []entity.CalendarEvent{{ID: "past", Start: past, End: past, Items: []string{"hat_witch"}}}
+
+			_, err := s.BuyCosmetic(context.Background(), 1, buyer, tt.item)
+
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("BuyCosmetic() error = %v", err)
+			case tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()):
+				t.Fatalf("BuyCosmetic() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if coins := store.coins[walletKey{1, chatStreakUser}]; coins != tt.wantCoins {
+				t.Errorf("coins = %d, want %d", coins, tt.wantCoins)
+			}
+
+			if _, owned := store.cosmetics[1][tt.item]; owned != tt.wantOwned {
+				t.Errorf("owned = %v, want %v", owned, tt.wantOwned)
+			}
+		})
+	}
+}
+
+func TestService_EquipCosmetic(t *testing.T) {
+	t.Parallel()
+
+	store := newMemoryRepo()
+	store.cosmetics[1] = map[string]ownedCosmetic{"hat_cap": {slot: entity.SlotHat}, "hat_party": {slot: entity.SlotHat}}
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, nil)
+	ctx := context.Background()
+
+	if _, err := s.EquipCosmetic(ctx, 1, entity.Actor{}, "hat_crown", true); !errors.Is(err, ErrCosmeticNotOwned) {
+		t.Errorf("equip not owned error = %v, want %v", err, ErrCosmeticNotOwned)
+	}
+
+	if _, err := s.EquipCosmetic(ctx, 1, entity.Actor{}, "hat_cap", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Второй предмет на то же место снимает первый.
+	wardrobe, err := s.EquipCosmetic(ctx, 1, entity.Actor{}, "hat_party", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range wardrobe.Items {
+		if want := item.ID == "hat_party"; item.Equipped != want {
+			t.Errorf("%s equipped = %v, want %v", item.ID, item.Equipped, want)
+		}
+	}
+
+	if _, err = s.EquipCosmetic(ctx, 1, entity.Actor{}, "hat_party", false); err != nil || store.cosmetics[1]["hat_party"].equipped {
+		t.Errorf("unequip: err = %v, wardrobe = %+v", err, store.cosmetics[1])
+	}
+}
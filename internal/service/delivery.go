@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/notify"
+)
+
+var ErrDeliveryQueueFull = errors.New("delivery queue is full")
+
+const (
+	deliveryWorkers   = 4
+	deliveryQueueSize = 256
+	deliveryTimeout   = 15 * time.Second // Срок одной попытки доставки.
+	deliveryAttempts  = 3
+	deliveryBackoff   = time.Second // Пауза перед второй попыткой; дальше удваивается.
+)
+
+// delivery Сообщение или предупреждение для внешнего канала чата.
+type delivery struct {
+	chatID  int
+	target  Notifier
+	message string
+	alert   *entity.Alert
+	attempt int // Сколько попыток уже не удалось.
+}
+
+// deliveryQueue Очередь доставки во внешние каналы: вебхуки и почту. Медленный или лежащий адрес занимает
+// воркера очереди, а не замер питомца или ответ пользователю.
+type deliveryQueue struct {
+	jobs    chan delivery
+	backoff time.Duration
+}
+
+func newDeliveryQueue() *deliveryQueue {
+	return &deliveryQueue{jobs: make(chan delivery, deliveryQueueSize), backoff: deliveryBackoff}
+}
+
+// push Ставит доставку в очередь; не блокирует.
+func (q *deliveryQueue) push(d delivery) error {
+	select {
+	case q.jobs <- d:
+		return nil
+	default:
+		return ErrDeliveryQueueFull
+	}
+}
+
+// queuedNotifier Внешний канал, доставка в который идёт через очередь: Notify и Alert только ставят
+// сообщение в очередь.
+type queuedNotifier struct {
+	queue  *deliveryQueue
+	target Notifier
+}
+
+func (n *queuedNotifier) Notify(_ context.Context, chatID int, message string) error {
+	return n.queue.push(delivery{chatID: chatID, target: n.target, message: message})
+}
+
+func (n *queuedNotifier) Alert(_ context.Context, chatID int, alert entity.Alert) error {
+	return n.queue.push(delivery{chatID: chatID, target: n.target, message: alert.Message, alert: &alert})
+}
+
+// RunDeliveries Запускает воркеров доставки во внешние каналы. Блокирует до отмены контекста.
+func (s *Service) RunDeliveries(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(deliveryWorkers)
+
+	for range deliveryWorkers {
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-s.deliveries.jobs:
+					s.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// deliver Одна попытка доставки со своим сроком. Временную ошибку повторяет позже с растущей паузой: на
+// время паузы доставка уходит из очереди и воркера не занимает.
+func (s *Service) deliver(ctx context.Context, d delivery) {
+	attemptCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	var err error
+
+	if n, ok := d.target.(AlertNotifier); ok && d.alert != nil {
+		err = n.Alert(attemptCtx, d.chatID, *d.alert)
+	} else {
+		err = d.target.Notify(attemptCtx, d.chatID, d.message)
+	}
+
+	if err == nil {
+		return
+	}
+
+	d.attempt++
+
+	if !errors.Is(err, notify.ErrTemporary) || d.attempt >= deliveryAttempts || ctx.Err() != nil {
+		s.logger.Error().Err(err).Msgf("can't deliver to chat_id: %d after %d attempts", d.chatID, d.attempt)
+
+		return
+	}
+
+	time.AfterFunc(s.deliveries.backoff<<(d.attempt-1), func() {
+		err := s.deliveries.push(d)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("can't retry delivery to chat_id: %d", d.chatID)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+	"gocha/internal/notify"
+
+	"github.com/rs/zerolog"
+)
+
+// flakyNotifier Канал, первые failures попыток доставки в который заканчиваются ошибкой fail.
+type flakyNotifier struct {
+	mu        sync.Mutex
+	failures  int
+	fail      error
+	calls     int
+	deadlines []bool
+	attempted chan struct{}
+}
+
+func (n *flakyNotifier) Notify(ctx context.Context, _ int, _ string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	defer func() { n.attempted <- struct{}{} }()
+
+	_, ok := ctx.Deadline()
+	n.deadlines = append(n.deadlines, ok)
+	n.calls++
+
+	if n.calls <= n.failures {
+		return n.fail
+	}
+
+	return nil
+}
+
+func TestService_RunDeliveries(t *testing.T) {
+	t.Parallel()
+
+	temporary := fmt.Errorf("%w: webhook responded 502", notify.ErrTemporary)
+
+	tests := []struct {
+		name      string
+		failures  int
+		fail      error
+		wantCalls int
+	}{
+		{name: "с первой попытки", wantCalls: 1},
+		{name: "временная ошибка повторяется", failures: 2, fail: temporary, wantCalls: 3},
+		{name: "окончательная ошибка не повторяется", failures: 1, fail: errors.New("webhook responded 400"), wantCalls: 1},
+		{name: "попытки заканчиваются", failures: deliveryAttempts + 1, fail: temporary, wantCalls: deliveryAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, newMemoryRepo(), nil)
+			s.deliveries.backoff = time.Millisecond
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go s.RunDeliveries(ctx)
+
+			target := &flakyNotifier{failures: tt.failures, fail: tt.fail, attempted: make(chan struct{}, deliveryAttempts+1)}
+			queued := &queuedNotifier{queue: s.deliveries, target: target}
+
+			err := queued.Notify(ctx, 1, "привет")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for range tt.wantCalls {
+				select {
+				case <-target.attempted:
+				case <-time.After(time.Second):
+					t.Fatal("delivery was not attempted")
+				}
+			}
+
+			// Лишней попытки быть не должно.
+			select {
+			case <-target.attempted:
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			target.mu.Lock()
+			defer target.mu.Unlock()
+
+			if target.calls != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", target.calls, tt.wantCalls)
+			}
+
+			for i, ok := range target.deadlines {
+				if !ok {
+					t.Errorf("attempt %d without deadline", i+1)
+				}
+			}
+		})
+	}
+}
+
+// blockingNotifier Канал, который не отвечает, пока не отменят контекст.
+type blockingNotifier struct{}
+
+func (blockingNotifier) Notify(ctx context.Context, _ int, _ string) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestService_SendAlert_Queued(t *testing.T) {
+	t.Parallel()
+
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, newMemoryRepo(), nil)
+
+	// Воркеры не запущены: отправка только ставит предупреждение в очередь и не ждёт зависший канал.
+	queued := &queuedNotifier{queue: s.deliveries, target: blockingNotifier{}}
+
+	start := time.Now()
+
+	err := queued.Alert(context.Background(), 1, entity.Alert{Type: "hunger", Message: "голоден"})
+	if err != nil || time.Since(start) > 100*time.Millisecond {
+		t.Fatalf("Alert() error = %v after %v", err, time.Since(start))
+	}
+
+	for range deliveryQueueSize - 1 {
+		_ = queued.Notify(context.Background(), 1, "")
+	}
+
+	if err = queued.Notify(context.Background(), 1, ""); !errors.Is(err, ErrDeliveryQueueFull) {
+		t.Errorf("Notify() on full queue error = %v, want %v", err, ErrDeliveryQueueFull)
+	}
+}
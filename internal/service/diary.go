@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+const (
+	diaryPageSize = 30
+	defaultLocale = "ru"
+)
+
+// diaryLocale Шаблоны фраз дневника для одного языка.
+type diaryLocale struct {
+	Opening string
+	Care    map[string]string // Действие -> фраза с перечнем тех, кто его совершал.
+	Nobody  string
+	Someone string
+	Sad     string
+	NotSad  string
+	Slept   string
+	NoSleep string
+	Sick    string
+	Good    string
+	Fine    string
+	Bad     string
+	And     string
+}
+
+var diaryLocales = map[string]diaryLocale{
+	"ru": {
+		Opening: "Дорогой дневник!",
+		Care: map[string]string{
+			"feed":     "Меня кормили: %s.",
+			"play":     "Со мной играли: %s.",
+			"clean":    "Меня мыли: %s.",
+			"heal":     "Меня лечили: %s.",
+			"sleep":    "Спать меня укладывали: %s.",
+			"wakeup":   "Будили меня: %s.",
+			"train":    "Трюкам меня учили: %s.",
+			"trick":    "Трюки меня просили показать: %s.",
+			"activity": "По делам меня отправляли: %s.",
+		},
+		Nobody:  "Сегодня обо мне никто не вспомнил…",
+		Someone: "кто-то",
+		Sad:     "Мне было грустно в %s.",
+		NotSad:  "Я ни разу не грустил!",
+		Slept:   "Я проспал %d ч %d мин.",
+		NoSleep: "Я совсем не спал.",
+		Sick:    "Мне было плохо: здоровье падало до %d.",
+		Good:    "Это был чудесный день!",
+		Fine:    "День как день.",
+		Bad:     "Надеюсь, завтра будет лучше.",
+		And:     " и ",
+	},
+	"en": {
+		Opening: "Dear diary!",
+		Care: map[string]string{
+			"feed":     "I was fed by %s.",
+			"play":     "I played with %s.",
+			"clean":    "I was bathed by %s.",
+			"heal":     "I was treated by %s.",
+			"sleep":    "I was put to bed by %s.",
+			"wakeup":   "I was woken up by %s.",
+			"train":    "I learned tricks with %s.",
+			"trick":    "I showed tricks to %s.",
+			"activity": "I was sent out by %s.",
+		},
+		Nobody:  "Nobody remembered me today…",
+		Someone: "someone",
+		Sad:     "I felt sad at %s.",
+		NotSad:  "I was never sad!",
+		Slept:   "I slept for %d h %d min.",
+		NoSleep: "I didn't sleep at all.",
+		Sick:    "I felt unwell: my health dropped to %d.",
+		Good:    "It was a wonderful day!",
+		Fine:    "Just an ordinary day.",
+		Bad:     "I hope tomorrow will be better.",
+		And:     " and ",
+	},
+}
+
+// Diary Дневник питомца чата, от свежих записей к старым, на языке locale.
+func (s *Service) Diary(ctx context.Context, chatID int, locale string) ([]entity.DiaryEntry, error) {
+	s.logger.Trace().Msg("diary")
+
+	pet, err := s.repo.LoadPet(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPetNotFound) {
+			return nil, ErrPetNotFound
+		}
+
+		return nil, err
+	}
+
+	loc := s.chatLocation(ctx, chatID)
+
+	entries, err := s.repo.GetDiary(ctx, pet.ID, dayOf(time.Now().In(loc)), diaryPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entries[i].Text = renderDiary(entries[i].Facts, loc, locale)
+	}
+
+	return entries, nil
+}
+
+// recordDiaryAction Записывает в факты дня, кто и что делал с питомцем.
+func (s *Service) recordDiaryAction(ctx context.Context, ev ActionEvent, day time.Time) {
+	if !ev.Success || ev.After.ID == 0 {
+		return
+	}
+
+	entry, err := s.diaryEntry(ctx, ev.After.ID, day)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load diary")
+
+		return
+	}
+
+	if entry.Facts.Care[ev.Action.Name] == nil {
+		entry.Facts.Care[ev.Action.Name] = make(map[string]int)
+	}
+
+	entry.Facts.Care[ev.Action.Name][ev.Actor.Name]++
+	entry.Facts.MinHealth = min(entry.Facts.MinHealth, ev.After.Health)
+	entry.Facts.EndHappiness = ev.After.Happiness
+
+	s.saveDiaryEntry(ctx, ev.After.ID, entry)
+}
+
+// recordDiaryTick Отмечает по замерам монитора за день сон, грусть и самочувствие питомца.
+func (s *Service) recordDiaryTick(ctx context.Context, samples []TickEvent, day time.Time) {
+	last := samples[len(samples)-1]
+	if last.Pet.ID == 0 || last.Pet.State == entity.PetDead {
+		return
+	}
+
+	entry, err := s.diaryEntry(ctx, last.Pet.ID, day)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load diary")
+
+		return
+	}
+
+	for _, ev := range samples {
+		if ev.Pet.State != entity.PetDead {
+			s.addDiarySample(&entry.Facts, ev)
+		}
+	}
+
+	s.saveDiaryEntry(ctx, last.Pet.ID, entry)
+}
+
+// addDiarySample Добавляет в факты дня один замер.
+func (s *Service) addDiarySample(facts *entity.DiaryFacts, ev TickEvent) {
+	elapsed := s.updateInterval()
+	if !facts.LastSampleAt.IsZero() {
+		elapsed = min(ev.At.Sub(facts.LastSampleAt), maxSampleGap)
+	}
+
+	if ev.Pet.State == entity.PetSleeping {
+		facts.SleptMinutes += int(elapsed.Minutes())
+	}
+
+	sad := ev.Pet.Happiness <= 20
+	if sad && !facts.Sad {
+		facts.SadMoments = append(facts.SadMoments, ev.At)
+	}
+
+	facts.Sad = sad
+	facts.MinHealth = min(facts.MinHealth, ev.Pet.Health)
+	facts.EndHappiness = ev.Pet.Happiness
+	facts.LastSampleAt = ev.At
+}
+
+func (s *Service) diaryEntry(ctx context.Context, petID int, day time.Time) (entity.DiaryEntry, error) {
+	entries, err := s.repo.GetDiary(ctx, petID, day, 1)
+	if err != nil {
+		return entity.DiaryEntry{}, err
+	}
+
+	if len(entries) > 0 && sameDay(entries[0].Day, day) {
+		if entries[0].Facts.Care == nil {
+			entries[0].Facts.Care = make(map[string]map[string]int)
+		}
+
+		return entries[0], nil
+	}
+
+	return entity.DiaryEntry{
+		Day: day,
+		Facts: entity.DiaryFacts{
+			Care:         make(map[string]map[string]int),
+			MinHealth:    100,
+			EndHappiness: 100,
+		},
+	}, nil
+}
+
+func (s *Service) saveDiaryEntry(ctx context.Context, petID int, entry entity.DiaryEntry) {
+	err := s.repo.SaveDiaryEntry(ctx, petID, entry)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save diary")
+	}
+}
+
+// renderDiary Собирает текст записи из фактов дня по шаблонам языка.
+func renderDiary(facts entity.DiaryFacts, loc *time.Location, locale string) string {
+	l, ok := diaryLocales[normalizeLocale(locale)]
+	if !ok {
+		l = diaryLocales[defaultLocale]
+	}
+
+	lines := []string{l.Opening}
+
+	cared := false
+	for _, action := range actions {
+		phrase, ok := l.Care[action.Name]
+		if !ok || len(facts.Care[action.Name]) == 0 {
+			continue
+		}
+
+		cared = true
+		lines = append(lines, fmt.Sprintf(phrase, joinActors(facts.Care[action.Name], l)))
+	}
+
+	if !cared {
+		lines = append(lines, l.Nobody)
+	}
+
+	if len(facts.SadMoments) > 0 {
+		moments := make([]string, 0, len(facts.SadMoments))
+		for _, at := range facts.SadMoments {
+			moments = append(moments, at.In(loc).Format("15:04"))
+		}
+
+		lines = append(lines, fmt.Sprintf(l.Sad, joinWords(moments, l.And)))
+	} else {
+		lines = append(lines, l.NotSad)
+	}
+
+	if facts.SleptMinutes > 0 {
+		lines = append(lines, fmt.Sprintf(l.Slept, facts.SleptMinutes/60, facts.SleptMinutes%60))
+	} else {
+		lines = append(lines, l.NoSleep)
+	}
+
+	if facts.MinHealth <= 40 {
+		lines = append(lines, fmt.Sprintf(l.Sick, facts.MinHealth))
+	}
+
+	switch {
+	case facts.EndHappiness >= 70:
+		lines = append(lines, l.Good)
+	case facts.EndHappiness >= 40:
+		lines = append(lines, l.Fine)
+	default:
+		lines = append(lines, l.Bad)
+	}
+
+	return strings.Join(lines, " ")
+}
+
+// joinActors Перечень участников по алфавиту: «Аня (2), Петя и кто-то».
+func joinActors(counts map[string]int, l diaryLocale) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		title := name
+		if title == "" {
+			title = l.Someone
+		}
+
+		if counts[name] > 1 {
+			title = fmt.Sprintf("%s (%d)", title, counts[name])
+		}
+
+		parts = append(parts, title)
+	}
+
+	return joinWords(parts, l.And)
+}
+
+func joinWords(words []string, and string) string {
+	if len(words) <= 1 {
+		return strings.Join(words, "")
+	}
+
+	return strings.Join(words[:len(words)-1], ", ") + and + words[len(words)-1]
+}
+
+// normalizeLocale Сводит language_code Telegram («en-US», «ru») к поддерживаемому языку.
+func normalizeLocale(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	if _, ok := diaryLocales[lang]; ok {
+		return lang
+	}
+
+	return defaultLocale
+}
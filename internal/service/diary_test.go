@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+func TestRenderDiary(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name   string
+		facts  entity.DiaryFacts
+		locale string
+		want   string
+	}{
+		{
+			name:   "пустой день",
+			facts:  entity.DiaryFacts{MinHealth: 100, EndHappiness: 100},
+			locale: "ru",
+			want: "Дорогой дневник! Сегодня обо мне никто не вспомнил… Я ни разу не грустил! Я совсем не спал. " +
+				"Это был чудесный день!",
+		},
+		{
+			name: "уход, грусть по местному времени, сон и болезнь",
+			facts: entity.DiaryFacts{
+				Care: map[string]map[string]int{
+					"feed": {"Петя": 1, "Аня": 2, "": 1},
+					"play": {"Аня": 1},
+				},
+				SadMoments:   []time.Time{time.Date(2024, time.March, 1, 9, 5, 0, 0, time.UTC)},
+				SleptMinutes: 95,
+				MinHealth:    30,
+				EndHappiness: 50,
+			},
+			locale: "ru-RU",
+			want: "Дорогой дневник! Меня кормили: кто-то, Аня (2) и Петя. Со мной играли: Аня. Мне было грустно в 12:05. " +
+				"Я проспал 1 ч 35 мин. Мне было плохо: здоровье падало до 30. День как день.",
+		},
+		{
+			name: "английский",
+			facts: entity.DiaryFacts{
+				Care:         map[string]map[string]int{"clean": {"Bob": 1}},
+				SleptMinutes: 60,
+				MinHealth:    90,
+				EndHappiness: 10,
+			},
+			locale: "en-US",
+			want: "Dear diary! I was bathed by Bob. I was never sad! I slept for 1 h 0 min. " +
+				"I hope tomorrow will be better.",
+		},
+		{
+			name:   "неизвестный язык — русский",
+			facts:  entity.DiaryFacts{MinHealth: 100, EndHappiness: 100},
+			locale: "de",
+			want: "Дорогой дневник! Сегодня обо мне никто не вспомнил… Я ни разу не грустил! Я совсем не спал. " +
+				"Это был чудесный день!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := renderDiary(tt.facts, loc, tt.locale); got != tt.want {
+				t.Errorf("renderDiary() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		words []string
+		want  string
+	}{
+		{name: "пусто", want: ""},
+		{name: "одно", words: []string{"Аня"}, want: "Аня"},
+		{name: "два", words: []string{"Аня", "Петя"}, want: "Аня и Петя"},
+		{name: "три", words: []string{"Аня", "Петя", "Вася"}, want: "Аня, Петя и Вася"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := joinWords(tt.words, " и "); got != tt.want {
+				t.Errorf("joinWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
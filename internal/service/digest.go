@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// pendingDigest Сводка чата, которая копится до конца окна.
+type pendingDigest struct {
+	opened time.Time      // Когда сработало первое предупреждение окна.
+	worst  map[string]int // Тип предупреждения — самое низкое значение стата за окно.
+}
+
+// digestBuffer Копящиеся сводки чатов в режиме сводки. Живёт в памяти: после перезапуска несработавшая
+// сводка пропадает, а ещё не исправленные статы снова попадут в новую на ближайшем замере.
+type digestBuffer struct {
+	mu    sync.Mutex
+	chats map[int]*pendingDigest
+}
+
+func newDigestBuffer() *digestBuffer {
+	return &digestBuffer{chats: make(map[int]*pendingDigest)}
+}
+
+// add Добавляет в сводку чата сработавшие правила; первое открывает окно.
+func (b *digestBuffer) add(chatID int, rules []alertRule, pet *entity.Pet, now time.Time) {
+	if len(rules) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending, ok := b.chats[chatID]
+	if !ok {
+		pending = &pendingDigest{opened: now, worst: make(map[string]int, len(rules))}
+		b.chats[chatID] = pending
+	}
+
+	for _, rule := range rules {
+		value := rule.Stat(pet)
+		if worst, ok := pending.worst[rule.Type]; !ok || value < worst {
+			pending.worst[rule.Type] = value
+		}
+	}
+}
+
+// opened Когда открылось окно копящейся сводки чата; false, если сводки нет.
+func (b *digestBuffer) opened(chatID int) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending, ok := b.chats[chatID]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return pending.opened, true
+}
+
+// take Забирает сводку чата, если её окно длиной window закончилось к now.
+func (b *digestBuffer) take(chatID int, window time.Duration, now time.Time) (pendingDigest, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending, ok := b.chats[chatID]
+	if !ok || now.Before(pending.opened.Add(window)) {
+		return pendingDigest{}, false
+	}
+
+	delete(b.chats, chatID)
+
+	return *pending, true
+}
+
+// restore Возвращает недоставленную сводку, объединяя её с накопленным за время отправки.
+func (b *digestBuffer) restore(chatID int, digest pendingDigest) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending, ok := b.chats[chatID]
+	if !ok {
+		b.chats[chatID] = &digest
+
+		return
+	}
+
+	pending.opened = digest.opened
+
+	for alertType, value := range digest.worst {
+		if worst, ok := pending.worst[alertType]; !ok || value < worst {
+			pending.worst[alertType] = value
+		}
+	}
+}
+
+func (b *digestBuffer) drop(chatID int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.chats, chatID)
+}
+
+func (s *Service) digestWindow() time.Duration {
+	return time.Duration(s.cfg.DigestWindow) * time.Minute
+}
+
+// digestDue Когда отправится копящаяся сводка чата: конец окна, а если он пришёлся на тихие часы — их конец.
+func (s *Service) digestDue(chatID int, settings entity.NotifySettings, local time.Time) (time.Time, bool) {
+	opened, ok := s.digests.opened(chatID)
+	if !ok || settings.Mode != entity.AlertDigest {
+		return time.Time{}, false
+	}
+
+	due := opened.Add(s.digestWindow()).In(local.Location())
+	if due.Before(local) {
+		due = local
+	}
+
+	if quietHours(settings, due) {
+		due = quietEnd(settings, due)
+	}
+
+	return due, true
+}
+
+// sendDigestIfDue Отправляет сводку чата, если её окно закончилось. Недоставленная сводка остаётся копиться.
+func (s *Service) sendDigestIfDue(ctx context.Context, chatID int, pet *entity.Pet, now time.Time) {
+	digest, ok := s.digests.take(chatID, s.digestWindow(), now)
+	if !ok {
+		return
+	}
+
+	if !s.sendAlert(ctx, chatID, newDigest(digest, pet)) {
+		s.digests.restore(chatID, digest)
+	}
+}
+
+// newDigest Одна сводка по всем предупреждениям окна с самыми низкими значениями статов за него.
+func newDigest(digest pendingDigest, pet *entity.Pet) entity.Alert {
+	lines := []string{"⚠️ Питомцу нужна помощь:"}
+
+	var names []string
+
+	for _, rule := range alertRules {
+		worst, ok := digest.worst[rule.Type]
+		if !ok {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("• %s: %d", rule.Title, worst))
+		names = append(names, rule.Actions...)
+	}
+
+	return entity.Alert{Type: digestAlertType, Message: strings.Join(lines, "\n"), Actions: alertActions(pet, names)}
+}
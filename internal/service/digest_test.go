@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestService_SendWarnings_Digest(t *testing.T) {
+	t.Parallel()
+
+	const chatID = 1
+
+	noon := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	type tick struct {
+		at     time.Duration // Смещение от полудня.
+		pet    *entity.Pet
+		sent   int // Сколько сводок отправлено к концу замера.
+		failed bool
+	}
+
+	hungry := &entity.Pet{State: entity.PetAlive, Health: 100, Hunger: 15, Happiness: 100, Energy: 100, Hygiene: 100}
+	hungrier := &entity.Pet{State: entity.PetAlive, Health: 100, Hunger: 5, Happiness: 10, Energy: 100, Hygiene: 100}
+	fed := &entity.Pet{State: entity.PetAlive, Health: 100, Hunger: 90, Happiness: 100, Energy: 100, Hygiene: 100}
+
+	tests := []struct {
+		name      string
+		ticks     []tick
+		wantLines []string // Строки последней сводки.
+	}{
+		{
+			name: "копится до конца окна",
+			ticks: []tick{
+				{at: 0, pet: hungry},
+				{at: 30 * time.Minute, pet: hungrier},
+				{at: 50 * time.Minute, pet: hungry},
+				{at: time.Hour, pet: fed, sent: 1},
+			},
+			wantLines: []string{"• Сытость: 5", "• Счастье: 10"},
+		},
+		{
+			name: "без предупреждений окно не открывается",
+			ticks: []tick{
+				{at: 0, pet: fed},
+				{at: 2 * time.Hour, pet: fed},
+			},
+		},
+		{
+			name: "следующее окно открывает новое предупреждение",
+			ticks: []tick{
+				{at: 0, pet: hungry},
+				{at: time.Hour, pet: hungry, sent: 1},
+				{at: 90 * time.Minute, pet: fed, sent: 1},
+				{at: 2 * time.Hour, pet: hungrier, sent: 1},
+				{at: 3 * time.Hour, pet: fed, sent: 2},
+			},
+			wantLines: []string{"• Сытость: 5", "• Счастье: 10"},
+		},
+		{
+			name: "недоставленная сводка уходит на следующем замере",
+			ticks: []tick{
+				{at: 0, pet: hungry},
+				{at: time.Hour, pet: hungry, failed: true},
+				{at: 70 * time.Minute, pet: hungrier, sent: 1},
+			},
+			wantLines: []string{"• Сытость: 5", "• Счастье: 10"},
+		},
+		{
+			name: "тихие часы задерживают сводку",
+			ticks: []tick{
+				{at: 10 * time.Hour, pet: hungry},
+				{at: 11*time.Hour + 30*time.Minute, pet: hungry},
+				{at: 20 * time.Hour, pet: fed, sent: 1},
+			},
+			wantLines: []string{"• Сытость: 15"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := &recordingNotifier{messages: map[int][]string{}}
+			logger := zerolog.Nop()
+
+			settings := defaultNotifySettings()
+			settings.Mode = entity.AlertDigest
+
+			store := newMemoryRepo()
+			store.timezones[1] = "UTC"
+			s := NewService(&config.Configuration{DigestWindow: 60}, &logger, store, recorder)
+
+			for _, tick := range tt.ticks {
+				s.notifier = recorder
+				if tick.failed {
+					s.notifier = failingNotifier{}
+				}
+
+				s.sendWarnings(context.Background(), chatID, tick.pet, settings, triggeredAlerts(settings, tick.pet), noon.Add(tick.at))
+
+				if got := len(recorder.messages[chatID]); got != tick.sent {
+					t.Fatalf("after tick +%v sent %d digests, want %d", tick.at, got, tick.sent)
+				}
+			}
+
+			messages := recorder.messages[chatID]
+			if len(tt.wantLines) == 0 {
+				return
+			}
+
+			last := strings.Split(messages[len(messages)-1], "\n")[1:]
+			if strings.Join(last, "|") != strings.Join(tt.wantLines, "|") {
+				t.Errorf("digest = %q, want %q", last, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestService_DigestDue(t *testing.T) {
+	t.Parallel()
+
+	at := func(day, h, m int) time.Time { return time.Date(2024, time.March, day, h, m, 0, 0, time.UTC) }
+	pet := &entity.Pet{Hunger: 5}
+	hunger, _ := lookupAlertRule("hunger")
+
+	tests := []struct {
+		name   string
+		opened time.Time
+		now    time.Time
+		mode   entity.AlertMode
+		want   time.Time // Нулевое — сводки нет.
+	}{
+		{name: "конец окна", opened: at(1, 12, 10), now: at(1, 12, 30), mode: entity.AlertDigest, want: at(1, 13, 10)},
+		{name: "окно кончается в тихие часы", opened: at(1, 22, 30), now: at(1, 22, 40), mode: entity.AlertDigest, want: at(2, 8, 0)},
+		{name: "просроченная в тихие часы ждёт утра", opened: at(1, 21, 0), now: at(2, 1, 0), mode: entity.AlertDigest, want: at(2, 8, 0)},
+		{name: "в обычном режиме сводки нет", opened: at(1, 12, 10), now: at(1, 12, 30), mode: entity.AlertInstant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := zerolog.Nop()
+			store := newMemoryRepo()
+			store.timezones[1] = "UTC"
+			s := NewService(&config.Configuration{DigestWindow: 60}, &logger, store, nil)
+			s.digests.add(1, []alertRule{hunger}, pet, tt.opened)
+
+			settings := defaultNotifySettings()
+			settings.Mode = tt.mode
+
+			got, ok := s.digestDue(1, settings, tt.now)
+			if ok != !tt.want.IsZero() || !got.Equal(tt.want) {
+				t.Errorf("digestDue() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
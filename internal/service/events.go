@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// ActionEvent Действие пользователя с питомцем: состояние до и после, результат.
+type ActionEvent struct {
+	ChatID  int
+	Actor   entity.Actor
+	Action  Action
+	Params  ActionParams
+	Success bool
+	Before  *entity.Pet
+	After   *entity.Pet
+	Message string // Итог действия или причина отказа.
+	At      time.Time
+}
+
+// TickEvent Очередной замер питомца монитором.
+type TickEvent struct {
+	ChatID   int
+	Pet      *entity.Pet
+	Alerting bool // Сработало хотя бы одно предупреждение.
+	At       time.Time
+	Replayed []TickEvent // В ленивом режиме: восстановленные замеры с прошлого пробуждения, от старых к новым.
+}
+
+// samples Восстановленные замеры и сам замер, от старых к новым.
+func (ev TickEvent) samples() []TickEvent {
+	samples := make([]TickEvent, 0, len(ev.Replayed)+1)
+	samples = append(samples, ev.Replayed...)
+	ev.Replayed = nil
+
+	return append(samples, ev)
+}
+
+// splitByDay Делит замеры, идущие от старых к новым, по местным дням.
+func splitByDay(samples []TickEvent, loc *time.Location) [][]TickEvent {
+	var days [][]TickEvent
+
+	for i, sample := range samples {
+		if i == 0 || !dayOf(sample.At.In(loc)).Equal(dayOf(samples[i-1].At.In(loc))) {
+			days = append(days, nil)
+		}
+
+		days[len(days)-1] = append(days[len(days)-1], sample)
+	}
+
+	return days
+}
+
+// onAction Разносит действие по подсистемам: журнал, отчёт, серии, задания, дневник. Возвращает новости для пользователя.
+func (s *Service) onAction(ctx context.Context, ev ActionEvent) []string {
+	s.recordHistory(ctx, ev)
+
+	var notices []string
+
+	// Отчёт и серию двигает только состоявшийся уход, а не любое нажатие кнопки.
+	if ev.Success && ev.Action.Care != "" {
+		s.recordCareAction(ctx, ev.ChatID, ev.At)
+		notices = append(notices, s.recordStreaks(ctx, ev.ChatID, ev.Actor, ev.At)...)
+	}
+
+	day := dayOf(ev.At.In(s.chatLocation(ctx, ev.ChatID)))
+
+	s.recordDiaryAction(ctx, ev, day)
+
+	notices = append(notices, s.progressQuests(ctx, ev.ChatID, day, func(q *entity.Quest) bool {
+		return questActionProgress(q, ev)
+	})...)
+
+	return notices
+}
+
+// onTick Разносит замер монитора по подсистемам. Восстановленные замеры ленивого режима проходят через
+// отчёт, дневник и задания наравне с самим замером, по одной записи на день.
+func (s *Service) onTick(ctx context.Context, ev TickEvent) {
+	loc := s.chatLocation(ctx, ev.ChatID)
+
+	var notices []string
+
+	for _, samples := range splitByDay(ev.samples(), loc) {
+		day := dayOf(samples[0].At.In(loc))
+
+		s.trackCare(ctx, ev.ChatID, samples)
+		s.recordDiaryTick(ctx, samples, day)
+
+		notices = append(notices, s.progressQuests(ctx, ev.ChatID, day, func(q *entity.Quest) bool {
+			changed := false
+			for _, sample := range samples {
+				if q.Status != entity.QuestActive {
+					break
+				}
+
+				changed = questTickProgress(q, sample, loc) || changed
+			}
+
+			return changed
+		})...)
+	}
+
+	if ev.Pet.State != entity.PetDead {
+		s.announceEvents(ctx, ev.ChatID, ev.Pet.Events)
+	}
+
+	s.notify(ctx, ev.ChatID, notices...)
+}
+
+// onWakeUp Питомец проснулся сам, проспав slept, и снимок с пробуждением сохранён. Чтение и повторы сохранения
+// его не вызывают; подсистемы всё равно обрабатывают его идемпотентно.
+func (s *Service) onWakeUp(ctx context.Context, chatID int, slept time.Duration, at time.Time) {
+	day := dayOf(at.In(s.chatLocation(ctx, chatID)))
+
+	s.notify(ctx, chatID, s.progressQuests(ctx, chatID, day, func(q *entity.Quest) bool {
+		return questWakeProgress(q, slept)
+	})...)
+}
+
+// notify Рассылает сообщения во все каналы чата.
+func (s *Service) notify(ctx context.Context, chatID int, messages ...string) {
+	if len(messages) == 0 {
+		return
+	}
+
+	for _, notifier := range s.chatNotifiers(ctx, chatID) {
+		for _, message := range messages {
+			err := notifier.Notify(ctx, chatID, message)
+			if err != nil {
+				s.logger.Error().Err(err).Msgf("can't notify chat_id: %d", chatID)
+			}
+		}
+	}
+}
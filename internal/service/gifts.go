@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+var (
+	ErrRecipientNotFound = errors.New("получатель не найден: он должен хотя бы раз написать боту")
+	ErrSelfGift          = errors.New("нельзя дарить своему же питомцу")
+	ErrGiftNotFound      = errors.New("подарок не найден или уже разобран")
+	ErrGiftLimit         = errors.New("лимит подарков исчерпан, попробуйте завтра")
+	ErrGiftItemNotOwned  = errors.New("этого предмета нет в гардеробе или он надет")
+	ErrGiftItemOwned     = errors.New("такой предмет уже есть, подарок можно только отклонить")
+	ErrAnonymousGift     = errors.New("анонимно дарить нельзя")
+	ErrBadGift           = errors.New("укажите число монет или предмет гардероба")
+)
+
+// giftLimits Ограничения на подарки одного пользователя в сутки и на очередь подарков у получателя.
+var giftLimits = entity.GiftLimits{
+	PerDay:       5,
+	CoinsPerDay:  200,
+	PendingPerTo: 10,
+}
+
+// RememberUser Запоминает, в каком чате видели пользователя, чтобы ему можно было отправить подарок по @username.
+func (s *Service) RememberUser(ctx context.Context, chatID int, actor entity.Actor) {
+	if actor.Username == "" {
+		return
+	}
+
+	err := s.repo.SaveUsername(ctx, actor.Username, actor.ID, chatID, time.Now())
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save username")
+	}
+}
+
+// SendGift Дарит питомцу чата пользователя to монеты из личного кошелька отправителя или предмет из гардероба
+// его чата. what — число монет или идентификатор предмета.
+func (s *Service) SendGift(ctx context.Context, chatID int, sender entity.Actor, to string, what string) (entity.Gift, error) {
+	s.logger.Trace().Msg("send gift")
+
+	if sender.ID == chatStreakUser {
+		return entity.Gift{}, ErrAnonymousGift
+	}
+
+	gift, err := newGift(what)
+	if err != nil {
+		return entity.Gift{}, err
+	}
+
+	toChatID, err := s.repo.GetUsernameChat(ctx, strings.TrimPrefix(strings.TrimSpace(to), "@"))
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return entity.Gift{}, ErrRecipientNotFound
+		}
+
+		return entity.Gift{}, err
+	}
+
+	if toChatID == chatID {
+		return entity.Gift{}, ErrSelfGift
+	}
+
+	gift.FromChatID = chatID
+	gift.FromUserID = sender.ID
+	gift.FromName = sender.Name
+	gift.ToChatID = toChatID
+	gift.Status = entity.GiftPending
+	gift.CreatedAt = time.Now()
+
+	limits := giftLimits
+	limits.Since = dayOf(gift.CreatedAt.In(s.chatLocation(ctx, chatID)))
+
+	gift.ID, err = s.repo.SendGift(ctx, gift, limits)
+	if err != nil {
+		return entity.Gift{}, giftError(err)
+	}
+
+	s.notify(ctx, toChatID, fmt.Sprintf("🎁 %s дарит вашему питомцу: %s. Принять: /accept %d, отказаться: /decline %d",
+		gift.FromName, gift.Title, gift.ID, gift.ID))
+
+	return gift, nil
+}
+
+// GiftInbox Подарки, ждущие ответа чата.
+func (s *Service) GiftInbox(ctx context.Context, chatID int) ([]entity.Gift, error) {
+	s.logger.Trace().Msg("gift inbox")
+
+	return s.repo.GetGifts(ctx, chatID, entity.GiftPending)
+}
+
+// AcceptGift Принимает подарок: монеты уходят в кошелёк чата, предмет — в гардероб.
+func (s *Service) AcceptGift(ctx context.Context, chatID int, giftID int64) (entity.Gift, error) {
+	s.logger.Trace().Msg("accept gift")
+
+	return s.resolveGift(ctx, chatID, giftID, entity.GiftAccepted, "🎉 Подарок «%s» принят!")
+}
+
+// DeclineGift Отклоняет подарок и возвращает его отправителю.
+func (s *Service) DeclineGift(ctx context.Context, chatID int, giftID int64) (entity.Gift, error) {
+	s.logger.Trace().Msg("decline gift")
+
+	return s.resolveGift(ctx, chatID, giftID, entity.GiftDeclined, "↩️ Подарок «%s» отклонён и вернулся к вам.")
+}
+
+func (s *Service) resolveGift(ctx context.Context, chatID int, giftID int64, status entity.GiftStatus, notice string) (entity.Gift, error) {
+	gift, err := s.repo.ResolveGift(ctx, giftID, chatID, status, time.Now())
+	if err != nil {
+		return entity.Gift{}, giftError(err)
+	}
+
+	gift.Status = status
+
+	message := fmt.Sprintf(notice, gift.Title)
+	if gift.Refunded {
+		message = fmt.Sprintf("↩️ Подарок «%s» отклонён. Такой предмет у вас уже есть, поэтому вернулась его цена: %d 🪙.",
+			gift.Title, gift.Amount)
+	}
+
+	s.notify(ctx, gift.FromChatID, message)
+
+	return gift, nil
+}
+
+// newGift Разбирает, что дарят: число — монеты, иначе предмет каталога.
+func newGift(what string) (entity.Gift, error) {
+	what = strings.TrimSpace(what)
+
+	if amount, err := strconv.Atoi(what); err == nil {
+		if amount <= 0 {
+			return entity.Gift{}, ErrBadGift
+		}
+
+		return entity.Gift{Kind: entity.GiftCoins, Amount: amount, Title: fmt.Sprintf("%d 🪙", amount)}, nil
+	}
+
+	item, ok := LookupCosmetic(strings.ToLower(what))
+	if !ok {
+		return entity.Gift{}, ErrBadGift
+	}
+
+	return entity.Gift{Kind: entity.GiftItem, Item: item.ID, Slot: item.Slot, Amount: item.Price, Title: item.Emoji + " " + item.Title}, nil
+}
+
+func giftError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrGiftNotFound):
+		return ErrGiftNotFound
+	case errors.Is(err, repo.ErrGiftLimit):
+		return ErrGiftLimit
+	case errors.Is(err, repo.ErrNotEnoughCoins):
+		return ErrNotEnoughCoins
+	case errors.Is(err, repo.ErrItemNotOwned):
+		return ErrGiftItemNotOwned
+	case errors.Is(err, repo.ErrItemOwned):
+		return ErrGiftItemOwned
+	default:
+		return err
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNewGift(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		what    string
+		want    entity.Gift
+		wantErr error
+	}{
+		{
+			name: "монеты",
+			what: " 50 ",
+			want: entity.Gift{Kind: entity.GiftCoins, Amount: 50, Title: "50 🪙"},
+		},
+		{
+			name: "предмет в любом регистре",
+			what: "HAT_CAP",
+			want: entity.Gift{Kind: entity.GiftItem, Item: "hat_cap", Slot: entity.SlotHat, Amount: 30, Title: "🧢 Кепка"},
+		},
+		{name: "ноль монет", what: "0", wantErr: ErrBadGift},
+		{name: "отрицательная сумма", what: "-10", wantErr: ErrBadGift},
+		{name: "неизвестный предмет", what: "hat_tulip", wantErr: ErrBadGift},
+		{name: "пусто", what: "", wantErr: ErrBadGift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newGift(tt.what)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("newGift() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("newGift() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiftError(t *testing.T) {
+	t.Parallel()
+
+	other := errors.New("db is down")
+
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{err: repo.ErrGiftNotFound, want: ErrGiftNotFound},
+		{err: repo.ErrGiftLimit, want: ErrGiftLimit},
+		{err: repo.ErrNotEnoughCoins, want: ErrNotEnoughCoins},
+		{err: repo.ErrItemNotOwned, want: ErrGiftItemNotOwned},
+		{err: repo.ErrItemOwned, want: ErrGiftItemOwned},
+		{err: other, want: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			t.Parallel()
+
+			if got := giftError(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("giftError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_SendGift(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fromChat = 1
+		toChat   = 2
+	)
+
+	sender := entity.Actor{ID: 42, Name: "Аня"}
+
+	tests := []struct {
+		name    string
+		sender  entity.Actor
+		to      string
+		what    string
+		sent    int
+		wantErr error
+	}{
+		{name: "подарок по @username", sender: sender, to: "@Bob", what: "10"},
+		{name: "предмет", sender: sender, to: "@bob", what: "hat_cap"},
+		{name: "надетый предмет не дарится", sender: sender, to: "@bob", what: "hat_party", wantErr: ErrGiftItemNotOwned},
+		{name: "не хватает монет", sender: sender, to: "@bob", what: "150", wantErr: ErrNotEnoughCoins},
+		{name: "аноним", to: "@bob", what: "10", wantErr: ErrAnonymousGift},
+		{name: "непонятно что дарят", sender: sender, to: "@bob", what: "много", wantErr: ErrBadGift},
+		{name: "получатель не писал боту", sender: sender, to: "@alice", what: "10", wantErr: ErrRecipientNotFound},
+		{name: "себе", sender: sender, to: "@me", what: "10", wantErr: ErrSelfGift},
+		{name: "лимит", sender: sender, to: "@bob", what: "10", sent: giftLimits.PerDay, wantErr: ErrGiftLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newMemoryRepo()
+			store.timezones[fromChat] = "UTC"
+			store.usernames["bob"], store.usernames["me"] = toChat, fromChat
+			store.coins[walletKey{fromChat, sender.ID}] = 100
+			store.cosmetics[fromChat] = map[string]ownedCosmetic{
+				"hat_cap":   {slot: entity.SlotHat},
+				"hat_party": {slot: entity.SlotHat, equipped: true},
+			}
+
+			for range tt.sent {
+				store.gifts = append(store.gifts, entity.Gift{
+					FromUserID: sender.ID, ToChatID: 3, Kind: entity.GiftCoins, Status: entity.GiftAccepted, CreatedAt: time.Now(),
+				})
+			}
+			notifier := &recordingNotifier{messages: map[int][]string{}}
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, store, notifier)
+
+			gift, err := s.SendGift(context.Background(), fromChat, tt.sender, tt.to, tt.what)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendGift() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if len(notifier.messages) != 0 {
+					t.Errorf("notified on error: %v", notifier.messages)
+				}
+
+				if len(store.gifts) != tt.sent || store.coins[walletKey{fromChat, sender.ID}] != 100 || len(store.cosmetics[fromChat]) != 2 {
+					t.Errorf("failed gift changed the store: gifts = %d, coins = %d, items = %v",
+						len(store.gifts), store.coins[walletKey{fromChat, sender.ID}], store.cosmetics[fromChat])
+				}
+
+				return
+			}
+
+			// Подарок списан у отправителя и ждёт ответа получателя.
+			switch gift.Kind {
+			case entity.GiftCoins:
+				if coins := store.coins[walletKey{fromChat, sender.ID}]; coins != 100-gift.Amount {
+					t.Errorf("sender coins = %d, want %d", coins, 100-gift.Amount)
+				}
+			case entity.GiftItem:
+				if _, ok := store.cosmetics[fromChat][gift.Item]; ok {
+					t.Errorf("sender still owns %s", gift.Item)
+				}
+			}
+
+			if stored := store.gifts[gift.ID-1]; stored.Status != entity.GiftPending {
+				t.Errorf("stored gift = %+v, want pending", stored)
+			}
+
+			if len(notifier.messages[toChat]) != 1 {
+				t.Errorf("recipient notifications = %v, want 1", notifier.messages[toChat])
+			}
+
+			if gift.FromChatID != fromChat || gift.ToChatID != toChat || gift.FromUserID != sender.ID ||
+				gift.Status != entity.GiftPending || gift.ID == 0 {
+				t.Errorf("SendGift() = %+v", gift)
+			}
+
+			// Сутки для лимитов считаются по местному времени отправителя.
+			if !store.giftLimits.Since.Equal(dayOf(gift.CreatedAt.UTC())) {
+				t.Errorf("limits since %v, want start of %v", store.giftLimits.Since, gift.CreatedAt)
+			}
+
+			if time.Since(gift.CreatedAt) > time.Minute {
+				t.Errorf("CreatedAt = %v", gift.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestService_ResolveGift(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fromChat = 1
+		toChat   = 2
+	)
+
+	const senderID = 42
+
+	tests := []struct {
+		name       string
+		chatID     int
+		decline    bool
+		resolved   bool
+		want       entity.GiftStatus
+		wantWallet walletKey // Кому достались монеты подарка.
+		wantErr    error
+	}{
+		{name: "принять", chatID: toChat, want: entity.GiftAccepted, wantWallet: walletKey{toChat, chatStreakUser}},
+		{name: "отклонить", chatID: toChat, decline: true, want: entity.GiftDeclined, wantWallet: walletKey{fromChat, senderID}},
+		{name: "чужой подарок", chatID: fromChat, wantErr: ErrGiftNotFound},
+		{name: "уже разобран", chatID: toChat, resolved: true, wantErr: ErrGiftNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			status := entity.GiftPending
+			if tt.resolved {
+				status = entity.GiftAccepted
+			}
+
+			store := newMemoryRepo()
+			store.gifts = []entity.Gift{{
+				ID: 1, FromChatID: fromChat, FromUserID: senderID, ToChatID: toChat,
+				Kind: entity.GiftCoins, Amount: 10, Title: "10 🪙", Status: status,
+			}}
+			notifier := &recordingNotifier{messages: map[int][]string{}}
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, store, notifier)
+
+			resolve := s.AcceptGift
+			if tt.decline {
+				resolve = s.DeclineGift
+			}
+
+			gift, err := resolve(context.Background(), tt.chatID, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolve error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				if len(notifier.messages) != 0 {
+					t.Errorf("notified on error: %v", notifier.messages)
+				}
+
+				return
+			}
+
+			if gift.Status != tt.want || store.gifts[0].Status != tt.want {
+				t.Errorf("status = %v, stored %v, want %v", gift.Status, store.gifts[0].Status, tt.want)
+			}
+
+			if coins := store.coins[tt.wantWallet]; coins != 10 {
+				t.Errorf("coins of %+v = %d, want 10", tt.wantWallet, coins)
+			}
+
+			// Об ответе узнаёт отправитель.
+			if msgs := notifier.messages[fromChat]; len(msgs) != 1 || !strings.Contains(msgs[0], "10 🪙") {
+				t.Errorf("sender notifications = %v", msgs)
+			}
+		})
+	}
+}
+
+func TestService_ResolveGift_Item(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fromChat = 1
+		toChat   = 2
+		senderID = 42
+	)
+
+	tests := []struct {
+		name         string
+		decline      bool
+		senderOwns   bool
+		receiverOwns bool
+		wantErr      error
+		wantOwner    int  // Чат, у которого оказался предмет.
+		wantRefund   bool // Отправителю вернулась цена.
+	}{
+		{name: "принять", wantOwner: toChat},
+		{name: "отклонить", decline: true, wantOwner: fromChat},
+		{name: "принять то, что уже есть", receiverOwns: true, wantErr: ErrGiftItemOwned},
+		{name: "отклонить то, что отправитель купил снова", decline: true, senderOwns: true, wantOwner: fromChat, wantRefund: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newMemoryRepo()
+			store.gifts = []entity.Gift{{
+				ID: 1, FromChatID: fromChat, FromUserID: senderID, ToChatID: toChat, Kind: entity.GiftItem,
+				Item: "hat_cap", Slot: entity.SlotHat, Amount: 30, Title: "🧢 Кепка", Status: entity.GiftPending,
+			}}
+
+			if tt.senderOwns {
+				store.cosmetics[fromChat] = map[string]ownedCosmetic{"hat_cap": {slot: entity.SlotHat}}
+			}
+
+			if tt.receiverOwns {
+				store.cosmetics[toChat] = map[string]ownedCosmetic{"hat_cap": {slot: entity.SlotHat}}
+			}
+
+			notifier := &recordingNotifier{messages: map[int][]string{}}
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, store, notifier)
+
+			resolve := s.AcceptGift
+			if tt.decline {
+				resolve = s.DeclineGift
+			}
+
+			gift, err := resolve(context.Background(), toChat, 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolve error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				// Откат: подарок ждёт ответа, как и раньше.
+				if store.gifts[0].Status != entity.GiftPending {
+					t.Errorf("stored status = %v, want pending", store.gifts[0].Status)
+				}
+
+				return
+			}
+
+			if _, ok := store.cosmetics[tt.wantOwner]["hat_cap"]; !ok {
+				t.Errorf("chat %d does not own the item: %v", tt.wantOwner, store.cosmetics)
+			}
+
+			refund := 0
+			if tt.wantRefund {
+				refund = 30
+			}
+
+			if gift.Refunded != tt.wantRefund || store.coins[walletKey{fromChat, senderID}] != refund {
+				t.Errorf("refunded = %v, sender coins = %d, want %d", gift.Refunded, store.coins[walletKey{fromChat, senderID}], refund)
+			}
+
+			if msgs := notifier.messages[fromChat]; tt.wantRefund && (len(msgs) != 1 || !strings.Contains(msgs[0], "30 🪙")) {
+				t.Errorf("sender notifications = %v, want the refunded price", msgs)
+			}
+		})
+	}
+}
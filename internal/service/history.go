@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+const historyPageSize = 30
+
+// History Страница журнала действий с питомцем чата: записи старше курсора before, 0 — с самой свежей.
+func (s *Service) History(ctx context.Context, chatID int, before int64) (entity.HistoryPage, error) {
+	s.logger.Trace().Msg("history")
+
+	pet, err := s.repo.LoadPet(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPetNotFound) {
+			return entity.HistoryPage{}, ErrPetNotFound
+		}
+
+		return entity.HistoryPage{}, err
+	}
+
+	// Берём на запись больше страницы, чтобы знать, есть ли что читать дальше.
+	entries, err := s.repo.GetHistory(ctx, pet.ID, before, historyPageSize+1)
+	if err != nil {
+		return entity.HistoryPage{}, err
+	}
+
+	page := entity.HistoryPage{Entries: entries}
+	if len(entries) > historyPageSize {
+		page.Entries = entries[:historyPageSize]
+		page.Next = page.Entries[historyPageSize-1].ID
+	}
+
+	return page, nil
+}
+
+// recordHistory Дописывает действие в журнал, в том числе отказанное: в журнале видно и то, что не получилось.
+func (s *Service) recordHistory(ctx context.Context, ev ActionEvent) {
+	if ev.Before == nil || ev.Before.ID == 0 {
+		return
+	}
+
+	after := ev.Before
+	if ev.After != nil {
+		after = ev.After
+	}
+
+	err := s.repo.AddHistory(ctx, ev.ChatID, ev.Before.ID, entity.HistoryEntry{
+		Actor:   ev.Actor,
+		Action:  ev.Action.Name,
+		Params:  ev.Params,
+		Before:  ev.Before.Stats(),
+		After:   after.Stats(),
+		Success: ev.Success,
+		Message: ev.Message,
+		At:      ev.At,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save history")
+	}
+}
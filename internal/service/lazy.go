@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+	"gocha/pkg/gocha"
+)
+
+// lazyHorizon Как далеко вперёд ищем пересечение порога. Если до горизонта ничего не случится, чат всё равно
+// проснётся: так доходят ежедневные подсистемы и копится дрейф расписания.
+const lazyHorizon = 6 * time.Hour
+
+// nextWakeUp Когда в ленивом режиме будить чат: первая минута, в которую сработает новое предупреждение,
+// питомец умрёт или закончится занятие, а если предупреждение ждёт конца тихих часов — их конец; в режиме
+// сводки — ещё и когда уйдёт копящаяся сводка. Поиск
+// не заходит за горизонт, за местную полночь — с неё начинаются и кончаются события календаря, меняющие
+// скорость убывания, — и за границы часов заданий на удержание стата.
+func (s *Service) nextWakeUp(ctx context.Context, chatID int, pet *entity.Pet, now time.Time) time.Time {
+	local := now.In(s.chatLocation(ctx, chatID))
+	midnight := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+
+	horizon := now.Add(lazyHorizon)
+	if midnight.Before(horizon) {
+		horizon = midnight
+	}
+
+	if boundary, ok := keepStatBoundary(local); ok && boundary.Before(horizon) {
+		horizon = boundary
+	}
+
+	extPet := PetEntityToGocha(pet)
+	extPet.DecayPercent = decayPercent(s.chatEvents(ctx, chatID, pet.Name, pet.CreatedAt, now))
+	extPet.Advance(now)
+
+	if extPet.IsDead() {
+		return horizon
+	}
+
+	settings := s.notifySettings(ctx, chatID)
+
+	// Уже сработавшие предупреждения не будят: о них сообщили на прошлом замере. Если же сообщить
+	// помешали тихие часы, будим к их концу.
+	alerting := make(map[string]bool, len(alertRules))
+	for _, rule := range triggeredAlerts(settings, petStats(extPet)) {
+		alerting[rule.Type] = true
+	}
+
+	if len(alerting) > 0 && quietHours(settings, local) {
+		if end := quietEnd(settings, local); end.Before(horizon) {
+			horizon = end
+		}
+	}
+
+	if due, ok := s.digestDue(chatID, settings, local); ok && due.After(local) && due.Before(horizon) {
+		horizon = due
+	}
+
+	activity := extPet.Activity
+
+	for at := extPet.LastUpdated.Add(time.Minute); !at.After(horizon); at = at.Add(time.Minute) {
+		extPet.Advance(at)
+
+		if extPet.IsDead() || extPet.Activity != activity {
+			return at
+		}
+
+		for _, rule := range triggeredAlerts(settings, petStats(extPet)) {
+			if !alerting[rule.Type] {
+				return at
+			}
+		}
+	}
+
+	return horizon
+}
+
+// replayTicks Восстанавливает замеры, которых в ленивом режиме не было: от снимка питомца до now с шагом
+// интервала обновления, но не раньше lazyHorizon назад. Сам замер в now сюда не входит. Предупреждения
+// по восстановленным замерам не отправлялись, поэтому Alerting у них не выставлен.
+func (s *Service) replayTicks(ctx context.Context, chatID int, pet *entity.Pet, now time.Time) []TickEvent {
+	step := s.updateInterval()
+	if step <= 0 {
+		return nil
+	}
+
+	from := pet.LastUpdated
+	if earliest := now.Add(-lazyHorizon); from.Before(earliest) {
+		from = earliest
+	}
+
+	extPet := PetEntityToGocha(pet)
+	extPet.DecayPercent = decayPercent(s.chatEvents(ctx, chatID, pet.Name, pet.CreatedAt, now))
+
+	var ticks []TickEvent
+
+	for at := from.Add(step); at.Before(now); at = at.Add(step) {
+		extPet.Advance(at)
+
+		ticks = append(ticks, TickEvent{ChatID: chatID, Pet: withPetMeta(GochaToPetEntity(extPet), pet), At: at})
+
+		if extPet.IsDead() {
+			break
+		}
+	}
+
+	return ticks
+}
+
+// lazyQueue Чаты, которым нужно пересчитать время пробуждения. Действие пользователя только отмечает чат,
+// пересчёт идёт в фоне, а отметки одного чата до пересчёта схлопываются в одну.
+type lazyQueue struct {
+	mu      sync.Mutex
+	pending map[int]struct{}
+	wake    chan struct{}
+}
+
+func newLazyQueue() *lazyQueue {
+	return &lazyQueue{pending: make(map[int]struct{}), wake: make(chan struct{}, 1)}
+}
+
+// Add Отмечает чат для пересчёта; не блокирует.
+func (q *lazyQueue) Add(chatID int) {
+	q.mu.Lock()
+	q.pending[chatID] = struct{}{}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take Забирает все отмеченные чаты.
+func (q *lazyQueue) take() []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	chatIDs := make([]int, 0, len(q.pending))
+	for chatID := range q.pending {
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	clear(q.pending)
+
+	return chatIDs
+}
+
+// RunLazyReschedules В ленивом режиме пересчитывает время пробуждения чатов, отмеченных после сохранения
+// питомца. Блокирует до отмены контекста.
+func (s *Service) RunLazyReschedules(ctx context.Context) {
+	if !s.cfg.LazyStats {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.reschedules.wake:
+		}
+
+		for _, chatID := range s.reschedules.take() {
+			s.rescheduleLazy(ctx, chatID)
+		}
+	}
+}
+
+// rescheduleLazy Пересчитывает, когда будить чат, по сохранённому снимку питомца.
+func (s *Service) rescheduleLazy(ctx context.Context, chatID int) {
+	pet, err := s.repo.LoadPet(ctx, chatID)
+	if err != nil {
+		if !errors.Is(err, repo.ErrPetNotFound) {
+			s.logger.Error().Err(err).Msg("can't load pet to reschedule")
+		}
+
+		return
+	}
+
+	s.scheduler.Schedule(chatID, s.nextWakeUp(ctx, chatID, pet, time.Now()))
+}
+
+// petStats Статы питомца для проверки правил предупреждений без полной конвертации.
+func petStats(p *gocha.Pet) *entity.Pet {
+	return &entity.Pet{
+		Health:    p.Health,
+		Hunger:    p.Hunger,
+		Happiness: p.Happiness,
+		Energy:    p.Energy,
+		Hygiene:   p.Hygiene,
+		State:     entity.State(p.State),
+	}
+}
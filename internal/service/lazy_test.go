@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+	"gocha/pkg/gocha"
+
+	"github.com/rs/zerolog"
+)
+
+// frozenCalendar Круглогодичное событие, при котором статы не убывают.
+var frozenCalendar = []entity.CalendarEvent{{
+	ID:    "frozen",
+	Start: "01-01",
+	End:   "12-31",
+	Decay: entity.DecayModifiers{Hunger: percent(0), Energy: percent(0), Hygiene: percent(0), Happiness: percent(0)},
+}}
+
+func newLazyService(t *testing.T, store *memoryRepo, frozen bool) *Service {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{UpdateInterval: 10, LazyStats: true}, &logger, store, nil)
+
+	s.calendar = nil
+	if frozen {
+		s.calendar = frozenCalendar
+	}
+
+	return s
+}
+
+// lazyPet Бодрый питомец, прожитый до at.
+func lazyPet(at time.Time, change func(p *entity.Pet)) *entity.Pet {
+	pet := GochaToPetEntity(gocha.NewPet("Гоча"))
+	pet.ID = 1
+	pet.CreatedAt = at.AddDate(0, 0, -1)
+	pet.LastUpdated = at
+	pet.Health, pet.Hunger, pet.Happiness, pet.Energy, pet.Hygiene = 100, 100, 100, 100, 100
+
+	if change != nil {
+		change(pet)
+	}
+
+	return pet
+}
+
+func TestService_NextWakeUp(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(h, m int) time.Time { return time.Date(2024, time.March, 1, h, m, 0, 0, loc) }
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		frozen bool // Статы не убывают: пересекать пороги нечему.
+		change func(p *entity.Pet)
+		want   time.Time // Нулевое — первое пересечение порога, проверяется симуляцией.
+	}{
+		{
+			name:   "первое пересечение порога",
+			now:    at(12, 0),
+			change: func(p *entity.Pet) { p.Hunger = defaultAlertThreshold + 2 },
+		},
+		{
+			name:   "до горизонта ничего не случится",
+			now:    at(10, 0),
+			frozen: true,
+			want:   at(16, 0),
+		},
+		{
+			name:   "не дальше местной полуночи",
+			now:    at(22, 30),
+			frozen: true,
+			want:   at(24, 0),
+		},
+		{
+			name:   "граница часов задания на удержание стата",
+			now:    at(8, 30),
+			frozen: true,
+			want:   at(9, 0),
+		},
+		{
+			name:   "сработавшее в тихие часы предупреждение ждёт их конца",
+			now:    at(4, 0),
+			frozen: true,
+			change: func(p *entity.Pet) { p.Hunger = defaultAlertThreshold },
+			want:   at(8, 0),
+		},
+		{
+			name:   "мёртвый питомец спит до горизонта",
+			now:    at(12, 0),
+			change: func(p *entity.Pet) { p.Health, p.State = 0, entity.PetDead },
+			want:   at(18, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pet := lazyPet(tt.now, tt.change)
+			store := newMemoryRepo()
+			store.timezones[1] = loc.String()
+			s := newLazyService(t, store, tt.frozen)
+
+			got := s.nextWakeUp(context.Background(), 1, pet, tt.now)
+
+			if !tt.want.IsZero() {
+				if !got.Equal(tt.want) {
+					t.Errorf("nextWakeUp() = %v, want %v", got.In(loc), tt.want)
+				}
+
+				return
+			}
+
+			if !got.After(tt.now) || !got.Before(tt.now.Add(lazyHorizon)) {
+				t.Fatalf("nextWakeUp() = %v, want a threshold crossing after %v", got.In(loc), tt.now)
+			}
+
+			// Порог пересекается ровно в got: минутой раньше предупреждения ещё нет.
+			settings := defaultNotifySettings()
+
+			for _, check := range []struct {
+				at       time.Time
+				alerting bool
+			}{{got.Add(-time.Minute), false}, {got, true}} {
+				extPet := PetEntityToGocha(pet)
+				extPet.Advance(check.at)
+
+				if alerting := len(triggeredAlerts(settings, petStats(extPet))) > 0; alerting != check.alerting {
+					t.Errorf("alerting at %v = %v, want %v", check.at.In(loc), alerting, check.alerting)
+				}
+			}
+		})
+	}
+}
+
+func TestService_LoadPet_Lazy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		lazy      bool
+		elapsed   time.Duration
+		wantAhead bool
+	}{
+		{name: "ленивый режим доживает снимок", lazy: true, elapsed: 2 * time.Hour, wantAhead: true},
+		{name: "обычный режим отдаёт снимок", lazy: false, elapsed: 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			saved := time.Now().Add(-tt.elapsed)
+			store := newMemoryRepo()
+			store.timezones[1] = "UTC"
+			store.putPet(1, lazyPet(saved, nil))
+			s := newLazyService(t, store, false)
+			s.cfg.LazyStats = tt.lazy
+
+			pet, err := s.LoadPet(context.Background(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ahead := time.Since(pet.LastUpdated) < time.Minute
+			if ahead != tt.wantAhead {
+				t.Errorf("LoadPet() last updated %v ago, want advanced = %v", time.Since(pet.LastUpdated), tt.wantAhead)
+			}
+
+			if tt.wantAhead && pet.Hunger >= 100 {
+				t.Errorf("LoadPet() hunger = %d, want decayed", pet.Hunger)
+			}
+
+			// Чтение не пишет снимок: его сохранит действие или пробуждение.
+			if !store.pet(1).LastUpdated.Equal(saved) {
+				t.Errorf("stored snapshot changed: %v", store.pet(1).LastUpdated)
+			}
+		})
+	}
+}
+
+func TestService_ReplayTicks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		saved     time.Time
+		wantFirst time.Time
+		wantCount int
+	}{
+		{name: "замеры с шагом интервала до now", saved: now.Add(-time.Hour), wantFirst: now.Add(-50 * time.Minute), wantCount: 5},
+		{name: "не дальше горизонта", saved: now.Add(-10 * time.Hour), wantFirst: now.Add(-lazyHorizon + 10*time.Minute), wantCount: 35},
+		{name: "меньше интервала — нечего восстанавливать", saved: now.Add(-5 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Без убывания питомец доживает до конца промежутка, а не умирает посередине.
+			store := newMemoryRepo()
+			store.timezones[1] = "UTC"
+			s := newLazyService(t, store, true)
+
+			ticks := s.replayTicks(context.Background(), 1, lazyPet(tt.saved, nil), now)
+			if len(ticks) != tt.wantCount {
+				t.Fatalf("replayTicks() = %d ticks, want %d", len(ticks), tt.wantCount)
+			}
+
+			for i, tick := range ticks {
+				if i == 0 && !tick.At.Equal(tt.wantFirst) {
+					t.Errorf("first tick at %v, want %v", tick.At, tt.wantFirst)
+				}
+
+				if i > 0 && tick.At.Sub(ticks[i-1].At) != 10*time.Minute {
+					t.Errorf("tick %d at %v after %v", i, tick.At, ticks[i-1].At)
+				}
+
+				if tick.Alerting || tick.Pet.ID != 1 {
+					t.Errorf("tick %d = %+v", i, tick)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitByDay(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	tick := func(h int) TickEvent { return TickEvent{At: time.Date(2024, time.March, 1, h, 0, 0, 0, time.UTC)} }
+
+	// 21:00 и 22:00 UTC — уже 2 марта по местному времени.
+	days := splitByDay([]TickEvent{tick(20), tick(21), tick(22)}, loc)
+	if len(days) != 2 || len(days[0]) != 1 || len(days[1]) != 2 {
+		t.Errorf("splitByDay() = %v", days)
+	}
+}
+
+func TestService_AddCareSample_Replayed(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	store := newMemoryRepo()
+	store.timezones[1] = "UTC"
+	s := newLazyService(t, store, false)
+
+	// Два часа сна чата восстановлены замерами: отчёт видит их целиком, а не один maxSampleGap.
+	report := entity.CareReport{LastSampleAt: start}
+	for at := start.Add(10 * time.Minute); !at.After(start.Add(2 * time.Hour)); at = at.Add(10 * time.Minute) {
+		s.addCareSample(&report, TickEvent{Pet: lazyPet(at, nil), At: at})
+	}
+
+	if report.ObservedMinutes != 120 {
+		t.Errorf("ObservedMinutes = %d, want 120", report.ObservedMinutes)
+	}
+}
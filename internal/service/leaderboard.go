@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+const (
+	leaderboardSize     = 10
+	leaderboardRefresh  = 10 * time.Minute
+	leaderboardCareDays = 7
+)
+
+// leaderboardKind Вид рейтинга и как его подписать.
+type leaderboardKind struct {
+	Category entity.LeaderboardCategory
+	Title    string
+	Unit     string
+}
+
+// globalLeaderboards Рейтинги питомцев всех чатов.
+var globalLeaderboards = []leaderboardKind{
+	{Category: entity.LeaderboardAge, Title: "🎂 Самые старые питомцы", Unit: "дн."},
+	{Category: entity.LeaderboardLevel, Title: "⭐ Самый высокий уровень", Unit: "ур."},
+	{Category: entity.LeaderboardCare, Title: "🩺 Лучший уход за неделю", Unit: "балл."},
+	{Category: entity.LeaderboardStreak, Title: "🔥 Самые длинные серии", Unit: "дн."},
+}
+
+// chatLeaderboards Рейтинги участников одного чата.
+var chatLeaderboards = []leaderboardKind{
+	{Category: entity.LeaderboardCare, Title: "🩺 Больше всех заботились за неделю", Unit: "дейст."},
+	{Category: entity.LeaderboardStreak, Title: "🔥 Самые длинные серии", Unit: "дн."},
+}
+
+// leaderboardCache Посчитанные рейтинги: общие обновляются раз в leaderboardRefresh, рейтинги чата живут столько же.
+type leaderboardCache struct {
+	mu       sync.Mutex
+	global   []entity.Leaderboard
+	globalAt time.Time
+	chats    map[int]cachedLeaderboards
+}
+
+type cachedLeaderboards struct {
+	boards []entity.Leaderboard
+	at     time.Time
+}
+
+// Leaderboards Общие рейтинги и рейтинги участников чата; свой чат и сам actor отмечены.
+func (s *Service) Leaderboards(ctx context.Context, chatID int, actor entity.Actor) (entity.Leaderboards, error) {
+	s.logger.Trace().Msg("leaderboards")
+
+	global, updatedAt, err := s.globalLeaderboards(ctx)
+	if err != nil {
+		return entity.Leaderboards{}, err
+	}
+
+	chat, err := s.chatLeaderboards(ctx, chatID)
+	if err != nil {
+		return entity.Leaderboards{}, err
+	}
+
+	hidden, err := s.repo.GetTopHidden(ctx, chatID)
+	if err != nil {
+		return entity.Leaderboards{}, err
+	}
+
+	return entity.Leaderboards{
+		Global: markOwn(global, func(e entity.LeaderboardEntry) bool {
+			return e.ChatID == chatID
+		}),
+		Chat: markOwn(chat, func(e entity.LeaderboardEntry) bool {
+			return actor.ID != 0 && e.UserID == actor.ID
+		}),
+		Hidden:    hidden,
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// SetTopHidden Скрывает чат из общих рейтингов или возвращает его туда. Действует сразу, не дожидаясь обновления.
+func (s *Service) SetTopHidden(ctx context.Context, chatID int, hidden bool) error {
+	s.logger.Trace().Msg("set top hidden")
+
+	err := s.repo.SetTopHidden(ctx, chatID, hidden)
+	if err != nil {
+		return err
+	}
+
+	s.leaderboards.mu.Lock()
+	s.leaderboards.globalAt = time.Time{}
+	s.leaderboards.mu.Unlock()
+
+	return nil
+}
+
+// RunLeaderboards Пересчитывает общие рейтинги раз в leaderboardRefresh и забывает устаревшие рейтинги чатов.
+// Блокирует до отмены контекста.
+func (s *Service) RunLeaderboards(ctx context.Context) {
+	ticker := time.NewTicker(leaderboardRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.refreshGlobalLeaderboards(ctx)
+			if err != nil {
+				s.logger.Error().Err(err).Msg("can't refresh leaderboards")
+			}
+
+			s.leaderboards.dropStale(time.Now())
+		}
+	}
+}
+
+// dropStale Забывает рейтинги чатов, посчитанные leaderboardRefresh назад и раньше.
+func (c *leaderboardCache) dropStale(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for chatID, cached := range c.chats {
+		if now.Sub(cached.at) >= leaderboardRefresh {
+			delete(c.chats, chatID)
+		}
+	}
+}
+
+// globalLeaderboards Общие рейтинги из кэша; если кэш пуст или сброшен — считаются сразу.
+func (s *Service) globalLeaderboards(ctx context.Context) ([]entity.Leaderboard, time.Time, error) {
+	s.leaderboards.mu.Lock()
+	global, at := s.leaderboards.global, s.leaderboards.globalAt
+	s.leaderboards.mu.Unlock()
+
+	if time.Since(at) < leaderboardRefresh {
+		return global, at, nil
+	}
+
+	err := s.refreshGlobalLeaderboards(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	s.leaderboards.mu.Lock()
+	defer s.leaderboards.mu.Unlock()
+
+	return s.leaderboards.global, s.leaderboards.globalAt, nil
+}
+
+func (s *Service) refreshGlobalLeaderboards(ctx context.Context) error {
+	since := dayOf(time.Now()).AddDate(0, 0, -leaderboardCareDays)
+	boards := make([]entity.Leaderboard, 0, len(globalLeaderboards))
+
+	for _, kind := range globalLeaderboards {
+		entries, err := s.repo.GetLeaderboard(ctx, kind.Category, since, leaderboardSize)
+		if err != nil {
+			return err
+		}
+
+		boards = append(boards, newLeaderboard(kind, entries))
+	}
+
+	s.leaderboards.mu.Lock()
+	s.leaderboards.global = boards
+	s.leaderboards.globalAt = time.Now()
+	s.leaderboards.mu.Unlock()
+
+	return nil
+}
+
+// chatLeaderboards Рейтинги участников чата из кэша или свежие.
+func (s *Service) chatLeaderboards(ctx context.Context, chatID int) ([]entity.Leaderboard, error) {
+	s.leaderboards.mu.Lock()
+	cached, ok := s.leaderboards.chats[chatID]
+	s.leaderboards.mu.Unlock()
+
+	if ok && time.Since(cached.at) < leaderboardRefresh {
+		return cached.boards, nil
+	}
+
+	since := time.Now().AddDate(0, 0, -leaderboardCareDays)
+	boards := make([]entity.Leaderboard, 0, len(chatLeaderboards))
+
+	for _, kind := range chatLeaderboards {
+		entries, err := s.repo.GetChatLeaderboard(ctx, chatID, kind.Category, careActionNames(), since, leaderboardSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range entries {
+			if entries[i].Name == "" {
+				entries[i].Name = "кто-то"
+			}
+		}
+
+		boards = append(boards, newLeaderboard(kind, entries))
+	}
+
+	s.leaderboards.mu.Lock()
+	s.leaderboards.chats[chatID] = cachedLeaderboards{boards: boards, at: time.Now()}
+	s.leaderboards.mu.Unlock()
+
+	return boards, nil
+}
+
+// newLeaderboard Расставляет места: равные значения делят место, следующее место пропускается.
+func newLeaderboard(kind leaderboardKind, entries []entity.LeaderboardEntry) entity.Leaderboard {
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Value == entries[i-1].Value {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	return entity.Leaderboard{
+		Category: kind.Category,
+		Title:    kind.Title,
+		Unit:     kind.Unit,
+		Entries:  entries,
+	}
+}
+
+// markOwn Копии рейтингов с отметкой своих мест: кэш общий для всех чатов и не меняется.
+func markOwn(boards []entity.Leaderboard, own func(e entity.LeaderboardEntry) bool) []entity.Leaderboard {
+	marked := make([]entity.Leaderboard, len(boards))
+
+	for i, board := range boards {
+		entries := make([]entity.LeaderboardEntry, len(board.Entries))
+		for j, e := range board.Entries {
+			e.Own = own(e)
+			entries[j] = e
+		}
+
+		board.Entries = entries
+		marked[i] = board
+	}
+
+	return marked
+}
+
+// careActionNames Действия ухода, которые считаются в рейтинге заботы.
+func careActionNames() []string {
+	names := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if action.Care != "" {
+			names = append(names, action.Name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestService_Leaderboards_Cache(t *testing.T) {
+	t.Parallel()
+
+	// Запросов к базе за один подсчёт: по одному на вид рейтинга.
+	globalQueries, chatQueries := len(globalLeaderboards), len(chatLeaderboards)
+
+	tests := []struct {
+		name       string
+		age        time.Duration // Сколько лет кэшу перед вторым запросом.
+		hide       bool
+		wantGlobal int
+		wantChat   int
+	}{
+		{name: "свежий кэш не пересчитывается", age: leaderboardRefresh - time.Minute, wantGlobal: globalQueries, wantChat: chatQueries},
+		{name: "устаревший кэш пересчитывается", age: leaderboardRefresh, wantGlobal: 2 * globalQueries, wantChat: 2 * chatQueries},
+		{name: "скрытие чата сбрасывает общие рейтинги", hide: true, wantGlobal: 2 * globalQueries, wantChat: chatQueries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newMemoryRepo()
+			store.leaderboard = []entity.LeaderboardEntry{{ChatID: 1, Value: 10}, {ChatID: 2, Value: 5}}
+			store.chatLeaderboards[1] = []entity.LeaderboardEntry{{UserID: 7, Value: 3}}
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, store, nil)
+			ctx := context.Background()
+
+			_, err := s.Leaderboards(ctx, 1, entity.Actor{ID: 7})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s.leaderboards.mu.Lock()
+			s.leaderboards.globalAt = s.leaderboards.globalAt.Add(-tt.age)
+			cached := s.leaderboards.chats[1]
+			cached.at = cached.at.Add(-tt.age)
+			s.leaderboards.chats[1] = cached
+			s.leaderboards.mu.Unlock()
+
+			if tt.hide {
+				err = s.SetTopHidden(ctx, 1, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			boards, err := s.Leaderboards(ctx, 1, entity.Actor{ID: 7})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if store.leaderboardReads != tt.wantGlobal || store.chatLeaderboardReads[1] != tt.wantChat {
+				t.Errorf("queries: global = %d, chat = %d, want %d, %d",
+					store.leaderboardReads, store.chatLeaderboardReads[1], tt.wantGlobal, tt.wantChat)
+			}
+
+			// Скрытый чат пропадает из общих рейтингов, но свой рейтинг чата остаётся.
+			if boards.Hidden != tt.hide || boards.Global[0].Entries[0].Own == tt.hide || !boards.Chat[0].Entries[0].Own {
+				t.Errorf("Leaderboards() = %+v", boards)
+			}
+		})
+	}
+}
+
+func TestLeaderboardCache_DropStale(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cache := &leaderboardCache{chats: map[int]cachedLeaderboards{
+		1: {at: now.Add(-leaderboardRefresh - time.Second)},
+		2: {at: now.Add(-leaderboardRefresh)},
+		3: {at: now.Add(-leaderboardRefresh + time.Second)},
+	}}
+
+	cache.dropStale(now)
+
+	if _, ok := cache.chats[3]; len(cache.chats) != 1 || !ok {
+		t.Errorf("chats after dropStale = %v, want only the fresh one", cache.chats)
+	}
+}
+
+func TestNewLeaderboard_Ranks(t *testing.T) {
+	t.Parallel()
+
+	board := newLeaderboard(leaderboardKind{}, []entity.LeaderboardEntry{{Value: 9}, {Value: 7}, {Value: 7}, {Value: 3}})
+
+	want := []int{1, 2, 2, 4}
+	for i, e := range board.Entries {
+		if e.Rank != want[i] {
+			t.Errorf("entry %d rank = %d, want %d", i, e.Rank, want[i])
+		}
+	}
+}
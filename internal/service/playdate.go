@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+	"gocha/pkg/gocha"
+)
+
+var (
+	ErrInviteNotFound  = errors.New("приглашение не найдено или истекло")
+	ErrSelfPlaydate    = errors.New("нельзя пригласить в гости самого себя")
+	ErrPlaydateDenied  = errors.New("встреча невозможна")
+	ErrPlaydateTooSoon = errors.New("питомцы недавно виделись")
+)
+
+const (
+	inviteTTL        = 24 * time.Hour
+	playdateCooldown = time.Hour // Не чаще одной встречи в час для каждого питомца.
+	playdateEnergy   = 10        // Сколько энергии тратит встреча.
+	playdateMinBonus = 5
+	playdateMaxBonus = 25
+)
+
+// compatibility Совместимость характеров в процентах; пара не упорядочена.
+var compatibility = map[[2]string]int{
+	{entity.TraitPlayful, entity.TraitPlayful}: 90,
+	{entity.TraitPlayful, entity.TraitLazy}:    40,
+	{entity.TraitPlayful, entity.TraitGlutton}: 70,
+	{entity.TraitPlayful, entity.TraitGrumpy}:  30,
+	{entity.TraitLazy, entity.TraitLazy}:       80,
+	{entity.TraitLazy, entity.TraitGlutton}:    75,
+	{entity.TraitLazy, entity.TraitGrumpy}:     60,
+	{entity.TraitGlutton, entity.TraitGlutton}: 65,
+	{entity.TraitGlutton, entity.TraitGrumpy}:  50,
+	{entity.TraitGrumpy, entity.TraitGrumpy}:   45,
+}
+
+// InvitePlaydate Создаёт одноразовый код приглашения в гости к питомцу чата.
+func (s *Service) InvitePlaydate(ctx context.Context, chatID int) (entity.PlaydateInvite, error) {
+	s.logger.Trace().Msg("invite playdate")
+
+	_, err := s.LoadPet(ctx, chatID)
+	if err != nil {
+		return entity.PlaydateInvite{}, err
+	}
+
+	code, err := inviteCode()
+	if err != nil {
+		return entity.PlaydateInvite{}, err
+	}
+
+	invite := entity.PlaydateInvite{Code: code, ExpiresAt: time.Now().Add(inviteTTL)}
+
+	err = s.repo.SavePlaydateInvite(ctx, chatID, invite)
+	if err != nil {
+		return entity.PlaydateInvite{}, err
+	}
+
+	return invite, nil
+}
+
+// AcceptPlaydate Отправляет питомца чата в гости по коду приглашения. Приглашение, перерыв между встречами и
+// запись встречи проверяются в одной транзакции; только записанная встреча даёт обоим питомцам прибавку счастья
+// по совместимости характеров, и оба чата получают одно и то же сообщение.
+func (s *Service) AcceptPlaydate(ctx context.Context, guestChatID int, code string) (entity.Visit, error) {
+	s.logger.Trace().Msg("accept playdate")
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	now := time.Now()
+
+	hostChatID, err := s.repo.GetPlaydateInvite(ctx, code, now)
+	if err != nil {
+		if errors.Is(err, repo.ErrInviteNotFound) {
+			return entity.Visit{}, ErrInviteNotFound
+		}
+
+		return entity.Visit{}, err
+	}
+
+	if hostChatID == guestChatID {
+		return entity.Visit{}, ErrSelfPlaydate
+	}
+
+	host, err := s.playdatePet(ctx, hostChatID, now)
+	if err != nil {
+		return entity.Visit{}, err
+	}
+
+	guest, err := s.playdatePet(ctx, guestChatID, now)
+	if err != nil {
+		return entity.Visit{}, err
+	}
+
+	visit := entity.Visit{
+		HostChatID:    hostChatID,
+		GuestChatID:   guestChatID,
+		HostPet:       host.Name,
+		GuestPet:      guest.Name,
+		Compatibility: petCompatibility(host.pet, guest.pet),
+		At:            now,
+	}
+	visit.Bonus = playdateMinBonus + (playdateMaxBonus-playdateMinBonus)*visit.Compatibility/100
+
+	// Код сгорает только теперь, когда оба питомца могут встретиться: если кто-то спит, приглашением
+	// воспользуются позже. Если код успели использовать параллельно, встречи не будет.
+	err = s.repo.SavePlaydate(ctx, code, visit, playdateCooldown)
+	switch {
+	case errors.Is(err, repo.ErrInviteNotFound):
+		return entity.Visit{}, ErrInviteNotFound
+	case errors.Is(err, repo.ErrPlaydateTooSoon):
+		return entity.Visit{}, ErrPlaydateTooSoon
+	case err != nil:
+		return entity.Visit{}, err
+	}
+
+	// Встреча уже состоялась: прибавка, которую не удалось сохранить, не отменяет её для второго питомца.
+	for _, p := range []*visitor{host, guest} {
+		err = s.finishPlaydate(ctx, p, visit.Bonus)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("can't apply playdate bonus for chat_id: %d", p.chatID)
+		}
+	}
+
+	message := playdateMessage(visit)
+	s.notify(ctx, hostChatID, message)
+	s.notify(ctx, guestChatID, message)
+
+	return visit, nil
+}
+
+// Visits Последние встречи питомца чата в гостях и у себя.
+func (s *Service) Visits(ctx context.Context, chatID int, limit int) ([]entity.Visit, error) {
+	s.logger.Trace().Msg("visits")
+
+	return s.repo.GetVisits(ctx, chatID, limit)
+}
+
+// visitor Питомец, дожитый до момента встречи.
+type visitor struct {
+	*gocha.Pet
+	pet    *entity.Pet
+	chatID int
+}
+
+// playdatePet Загружает питомца к встрече и проверяет, что он может пойти в гости. Перерыв между встречами
+// проверяет SavePlaydate под блокировкой.
+func (s *Service) playdatePet(ctx context.Context, chatID int, now time.Time) (*visitor, error) {
+	pet, err := s.LoadPet(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	extPet := PetEntityToGocha(pet)
+	extPet.DecayPercent = decayPercent(pet.Events)
+	extPet.Advance(now)
+
+	switch {
+	case extPet.IsDead():
+		return nil, fmt.Errorf("%w: %s умер", ErrPlaydateDenied, pet.Name)
+	case extPet.IsSleeping():
+		return nil, fmt.Errorf("%w: %s спит", ErrPlaydateDenied, pet.Name)
+	case extPet.Activity != nil:
+		return nil, fmt.Errorf("%w: %s занят", ErrPlaydateDenied, pet.Name)
+	case extPet.Energy < playdateEnergy*2:
+		return nil, fmt.Errorf("%w: %s слишком устал", ErrPlaydateDenied, pet.Name)
+	}
+
+	return &visitor{Pet: extPet, pet: pet, chatID: chatID}, nil
+}
+
+// finishPlaydate Начисляет питомцу прибавку счастья за встречу и сохраняет его.
+func (s *Service) finishPlaydate(ctx context.Context, p *visitor, bonus int) error {
+	_, err := s.updatePet(ctx, p.chatID, func(extPet *gocha.Pet, _ *entity.Pet) error {
+		extPet.Happiness = min(extPet.Happiness+bonus, gocha.MaxStatValue)
+		extPet.Energy = max(extPet.Energy-playdateEnergy, gocha.MinStatValue)
+
+		return nil
+	})
+
+	return err
+}
+
+func petCompatibility(a, b *entity.Pet) int {
+	ta, tb := a.Trait(), b.Trait()
+
+	if value, ok := compatibility[[2]string{ta, tb}]; ok {
+		return value
+	}
+
+	return compatibility[[2]string{tb, ta}]
+}
+
+func playdateMessage(v entity.Visit) string {
+	mood := "немного повздорили, но расстались друзьями"
+
+	switch {
+	case v.Compatibility >= 75:
+		mood = "стали лучшими друзьями"
+	case v.Compatibility >= 50:
+		mood = "отлично провели время"
+	}
+
+	return fmt.Sprintf("🏡 Встреча в гостях: %s (гость) и %s (хозяин) %s! Совместимость %d%%, счастье +%d у обоих.",
+		v.GuestPet, v.HostPet, mood, v.Compatibility, v.Bonus)
+}
+
+// inviteCode Короткий код для ввода вручную: в base32 нет нуля и единицы, которые путают с O и I.
+func inviteCode() (string, error) {
+	buf := make([]byte, 5)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestPetCompatibility(t *testing.T) {
+	t.Parallel()
+
+	for _, a := range []string{"Гоча", "Мурка", "Бобик", "Кеша"} {
+		for _, b := range []string{"Гоча", "Мурка", "Бобик", "Кеша"} {
+			pa, pb := &entity.Pet{ID: 1, Name: a}, &entity.Pet{ID: 2, Name: b}
+
+			ab, ba := petCompatibility(pa, pb), petCompatibility(pb, pa)
+			if ab != ba || ab <= 0 || ab > 100 {
+				t.Errorf("compatibility(%s, %s) = %d, reversed %d", a, b, ab, ba)
+			}
+		}
+	}
+}
+
+func TestService_AcceptPlaydate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		host  = 1
+		guest = 2
+		code  = "ABCDEFGH"
+	)
+
+	pet := func(id int, name string, energy int) *entity.Pet {
+		return &entity.Pet{
+			ID: id, Name: name, State: entity.PetAlive,
+			Health: 100, Hunger: 100, Happiness: 50, Energy: energy, Hygiene: 100,
+			LastUpdated: time.Now(), CreatedAt: time.Now().Add(-time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		guestChat   int
+		code        string
+		guestEnergy int
+		expired     bool
+		visits      []entity.Visit
+		saveErr     error
+		wantErr     error
+		wantVisits  int
+		inviteLeft  bool
+		wantChanged bool
+	}{
+		{
+			name:        "встреча",
+			guestChat:   guest,
+			code:        " abcdefgh ",
+			guestEnergy: 100,
+			wantVisits:  1,
+			wantChanged: true,
+		},
+		{
+			name:        "неизвестный код",
+			guestChat:   guest,
+			code:        "ZZZZZZZZ",
+			guestEnergy: 100,
+			wantErr:     ErrInviteNotFound,
+			inviteLeft:  true,
+		},
+		{
+			name:        "истёкшее приглашение",
+			guestChat:   guest,
+			code:        code,
+			guestEnergy: 100,
+			expired:     true,
+			wantErr:     ErrInviteNotFound,
+			inviteLeft:  true,
+		},
+		{
+			name:        "в гости к себе",
+			guestChat:   host,
+			code:        code,
+			guestEnergy: 100,
+			wantErr:     ErrSelfPlaydate,
+			inviteLeft:  true,
+		},
+		{
+			name:        "уставший гость не тратит приглашение",
+			guestChat:   guest,
+			code:        code,
+			guestEnergy: 10,
+			wantErr:     ErrPlaydateDenied,
+			inviteLeft:  true,
+		},
+		{
+			name:        "перерыв проверяется в транзакции, питомцы не меняются",
+			guestChat:   guest,
+			code:        code,
+			guestEnergy: 100,
+			visits:      []entity.Visit{{HostChatID: 3, GuestChatID: guest, At: time.Now().Add(-10 * time.Minute)}},
+			wantErr:     ErrPlaydateTooSoon,
+			wantVisits:  1,
+			inviteLeft:  true,
+		},
+		{
+			name:        "сбой транзакции не начисляет прибавку",
+			guestChat:   guest,
+			code:        code,
+			guestEnergy: 100,
+			saveErr:     errors.New("db is down"),
+			wantErr:     errors.New("db is down"),
+			inviteLeft:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newMemoryRepo()
+			expiresAt := time.Now().Add(time.Hour)
+			if tt.expired {
+				expiresAt = time.Now().Add(-time.Minute)
+			}
+
+			store.invites[code] = playdateInvite{chatID: host, expiresAt: expiresAt}
+			store.visits, store.playdateErr = tt.visits, tt.saveErr
+			store.putPet(host, pet(10, "Гоча", 100))
+			store.putPet(guest, pet(20, "Мурка", tt.guestEnergy))
+			logger := zerolog.Nop()
+			s := NewService(&config.Configuration{}, &logger, store, nil)
+
+			visit, err := s.AcceptPlaydate(context.Background(), tt.guestChat, tt.code)
+
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("AcceptPlaydate() error = %v", err)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr) && (err == nil || err.Error() != tt.wantErr.Error()):
+				t.Fatalf("AcceptPlaydate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(store.visits) != tt.wantVisits {
+				t.Errorf("visits = %d, want %d", len(store.visits), tt.wantVisits)
+			}
+
+			if _, ok := store.invites[code]; ok != tt.inviteLeft {
+				t.Errorf("invite left = %v, want %v", ok, tt.inviteLeft)
+			}
+
+			for _, chatID := range []int{host, guest} {
+				changed := store.pet(chatID).Happiness != 50
+				if changed != tt.wantChanged {
+					t.Errorf("pet of chat %d happiness changed = %v, want %v", chatID, changed, tt.wantChanged)
+				}
+
+				if changed && store.pet(chatID).Happiness != 50+visit.Bonus {
+					t.Errorf("pet of chat %d happiness = %d, want %d", chatID, store.pet(chatID).Happiness, 50+visit.Bonus)
+				}
+			}
+		})
+	}
+}
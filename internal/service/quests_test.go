@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+	"gocha/pkg/gocha"
+
+	"github.com/rs/zerolog"
+)
+
+func questTemplate(t *testing.T, id string) entity.Quest {
+	t.Helper()
+
+	for _, quest := range questTemplates {
+		if quest.ID == id {
+			quest.Status = entity.QuestActive
+
+			return quest
+		}
+	}
+
+	t.Fatalf("quest %q not found", id)
+
+	return entity.Quest{}
+}
+
+func TestQuestActionProgress(t *testing.T) {
+	t.Parallel()
+
+	play, _ := LookupAction("play")
+	feed, _ := LookupAction("feed")
+	wakeup, _ := LookupAction("wakeup")
+
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	slept := func(d time.Duration) *entity.Pet { return &entity.Pet{SleepStartTime: now.Add(-d)} }
+
+	tests := []struct {
+		name       string
+		quest      string
+		progress   int
+		ev         ActionEvent
+		changed    bool
+		wantStatus entity.QuestStatus
+	}{
+		{
+			name:       "действие засчитывается",
+			quest:      "play5",
+			ev:         ActionEvent{Action: play, Success: true, At: now},
+			changed:    true,
+			wantStatus: entity.QuestActive,
+		},
+		{
+			name:       "последнее действие выполняет задание",
+			quest:      "play5",
+			progress:   4,
+			ev:         ActionEvent{Action: play, Success: true, At: now},
+			changed:    true,
+			wantStatus: entity.QuestDone,
+		},
+		{
+			name:       "неудачное действие не считается",
+			quest:      "play5",
+			ev:         ActionEvent{Action: play, At: now},
+			wantStatus: entity.QuestActive,
+		},
+		{
+			name:       "чужое действие не считается",
+			quest:      "play5",
+			ev:         ActionEvent{Action: feed, Success: true, At: now},
+			wantStatus: entity.QuestActive,
+		},
+		{
+			name:       "разбудили после долгого сна",
+			quest:      "sleep30",
+			ev:         ActionEvent{Action: wakeup, Success: true, Before: slept(40 * time.Minute), At: now},
+			changed:    true,
+			wantStatus: entity.QuestDone,
+		},
+		{
+			name:       "разбудили слишком рано",
+			quest:      "sleep30",
+			ev:         ActionEvent{Action: wakeup, Success: true, Before: slept(10 * time.Minute), At: now},
+			wantStatus: entity.QuestActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			quest := questTemplate(t, tt.quest)
+			quest.Progress = tt.progress
+
+			if changed := questActionProgress(&quest, tt.ev); changed != tt.changed {
+				t.Errorf("questActionProgress() = %v, want %v", changed, tt.changed)
+			}
+
+			if quest.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", quest.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestQuestWakeProgress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		quest      string
+		slept      time.Duration
+		wantStatus entity.QuestStatus
+	}{
+		{name: "выспался", quest: "sleep30", slept: 30 * time.Minute, wantStatus: entity.QuestDone},
+		{name: "проснулся сам, но спал недолго", quest: "sleep30", slept: 10 * time.Minute, wantStatus: entity.QuestActive},
+		{name: "чужое задание", quest: "play5", slept: time.Hour, wantStatus: entity.QuestActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			quest := questTemplate(t, tt.quest)
+			changed := questWakeProgress(&quest, tt.slept)
+
+			if changed != (tt.wantStatus == entity.QuestDone) || quest.Status != tt.wantStatus {
+				t.Errorf("questWakeProgress() = %v, status %q, want %q", changed, quest.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestQuestTickProgress(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	at := func(hour int) time.Time { return time.Date(2024, time.March, 1, hour, 30, 0, 0, loc) }
+
+	tests := []struct {
+		name         string
+		progress     int
+		hygiene      int
+		at           time.Time
+		changed      bool
+		wantStatus   entity.QuestStatus
+		wantProgress int
+	}{
+		{
+			name:       "ночью стат не проверяется",
+			hygiene:    10,
+			at:         at(3),
+			wantStatus: entity.QuestActive,
+		},
+		{
+			name:       "днём просадка проваливает задание",
+			hygiene:    69,
+			at:         at(12),
+			changed:    true,
+			wantStatus: entity.QuestFailed,
+		},
+		{
+			name:         "удержание продвигает прогресс по часам",
+			progress:     11,
+			hygiene:      70,
+			at:           at(12),
+			changed:      true,
+			wantStatus:   entity.QuestActive,
+			wantProgress: 12,
+		},
+		{
+			name:         "в тот же час прогресс не меняется",
+			progress:     12,
+			hygiene:      90,
+			at:           at(12),
+			wantStatus:   entity.QuestActive,
+			wantProgress: 12,
+		},
+		{
+			name:         "дотянули до срока",
+			progress:     19,
+			hygiene:      90,
+			at:           at(20),
+			changed:      true,
+			wantStatus:   entity.QuestDone,
+			wantProgress: 20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			quest := questTemplate(t, "hygiene70")
+			quest.Progress = tt.progress
+
+			// Замер приходит в UTC, час считается по местному времени чата.
+			ev := TickEvent{Pet: &entity.Pet{Hygiene: tt.hygiene}, At: tt.at.UTC()}
+
+			if changed := questTickProgress(&quest, ev, loc); changed != tt.changed {
+				t.Errorf("questTickProgress() = %v, want %v", changed, tt.changed)
+			}
+
+			if quest.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", quest.Status, tt.wantStatus)
+			}
+
+			if tt.wantStatus != entity.QuestFailed && quest.Progress != tt.wantProgress {
+				t.Errorf("progress = %d, want %d", quest.Progress, tt.wantProgress)
+			}
+		})
+	}
+}
+
+func TestGenerateQuests(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	ids := func(quests []entity.Quest) []string {
+		result := make([]string, 0, len(quests))
+		for _, quest := range quests {
+			result = append(result, quest.ID)
+		}
+
+		return result
+	}
+
+	first := generateQuests(1, day)
+	if len(first) != questsPerDay {
+		t.Fatalf("generateQuests() returned %d quests, want %d", len(first), questsPerDay)
+	}
+
+	if again := generateQuests(1, day); !slices.Equal(ids(first), ids(again)) {
+		t.Errorf("same chat and day got %v and %v", ids(first), ids(again))
+	}
+
+	// Сброс — это новый набор на следующий день; хотя бы за неделю он должен смениться.
+	changed := false
+	for d := 1; d <= 7; d++ {
+		changed = changed || !slices.Equal(ids(first), ids(generateQuests(1, day.AddDate(0, 0, d))))
+	}
+
+	if !changed {
+		t.Errorf("quests never changed over a week: %v", ids(first))
+	}
+
+	for _, quest := range first {
+		if quest.Status != entity.QuestActive || quest.Progress != 0 {
+			t.Errorf("new quest %s = %+v, want active without progress", quest.ID, quest)
+		}
+	}
+}
+
+func TestWokeUp(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	asleep := func() *gocha.Pet {
+		p := gocha.NewPet("")
+		p.Energy = 70
+		p.Sleep()
+		p.SleepStartTime, p.Activity.StartedAt, p.LastUpdated = start, start, start
+
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		pet     *gocha.Pet
+		advance time.Duration
+		want    bool
+	}{
+		{name: "проснулся сам", pet: asleep(), advance: 2 * time.Hour, want: true},
+		{name: "ещё спит", pet: asleep(), advance: 5 * time.Minute},
+		{name: "не спал", pet: gocha.NewPet(""), advance: 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.pet.LastUpdated = start
+			results := tt.pet.Advance(start.Add(tt.advance))
+
+			slept, ok := wokeUp(results, GochaToPetEntity(tt.pet))
+			if ok != tt.want {
+				t.Fatalf("wokeUp() = %v, want %v", ok, tt.want)
+			}
+
+			if ok && (slept < 10*time.Minute || slept > time.Hour) {
+				t.Errorf("slept = %v, want the sleep to end on its own within an hour", slept)
+			}
+
+			if _, ok := wokeUp(results, &entity.Pet{State: entity.PetDead}); ok {
+				t.Error("wokeUp() reported a dead pet as woken up")
+			}
+		})
+	}
+}
+
+func TestService_OnWakeUp(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	sleep := questTemplate(t, "sleep30")
+	store := newMemoryRepo()
+	store.timezones[1] = "UTC"
+	store.quests[1] = map[string][]entity.Quest{"2024-03-01": {questTemplate(t, "play5"), sleep}}
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, nil)
+
+	// Пробуждение, пришедшее повторно, не награждает второй раз.
+	s.onWakeUp(context.Background(), 1, 10*time.Minute, at)
+
+	if quests := store.quests[1]["2024-03-01"]; quests[1].Status != entity.QuestActive {
+		t.Fatalf("short sleep completed the quest: %+v", quests[1])
+	}
+
+	s.onWakeUp(context.Background(), 1, 45*time.Minute, at)
+	s.onWakeUp(context.Background(), 1, 45*time.Minute, at)
+
+	quests := store.quests[1]["2024-03-01"]
+	if quests[1].Status != entity.QuestDone || quests[0].Status != entity.QuestActive {
+		t.Errorf("quests after wake-up = %+v", quests)
+	}
+
+	if coins := store.coins[walletKey{1, chatStreakUser}]; coins != sleep.Reward {
+		t.Errorf("coins = %d, want %d", coins, sleep.Reward)
+	}
+}
+
+// wakeRepo Общее хранилище, в котором первое сохранение питомца проигрывает гонку.
+type wakeRepo struct {
+	*memoryRepo
+
+	raced bool
+}
+
+func (r *wakeRepo) SavePet(ctx context.Context, p *entity.Pet, chatID int) error {
+	if !r.raced {
+		r.raced = true
+		r.pets[chatID].Version++
+	}
+
+	return r.memoryRepo.SavePet(ctx, p, chatID)
+}
+
+func TestService_WakeUpAfterSave(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	day := dayOf(now.UTC()).Format(time.DateOnly)
+
+	extPet := gocha.NewPet("Гоча")
+	extPet.Energy = 20
+	extPet.Sleep()
+	extPet.SleepStartTime, extPet.Activity.StartedAt, extPet.LastUpdated = now.Add(-2*time.Hour), now.Add(-2*time.Hour), now.Add(-2*time.Hour)
+
+	pet := GochaToPetEntity(extPet)
+	pet.ID, pet.CreatedAt = 1, now.AddDate(0, 0, -1)
+
+	store := &wakeRepo{memoryRepo: newMemoryRepo()}
+	store.putPet(1, pet)
+	store.timezones[1] = "UTC"
+	store.quests[1] = map[string][]entity.Quest{day: {questTemplate(t, "sleep30")}}
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{LazyStats: true}, &logger, store, nil)
+	s.calendar = nil
+
+	// Чтение доживает питомца до пробуждения, но ничего не сохраняет — и задание не трогает.
+	if _, err := s.LoadPet(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if store.quests[1][day][0].Status != entity.QuestActive {
+		t.Fatalf("LoadPet() progressed the quest: %+v", store.quests[1][day][0])
+	}
+
+	// Первое сохранение проиграло гонку: пробуждение засчитывается один раз, после удачного повтора.
+	_, err := s.updatePet(context.Background(), 1, func(*gocha.Pet, *entity.Pet) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if store.quests[1][day][0].Status != entity.QuestDone || store.coins[walletKey{1, chatStreakUser}] != store.quests[1][day][0].Reward {
+		t.Errorf("quest after wake-up = %+v, coins = %d", store.quests[1][day][0], store.coins[walletKey{1, chatStreakUser}])
+	}
+
+	if got := store.pet(1).State; got != entity.PetAlive {
+		t.Errorf("saved state = %q, want awake", got)
+	}
+}
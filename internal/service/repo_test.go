@@ -0,0 +1,678 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+// memoryRepo Общее хранилище тестов сервиса: данные чатов в памяти с теми же правилами, что у
+// postgres.Repository. Методы, которые тестам не нужны, возвращают пустые значения.
+type memoryRepo struct {
+	repo.Repository
+
+	mu        sync.Mutex
+	pets      map[int]*entity.Pet
+	conflicts int
+	timezones map[int]string
+
+	reports     map[int][]entity.CareReport
+	reportsSent map[int]map[string]bool
+
+	streaks map[walletKey]entity.Streak
+	coins   map[walletKey]int
+
+	quests map[int]map[string][]entity.Quest
+
+	cosmetics      map[int]map[string]ownedCosmetic
+	addCosmeticErr error
+
+	invites     map[string]playdateInvite
+	visits      []entity.Visit
+	playdateErr error
+
+	usernames  map[string]int
+	gifts      []entity.Gift
+	giftLimits entity.GiftLimits // Лимиты последнего SendGift.
+
+	lastAlerts map[int]map[string]time.Time
+	settings   map[int]entity.NotifySettings
+
+	leaderboard          []entity.LeaderboardEntry         // Общий рейтинг любого вида, включая скрытые чаты.
+	chatLeaderboards     map[int][]entity.LeaderboardEntry // Рейтинг участников чата любого вида.
+	topHidden            map[int]bool
+	leaderboardReads     int         // Запросов общих рейтингов.
+	chatLeaderboardReads map[int]int // Запросов рейтингов чата.
+}
+
+// ownedCosmetic Купленный чатом предмет.
+type ownedCosmetic struct {
+	slot     entity.CosmeticSlot
+	equipped bool
+}
+
+// playdateInvite Приглашение на встречу от чата chatID.
+type playdateInvite struct {
+	chatID    int
+	expiresAt time.Time
+}
+
+// walletKey Кошелёк или серия участника чата; userID chatStreakUser — общие для чата.
+type walletKey struct {
+	chatID int
+	userID int64
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		pets:      map[int]*entity.Pet{},
+		timezones: map[int]string{},
+
+		reports:     map[int][]entity.CareReport{},
+		reportsSent: map[int]map[string]bool{},
+
+		streaks: map[walletKey]entity.Streak{},
+		coins:   map[walletKey]int{},
+
+		quests: map[int]map[string][]entity.Quest{},
+
+		cosmetics: map[int]map[string]ownedCosmetic{},
+
+		invites: map[string]playdateInvite{},
+
+		usernames: map[string]int{},
+
+		lastAlerts: map[int]map[string]time.Time{},
+		settings:   map[int]entity.NotifySettings{},
+
+		chatLeaderboards:     map[int][]entity.LeaderboardEntry{},
+		topHidden:            map[int]bool{},
+		chatLeaderboardReads: map[int]int{},
+	}
+}
+
+// putPet Кладёт копию питомца чата в хранилище.
+func (r *memoryRepo) putPet(chatID int, p *entity.Pet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pets[chatID] = clonePet(p)
+}
+
+func (r *memoryRepo) pet(chatID int) *entity.Pet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return clonePet(r.pets[chatID])
+}
+
+func (r *memoryRepo) LoadPet(_ context.Context, chatID int) (*entity.Pet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pet, ok := r.pets[chatID]
+	if !ok {
+		return nil, repo.ErrPetNotFound
+	}
+
+	return clonePet(pet), nil
+}
+
+func (r *memoryRepo) LoadPets(ctx context.Context, chatIDs []int) (map[int]*entity.Pet, error) {
+	pets := make(map[int]*entity.Pet, len(chatIDs))
+
+	for _, chatID := range chatIDs {
+		pet, err := r.LoadPet(ctx, chatID)
+		if err == nil {
+			pets[chatID] = pet
+		}
+	}
+
+	return pets, nil
+}
+
+func (r *memoryRepo) SavePet(_ context.Context, p *entity.Pet, chatID int) error {
+	// Отдаём процессор между чтением и записью, чтобы гонки случались чаще.
+	runtime.Gosched()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.pets[chatID]
+	if !ok || stored.Version != p.Version {
+		r.conflicts++
+
+		return repo.ErrVersionConflict
+	}
+
+	p.Version++
+	r.pets[chatID] = clonePet(p)
+
+	return nil
+}
+
+func (r *memoryRepo) SavePets(ctx context.Context, pets map[int]*entity.Pet) ([]int, error) {
+	var conflicts []int
+
+	for chatID, p := range pets {
+		if errors.Is(r.SavePet(ctx, p, chatID), repo.ErrVersionConflict) {
+			conflicts = append(conflicts, chatID)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// GetChats Чаты с питомцем или заданным часовым поясом.
+func (r *memoryRepo) GetChats(context.Context) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chats := slices.Collect(maps.Keys(r.pets))
+	for chatID := range r.timezones {
+		if !slices.Contains(chats, chatID) {
+			chats = append(chats, chatID)
+		}
+	}
+
+	slices.Sort(chats)
+
+	return chats, nil
+}
+
+func (r *memoryRepo) GetChatTimezone(_ context.Context, chatID int) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.timezones[chatID], nil
+}
+
+func (r *memoryRepo) GetCosmetics(_ context.Context, chatID int) (map[string]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make(map[string]bool, len(r.cosmetics[chatID]))
+	for id, item := range r.cosmetics[chatID] {
+		items[id] = item.equipped
+	}
+
+	return items, nil
+}
+
+// AddCosmetic Как и в базе, повторная покупка ничего не меняет.
+func (r *memoryRepo) AddCosmetic(_ context.Context, chatID int, itemID string, slot entity.CosmeticSlot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.addCosmeticErr != nil {
+		return r.addCosmeticErr
+	}
+
+	if r.cosmetics[chatID] == nil {
+		r.cosmetics[chatID] = map[string]ownedCosmetic{}
+	}
+
+	if _, ok := r.cosmetics[chatID][itemID]; !ok {
+		r.cosmetics[chatID][itemID] = ownedCosmetic{slot: slot}
+	}
+
+	return nil
+}
+
+func (r *memoryRepo) EquipCosmetic(_ context.Context, chatID int, slot entity.CosmeticSlot, itemID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, item := range r.cosmetics[chatID] {
+		if item.slot == slot {
+			item.equipped = id == itemID
+			r.cosmetics[chatID][id] = item
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryRepo) GetCareReports(_ context.Context, chatID int, from, to time.Time) ([]entity.CareReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reports []entity.CareReport
+
+	for _, report := range r.reports[chatID] {
+		day := report.Day.Format(time.DateOnly)
+		if day >= from.Format(time.DateOnly) && day <= to.Format(time.DateOnly) {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports, nil
+}
+
+func (r *memoryRepo) SaveCareReport(_ context.Context, chatID int, c entity.CareReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, report := range r.reports[chatID] {
+		if report.Day.Equal(c.Day) {
+			r.reports[chatID][i] = c
+
+			return nil
+		}
+	}
+
+	r.reports[chatID] = append(r.reports[chatID], c)
+
+	return nil
+}
+
+// MarkCareReportSent Как и в базе, отмечает только существующий отчёт и только один раз.
+func (r *memoryRepo) MarkCareReportSent(_ context.Context, chatID int, day time.Time, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := day.Format(time.DateOnly)
+
+	exists := slices.ContainsFunc(r.reports[chatID], func(report entity.CareReport) bool {
+		return report.Day.Format(time.DateOnly) == key
+	})
+	if !exists || r.reportsSent[chatID][key] {
+		return false, nil
+	}
+
+	if r.reportsSent[chatID] == nil {
+		r.reportsSent[chatID] = map[string]bool{}
+	}
+
+	r.reportsSent[chatID][key] = true
+
+	return true, nil
+}
+
+func (r *memoryRepo) UnmarkCareReportSent(_ context.Context, chatID int, day time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reportsSent[chatID], day.Format(time.DateOnly))
+
+	return nil
+}
+
+func (r *memoryRepo) GetStreak(_ context.Context, chatID int, userID int64) (entity.Streak, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.streaks[walletKey{chatID, userID}], nil
+}
+
+// ExtendStreak Как и в базе, перезаписывает существующую серию, только если её последний день — prevDay.
+func (r *memoryRepo) ExtendStreak(_ context.Context, chatID int, userID int64, prevDay time.Time, streak entity.Streak) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := walletKey{chatID, userID}
+
+	stored, ok := r.streaks[key]
+	if ok && !stored.LastDay.Equal(prevDay) {
+		return false, nil
+	}
+
+	r.streaks[key] = streak
+
+	return true, nil
+}
+
+func (r *memoryRepo) GetCoins(_ context.Context, chatID int, userID int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.coins[walletKey{chatID, userID}], nil
+}
+
+func (r *memoryRepo) AddCoins(_ context.Context, chatID int, userID int64, amount int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := walletKey{chatID, userID}
+	r.coins[key] += amount
+
+	return r.coins[key], nil
+}
+
+func (r *memoryRepo) SpendCoins(_ context.Context, chatID int, userID int64, amount int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := walletKey{chatID, userID}
+	if r.coins[key] < amount {
+		return r.coins[key], repo.ErrNotEnoughCoins
+	}
+
+	r.coins[key] -= amount
+
+	return r.coins[key], nil
+}
+
+func (r *memoryRepo) GetDailyQuests(_ context.Context, chatID int, day time.Time) ([]entity.Quest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	quests, ok := r.quests[chatID][day.Format(time.DateOnly)]
+	if !ok {
+		return nil, repo.ErrQuestsNotFound
+	}
+
+	return slices.Clone(quests), nil
+}
+
+func (r *memoryRepo) SaveDailyQuests(_ context.Context, chatID int, day time.Time, quests []entity.Quest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.quests[chatID] == nil {
+		r.quests[chatID] = map[string][]entity.Quest{}
+	}
+
+	r.quests[chatID][day.Format(time.DateOnly)] = slices.Clone(quests)
+
+	return nil
+}
+
+func (r *memoryRepo) GetDiary(context.Context, int, time.Time, int) ([]entity.DiaryEntry, error) {
+	return nil, nil
+}
+func (r *memoryRepo) SaveDiaryEntry(context.Context, int, entity.DiaryEntry) error { return nil }
+
+func (r *memoryRepo) AddHistory(context.Context, int, int, entity.HistoryEntry) error { return nil }
+
+func (r *memoryRepo) AddStatSamples(context.Context, map[int][]entity.StatPoint) error { return nil }
+
+func (r *memoryRepo) GetChatRoles(context.Context, int) ([]entity.ChatRole, error) { return nil, nil }
+
+func (r *memoryRepo) MarkEventAnnounced(context.Context, int, string, time.Time) (bool, error) {
+	return false, nil
+}
+
+// GetNotifySettings Как и в базе, у чата без своих настроек — nil.
+func (r *memoryRepo) GetNotifySettings(_ context.Context, chatID int) (*entity.NotifySettings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings, ok := r.settings[chatID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &settings, nil
+}
+
+func (r *memoryRepo) SaveNotifySettings(_ context.Context, chatID int, settings entity.NotifySettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings[chatID] = settings
+
+	return nil
+}
+func (r *memoryRepo) GetNotifyChannels(context.Context, int) ([]entity.NotifyChannel, error) {
+	return nil, nil
+}
+func (r *memoryRepo) GetLastAlert(_ context.Context, chatID int, alertType string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lastAlerts[chatID][alertType], nil
+}
+
+func (r *memoryRepo) UpdateLastAlert(_ context.Context, chatID int, alertType string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastAlerts[chatID] == nil {
+		r.lastAlerts[chatID] = map[string]time.Time{}
+	}
+
+	r.lastAlerts[chatID][alertType] = at
+
+	return nil
+}
+
+func (r *memoryRepo) SavePlaydateInvite(_ context.Context, chatID int, invite entity.PlaydateInvite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invites[invite.Code] = playdateInvite{chatID: chatID, expiresAt: invite.ExpiresAt}
+
+	return nil
+}
+
+func (r *memoryRepo) GetPlaydateInvite(_ context.Context, code string, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, ok := r.invites[code]
+	if !ok || !invite.expiresAt.After(now) {
+		return 0, repo.ErrInviteNotFound
+	}
+
+	return invite.chatID, nil
+}
+
+// SavePlaydate Правила транзакции postgres.Repository: при любой ошибке приглашение остаётся на месте.
+func (r *memoryRepo) SavePlaydate(_ context.Context, code string, v entity.Visit, cooldown time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.playdateErr != nil {
+		return r.playdateErr
+	}
+
+	invite, ok := r.invites[code]
+	if !ok || invite.chatID != v.HostChatID || !invite.expiresAt.After(v.At) {
+		return repo.ErrInviteNotFound
+	}
+
+	for _, prev := range r.visits {
+		met := prev.HostChatID == v.HostChatID || prev.GuestChatID == v.HostChatID ||
+			prev.HostChatID == v.GuestChatID || prev.GuestChatID == v.GuestChatID
+		if met && prev.At.After(v.At.Add(-cooldown)) {
+			return repo.ErrPlaydateTooSoon
+		}
+	}
+
+	delete(r.invites, code)
+	r.visits = append(r.visits, v)
+
+	return nil
+}
+
+func (r *memoryRepo) SaveUsername(_ context.Context, username string, _ int64, chatID int, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.usernames[strings.ToLower(username)] = chatID
+
+	return nil
+}
+
+func (r *memoryRepo) GetUsernameChat(_ context.Context, username string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	chatID, ok := r.usernames[strings.ToLower(username)]
+	if !ok {
+		return 0, repo.ErrUserNotFound
+	}
+
+	return chatID, nil
+}
+
+// SendGift Правила транзакции postgres.Repository: лимиты, списание монет или предмета и запись подарка
+// вместе; номер подарка — его место в gifts, начиная с 1.
+func (r *memoryRepo) SendGift(_ context.Context, g entity.Gift, limits entity.GiftLimits) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.giftLimits = limits
+
+	var sent, coins, pending int
+
+	for _, prev := range r.gifts {
+		if prev.FromUserID == g.FromUserID && !prev.CreatedAt.Before(limits.Since) {
+			sent++
+
+			if prev.Kind == entity.GiftCoins {
+				coins += prev.Amount
+			}
+		}
+
+		if prev.ToChatID == g.ToChatID && prev.Status == entity.GiftPending {
+			pending++
+		}
+	}
+
+	if sent >= limits.PerDay || g.Kind == entity.GiftCoins && coins+g.Amount > limits.CoinsPerDay || pending >= limits.PendingPerTo {
+		return 0, repo.ErrGiftLimit
+	}
+
+	switch g.Kind {
+	case entity.GiftCoins:
+		wallet := walletKey{g.FromChatID, g.FromUserID}
+		if r.coins[wallet] < g.Amount {
+			return 0, repo.ErrNotEnoughCoins
+		}
+
+		r.coins[wallet] -= g.Amount
+	case entity.GiftItem:
+		item, ok := r.cosmetics[g.FromChatID][g.Item]
+		if !ok || item.equipped {
+			return 0, repo.ErrItemNotOwned
+		}
+
+		delete(r.cosmetics[g.FromChatID], g.Item)
+	}
+
+	g.ID, g.Status = int64(len(r.gifts)+1), entity.GiftPending
+	r.gifts = append(r.gifts, g)
+
+	return g.ID, nil
+}
+
+// ResolveGift Правила транзакции postgres.Repository: разобрать можно только ждущий подарок своего чата.
+func (r *memoryRepo) ResolveGift(_ context.Context, giftID int64, chatID int, status entity.GiftStatus, _ time.Time) (entity.Gift, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if giftID < 1 || int(giftID) > len(r.gifts) {
+		return entity.Gift{}, repo.ErrGiftNotFound
+	}
+
+	g := r.gifts[giftID-1]
+	if g.ToChatID != chatID || g.Status != entity.GiftPending {
+		return entity.Gift{}, repo.ErrGiftNotFound
+	}
+
+	g.Status = status
+
+	// Монеты получает общий кошелёк чата, а возвращаются они в личный кошелёк отправителя.
+	to := walletKey{g.ToChatID, chatStreakUser}
+	if status == entity.GiftDeclined {
+		to = walletKey{g.FromChatID, g.FromUserID}
+	}
+
+	switch g.Kind {
+	case entity.GiftCoins:
+		r.coins[to] += g.Amount
+	case entity.GiftItem:
+		if _, owned := r.cosmetics[to.chatID][g.Item]; !owned {
+			if r.cosmetics[to.chatID] == nil {
+				r.cosmetics[to.chatID] = map[string]ownedCosmetic{}
+			}
+
+			r.cosmetics[to.chatID][g.Item] = ownedCosmetic{slot: g.Slot}
+
+			break
+		}
+
+		if status != entity.GiftDeclined {
+			return entity.Gift{}, repo.ErrItemOwned
+		}
+
+		g.Refunded = true
+		r.coins[to] += g.Amount
+	}
+
+	r.gifts[giftID-1] = g
+
+	return g, nil
+}
+
+// GetLeaderboard Как и в базе, скрытые чаты в общие рейтинги не попадают.
+func (r *memoryRepo) GetLeaderboard(_ context.Context, _ entity.LeaderboardCategory, _ time.Time, limit int) ([]entity.LeaderboardEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.leaderboardReads++
+
+	var entries []entity.LeaderboardEntry
+
+	for _, e := range r.leaderboard {
+		if !r.topHidden[e.ChatID] && len(entries) < limit {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+func (r *memoryRepo) GetChatLeaderboard(_ context.Context, chatID int, _ entity.LeaderboardCategory, _ []string, _ time.Time, limit int) ([]entity.LeaderboardEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.chatLeaderboardReads[chatID]++
+
+	entries := r.chatLeaderboards[chatID]
+
+	return slices.Clone(entries[:min(limit, len(entries))]), nil
+}
+
+func (r *memoryRepo) GetTopHidden(_ context.Context, chatID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.topHidden[chatID], nil
+}
+
+func (r *memoryRepo) SetTopHidden(_ context.Context, chatID int, hidden bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.topHidden[chatID] = hidden
+
+	return nil
+}
+
+func clonePet(p *entity.Pet) *entity.Pet {
+	c := *p
+	c.Skills = maps.Clone(p.Skills)
+
+	c.RecentCare = make(map[string][]time.Time, len(p.RecentCare))
+	for action, times := range p.RecentCare {
+		c.RecentCare[action] = slices.Clone(times)
+	}
+
+	if p.Activity != nil {
+		activity := *p.Activity
+		c.Activity = &activity
+	}
+
+	return &c
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+const (
+	neglectAfter         = 6 * time.Hour    // Столько питомец может обходиться без ухода, дальше это запущенность.
+	reportCheckInterval  = 15 * time.Minute // Полночь любого часового пояса приходится на начало четверти часа.
+	reportHistoryDays    = 7
+	maxSampleGap         = time.Hour // Больший разрыв между замерами считаем простоем сервиса, а не временем питомца.
+	unansweredPenalty    = 5
+	maxUnansweredPenalty = 15
+)
+
+// Notifier Канал доставки сообщений чата питомца: сам чат в Telegram, вебхук или почта.
+type Notifier interface {
+	Notify(ctx context.Context, chatID int, message string) error
+}
+
+// AlertNotifier Уведомитель, который умеет прикладывать к предупреждению кнопки исправляющих действий.
+type AlertNotifier interface {
+	Alert(ctx context.Context, chatID int, alert entity.Alert) error
+}
+
+// CareReport Табель ухода за сегодня и историю за неделю.
+func (s *Service) CareReport(ctx context.Context, chatID int) (entity.CareReportCard, error) {
+	s.logger.Trace().Msg("care report")
+
+	today := dayOf(time.Now().In(s.chatLocation(ctx, chatID)))
+
+	reports, err := s.repo.GetCareReports(ctx, chatID, today.AddDate(0, 0, -reportHistoryDays), today)
+	if err != nil {
+		return entity.CareReportCard{}, err
+	}
+
+	card := entity.CareReportCard{
+		Today:   newCareReport(today, reports),
+		History: make([]entity.CareReport, 0, len(reports)),
+	}
+
+	for _, report := range reports {
+		fillCareReport(&report)
+
+		if sameDay(report.Day, today) {
+			card.Today = report
+
+			continue
+		}
+
+		card.History = append(card.History, report)
+	}
+
+	fillCareReport(&card.Today)
+
+	return card, nil
+}
+
+// todayCareReport Отчёт за текущий день чата по его часовому поясу. Новый день наследует незакрытое предупреждение
+// и время последнего ухода.
+func (s *Service) todayCareReport(ctx context.Context, chatID int, now time.Time) (entity.CareReport, error) {
+	today := dayOf(now.In(s.chatLocation(ctx, chatID)))
+
+	reports, err := s.repo.GetCareReports(ctx, chatID, today.AddDate(0, 0, -reportHistoryDays), today)
+	if err != nil {
+		return entity.CareReport{}, err
+	}
+
+	if len(reports) > 0 && sameDay(reports[len(reports)-1].Day, today) {
+		return reports[len(reports)-1], nil
+	}
+
+	return newCareReport(today, reports), nil
+}
+
+// trackCare Учитывает замеры состояния питомца одного местного дня в дневном отчёте.
+func (s *Service) trackCare(ctx context.Context, chatID int, samples []TickEvent) {
+	last := samples[len(samples)-1]
+	if last.Pet.State == entity.PetDead {
+		return
+	}
+
+	report, err := s.todayCareReport(ctx, chatID, last.At)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load care report")
+
+		return
+	}
+
+	for _, sample := range samples {
+		if sample.Pet.State != entity.PetDead {
+			s.addCareSample(&report, sample)
+		}
+	}
+
+	s.saveCareReport(ctx, chatID, report)
+}
+
+// addCareSample Добавляет в отчёт время с прошлого замера, отнеся его к состоянию питомца на этом замере.
+func (s *Service) addCareSample(report *entity.CareReport, ev TickEvent) {
+	elapsed := s.updateInterval()
+	if !report.LastSampleAt.IsZero() {
+		elapsed = min(ev.At.Sub(report.LastSampleAt), maxSampleGap)
+	}
+
+	minutes := int(elapsed.Minutes())
+
+	ev.Pet.UpdateStatus()
+
+	report.ObservedMinutes += minutes
+
+	switch {
+	case ev.Pet.Status.IsCritical:
+		report.CriticalMinutes += minutes
+	case ev.Pet.Status.IsWarning:
+		report.WarningMinutes += minutes
+	}
+
+	if !report.LastActionAt.IsZero() && ev.At.Sub(report.LastActionAt) > neglectAfter {
+		report.NeglectMinutes += minutes
+	}
+
+	if ev.Alerting && report.PendingAlertAt.IsZero() {
+		report.Alerts++
+		report.PendingAlertAt = ev.At
+	}
+
+	report.LastSampleAt = ev.At
+}
+
+// recordCareAction Учитывает действие ухода: закрывает ожидающее предупреждение и сбрасывает запущенность.
+func (s *Service) recordCareAction(ctx context.Context, chatID int, now time.Time) {
+	report, err := s.todayCareReport(ctx, chatID, now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load care report")
+
+		return
+	}
+
+	report.Actions++
+	report.LastActionAt = now
+
+	if !report.PendingAlertAt.IsZero() {
+		report.RespondedAlerts++
+		report.ResponseSeconds += int(now.Sub(report.PendingAlertAt).Seconds())
+		report.PendingAlertAt = time.Time{}
+	}
+
+	s.saveCareReport(ctx, chatID, report)
+}
+
+func (s *Service) saveCareReport(ctx context.Context, chatID int, report entity.CareReport) {
+	report.Score = careScore(report)
+
+	err := s.repo.SaveCareReport(ctx, chatID, report)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save care report")
+	}
+}
+
+// RunDailyReports Каждую четверть часа отправляет итоги прошедшего дня тем чатам, у которых по их часовому поясу
+// уже наступила полночь. Блокирует до отмены контекста.
+func (s *Service) RunDailyReports(ctx context.Context) {
+	for {
+		now := time.Now()
+		timer := time.NewTimer(now.Truncate(reportCheckInterval).Add(reportCheckInterval).Sub(now))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case <-timer.C:
+			s.sendDailyReports(ctx, time.Now())
+		}
+	}
+}
+
+// sendDailyReports Отправляет каждому чату итоги его вчерашнего дня, если они ещё не отправлены. Отметка об отправке
+// хранится в отчёте и ставится до отправки, чтобы итоги не дублировались; если отправить не удалось, отметка
+// снимается и итоги уходят на следующей проверке. Так после простоя сервиса или сбоя доставки итоги досылаются.
+func (s *Service) sendDailyReports(ctx context.Context, now time.Time) {
+	if s.notifier == nil {
+		return
+	}
+
+	chats, err := s.repo.GetChats(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't get chats for daily reports")
+
+		return
+	}
+
+	for _, chatID := range chats {
+		day := dayOf(now.In(s.chatLocation(ctx, chatID))).AddDate(0, 0, -1)
+
+		first, err := s.repo.MarkCareReportSent(ctx, chatID, day, now)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("can't mark daily report for chat_id: %d", chatID)
+
+			continue
+		}
+
+		if !first {
+			continue
+		}
+
+		reports, err := s.repo.GetCareReports(ctx, chatID, day, day)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("can't load care report for chat_id: %d", chatID)
+
+			continue
+		}
+
+		if len(reports) == 0 {
+			continue
+		}
+
+		report := reports[0]
+		fillCareReport(&report)
+
+		err = s.notifier.Notify(ctx, chatID, formatCareReport(report))
+		if err == nil {
+			continue
+		}
+
+		s.logger.Error().Err(err).Msgf("can't send daily report to chat_id: %d", chatID)
+
+		err = s.repo.UnmarkCareReportSent(ctx, chatID, day)
+		if err != nil {
+			s.logger.Error().Err(err).Msgf("can't unmark daily report for chat_id: %d", chatID)
+		}
+	}
+}
+
+// careScore Оценка ухода: время в жёлтой и красной зонах, запущенность и реакция на предупреждения.
+func careScore(r entity.CareReport) int {
+	observed := max(r.ObservedMinutes, 1)
+
+	penalty := r.CriticalMinutes*60/observed + r.WarningMinutes*25/observed + r.NeglectMinutes*30/observed
+	penalty += min((r.Alerts-r.RespondedAlerts)*unansweredPenalty, maxUnansweredPenalty)
+
+	if r.RespondedAlerts > 0 {
+		switch avg := time.Duration(r.ResponseSeconds/r.RespondedAlerts) * time.Second; {
+		case avg > 2*time.Hour:
+			penalty += 10
+		case avg > 30*time.Minute:
+			penalty += 5
+		}
+	}
+
+	return min(max(100-penalty, 0), 100)
+}
+
+func careGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "Отлично"
+	case score >= 75:
+		return "Хорошо"
+	case score >= 50:
+		return "Удовлетворительно"
+	default:
+		return "Плохо"
+	}
+}
+
+func fillCareReport(r *entity.CareReport) {
+	r.Score = careScore(*r)
+	r.Grade = careGrade(r.Score)
+
+	if r.RespondedAlerts > 0 {
+		r.AvgResponseMin = r.ResponseSeconds / r.RespondedAlerts / 60
+	}
+}
+
+func formatCareReport(r entity.CareReport) string {
+	return fmt.Sprintf("📋 Итоги дня %s\n"+
+		"Оценка ухода: %d/100 — %s\n"+
+		"🟡 В жёлтой зоне: %d мин.\n"+
+		"🔴 В красной зоне: %d мин.\n"+
+		"💤 Без ухода: %d мин.\n"+
+		"⚠️ Предупреждений: %d, отреагировали: %d (в среднем за %d мин.)\n"+
+		"🤲 Действий: %d",
+		r.Day.Format("02.01.2006"), r.Score, r.Grade,
+		r.WarningMinutes, r.CriticalMinutes, r.NeglectMinutes,
+		r.Alerts, r.RespondedAlerts, r.AvgResponseMin, r.Actions)
+}
+
+func newCareReport(day time.Time, previous []entity.CareReport) entity.CareReport {
+	report := entity.CareReport{Day: day, Score: 100}
+
+	if len(previous) > 0 {
+		last := previous[len(previous)-1]
+		report.LastActionAt = last.LastActionAt
+		report.PendingAlertAt = last.PendingAlertAt
+		report.LastSampleAt = last.LastSampleAt
+	}
+
+	return report
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format(time.DateOnly) == b.Format(time.DateOnly)
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCareScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		report entity.CareReport
+		want   int
+	}{
+		{
+			name:   "пустой день",
+			report: entity.CareReport{},
+			want:   100,
+		},
+		{
+			name:   "весь день в красной зоне",
+			report: entity.CareReport{ObservedMinutes: 600, CriticalMinutes: 600},
+			want:   40,
+		},
+		{
+			name:   "половина дня в жёлтой зоне",
+			report: entity.CareReport{ObservedMinutes: 600, WarningMinutes: 300},
+			want:   88,
+		},
+		{
+			name:   "штраф за пропущенные предупреждения ограничен",
+			report: entity.CareReport{ObservedMinutes: 600, Alerts: 50},
+			want:   100 - maxUnansweredPenalty,
+		},
+		{
+			name:   "медленная реакция",
+			report: entity.CareReport{ObservedMinutes: 600, Alerts: 1, RespondedAlerts: 1, ResponseSeconds: 3 * 3600},
+			want:   90,
+		},
+		{
+			name: "оценка не уходит ниже нуля",
+			report: entity.CareReport{
+				ObservedMinutes: 600, CriticalMinutes: 600, NeglectMinutes: 600, WarningMinutes: 600, Alerts: 50,
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := careScore(tt.report); got != tt.want {
+				t.Errorf("careScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCareReport(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+	actionAt := day.Add(-time.Hour)
+	alertAt := day.Add(-30 * time.Minute)
+
+	tests := []struct {
+		name     string
+		previous []entity.CareReport
+		want     entity.CareReport
+	}{
+		{
+			name: "первый день",
+			want: entity.CareReport{Day: day, Score: 100},
+		},
+		{
+			name: "новый день наследует незакрытое предупреждение",
+			previous: []entity.CareReport{
+				{LastActionAt: day.Add(-5 * time.Hour)},
+				{LastActionAt: actionAt, PendingAlertAt: alertAt, LastSampleAt: alertAt, Actions: 7, Alerts: 3},
+			},
+			want: entity.CareReport{Day: day, Score: 100, LastActionAt: actionAt, PendingAlertAt: alertAt, LastSampleAt: alertAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := newCareReport(day, tt.previous); got != tt.want {
+				t.Errorf("newCareReport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages map[int][]string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, chatID int, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messages[chatID] = append(n.messages[chatID], message)
+
+	return nil
+}
+
+func TestService_SendDailyReports(t *testing.T) {
+	t.Parallel()
+
+	const (
+		moscow = 1 // UTC+3
+		tokyo  = 2 // UTC+9
+	)
+
+	moscowDay := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	tokyoDay := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	store := newMemoryRepo()
+	store.timezones[moscow], store.timezones[tokyo] = "Europe/Moscow", "Asia/Tokyo"
+	store.reports[moscow] = []entity.CareReport{{Day: moscowDay, Actions: 1}}
+	store.reports[tokyo] = []entity.CareReport{{Day: tokyoDay, Actions: 2}}
+	notifier := &recordingNotifier{messages: map[int][]string{}}
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, notifier)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want map[int]int
+	}{
+		{
+			name: "в Токио уже 2 марта, в Москве ещё 1 марта",
+			now:  time.Date(2024, time.March, 1, 15, 0, 0, 0, time.UTC),
+			want: map[int]int{},
+		},
+		{
+			name: "в Москве наступило 2 марта",
+			now:  time.Date(2024, time.March, 1, 21, 0, 0, 0, time.UTC),
+			want: map[int]int{moscow: 1},
+		},
+		{
+			name: "повторная проверка ничего не дублирует",
+			now:  time.Date(2024, time.March, 1, 21, 15, 0, 0, time.UTC),
+			want: map[int]int{moscow: 1},
+		},
+		{
+			name: "в Токио наступило 3 марта",
+			now:  time.Date(2024, time.March, 2, 15, 0, 0, 0, time.UTC),
+			want: map[int]int{moscow: 1, tokyo: 1},
+		},
+	}
+
+	// Шаги идут последовательно: каждый опирается на отметки предыдущих.
+	for _, tt := range tests {
+		s.sendDailyReports(context.Background(), tt.now)
+
+		for _, chatID := range []int{moscow, tokyo} {
+			if got := len(notifier.messages[chatID]); got != tt.want[chatID] {
+				t.Errorf("%s: chat %d got %d reports, want %d", tt.name, chatID, got, tt.want[chatID])
+			}
+		}
+	}
+}
+
+func TestService_SendDailyReports_Failed(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	store := newMemoryRepo()
+	store.timezones[1] = "UTC"
+	store.reports[1] = []entity.CareReport{{Day: day, Actions: 1}}
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, failingNotifier{})
+
+	now := day.AddDate(0, 0, 1).Add(time.Hour)
+	s.sendDailyReports(context.Background(), now)
+
+	if store.reportsSent[1][day.Format(time.DateOnly)] {
+		t.Fatal("undelivered report stays marked as sent")
+	}
+
+	// Канал ожил: на следующей проверке итоги досылаются.
+	recorder := &recordingNotifier{messages: map[int][]string{}}
+	s.notifier = recorder
+	s.sendDailyReports(context.Background(), now.Add(reportCheckInterval))
+
+	if len(recorder.messages[1]) != 1 || !store.reportsSent[1][day.Format(time.DateOnly)] {
+		t.Errorf("resent reports = %v, marked = %v", recorder.messages[1], store.reportsSent[1])
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+	"gocha/pkg/gocha"
+)
+
+var (
+	ErrUnknownRole = errors.New("неизвестная роль: cook, doctor, entertainer или janitor")
+	ErrNoRole      = errors.New("у участника нет роли")
+)
+
+// roleBoost На сколько процентов своё действие ухода у участника с ролью сильнее обычного.
+const roleBoost = 50
+
+// RoleDef Роль в уходе за питомцем: какое действие она усиливает и закреплено ли оно за ней.
+type RoleDef struct {
+	Role  entity.Role
+	Title string
+	Emoji string
+	Care  gocha.CareAction // Действие, которое участник с ролью делает лучше остальных.
+	// Exclusive Если роль в чате кому-то назначена, её действие доступно только ей.
+	Exclusive bool
+}
+
+var roles = []RoleDef{
+	{Role: entity.RoleCook, Title: "Повар", Emoji: "👨‍🍳", Care: gocha.CareFeed},
+	{Role: entity.RoleDoctor, Title: "Врач", Emoji: "🩺", Care: gocha.CareHeal, Exclusive: true},
+	{Role: entity.RoleEntertainer, Title: "Аниматор", Emoji: "🎭", Care: gocha.CarePlay},
+	{Role: entity.RoleJanitor, Title: "Уборщик", Emoji: "🧹", Care: gocha.CareClean},
+}
+
+// Roles Все роли, которые можно назначить.
+func Roles() []RoleDef {
+	return roles
+}
+
+func LookupRole(role entity.Role) (RoleDef, bool) {
+	for _, def := range roles {
+		if def.Role == role {
+			return def, true
+		}
+	}
+
+	return RoleDef{}, false
+}
+
+// roleAccess Роли чата глазами участника: его собственная роль и какие роли в чате уже заняты.
+// Нулевое значение — ролей нет, ничего не закрыто и не усилено.
+type roleAccess struct {
+	role     entity.Role
+	assigned map[entity.Role]bool
+}
+
+// allows Закрыто ли действие чужой ролью.
+func (a roleAccess) allows(action Action) (bool, string) {
+	for _, def := range roles {
+		if def.Exclusive && def.Care == action.Care && action.Care != "" && a.assigned[def.Role] && a.role != def.Role {
+			return false, fmt.Sprintf("%s здесь может только %s", action.Title, strings.ToLower(def.Title))
+		}
+	}
+
+	return true, ""
+}
+
+// boost Прибавка к эффекту действия от роли участника.
+func (a roleAccess) boost(action Action) int {
+	def, ok := LookupRole(a.role)
+	if !ok || action.Care == "" || def.Care != action.Care {
+		return 0
+	}
+
+	return roleBoost
+}
+
+// roleAccess Роли чата для actor. Если их не удалось загрузить, действия не закрываются.
+func (s *Service) roleAccess(ctx context.Context, chatID int, actor entity.Actor) roleAccess {
+	chatRoles, err := s.repo.GetChatRoles(ctx, chatID)
+	if err != nil {
+		s.logger.Error().Err(err).Msgf("can't load roles for chat_id: %d", chatID)
+
+		return roleAccess{}
+	}
+
+	access := roleAccess{assigned: make(map[entity.Role]bool, len(chatRoles))}
+	for _, cr := range chatRoles {
+		access.assigned[cr.Role] = true
+
+		if actor.ID != 0 && cr.UserID == actor.ID {
+			access.role = cr.Role
+		}
+	}
+
+	return access
+}
+
+// PetForUser Питомец чата с доступными действиями для конкретного участника: с учётом его роли и занятых ролей.
+func (s *Service) PetForUser(ctx context.Context, chatID int, actor entity.Actor) (*entity.Pet, error) {
+	pet, err := s.LoadPet(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	describeActions(pet, s.roleAccess(ctx, chatID, actor))
+
+	return pet, nil
+}
+
+// ChatRoles Кому в чате какие роли назначены.
+func (s *Service) ChatRoles(ctx context.Context, chatID int) ([]entity.ChatRole, error) {
+	s.logger.Trace().Msg("chat roles")
+
+	return s.repo.GetChatRoles(ctx, chatID)
+}
+
+// UserRole Роль участника чата; пустая, если не назначена.
+func (s *Service) UserRole(ctx context.Context, chatID int, userID int64) (entity.Role, error) {
+	s.logger.Trace().Msg("user role")
+
+	return s.repo.GetUserRole(ctx, chatID, userID)
+}
+
+// AssignRole Назначает участнику роль вместо прежней. Кто вправе назначать, решает вызывающий.
+func (s *Service) AssignRole(ctx context.Context, chatID int, member entity.Actor, role string) (entity.ChatRole, error) {
+	s.logger.Trace().Msg("assign role")
+
+	def, ok := LookupRole(entity.Role(role))
+	if !ok {
+		return entity.ChatRole{}, ErrUnknownRole
+	}
+
+	cr := entity.ChatRole{UserID: member.ID, Name: member.Name, Role: def.Role}
+
+	err := s.repo.SetUserRole(ctx, chatID, cr)
+	if err != nil {
+		return entity.ChatRole{}, err
+	}
+
+	return cr, nil
+}
+
+// RemoveRole Снимает роль с участника.
+func (s *Service) RemoveRole(ctx context.Context, chatID int, userID int64) error {
+	s.logger.Trace().Msg("remove role")
+
+	err := s.repo.DeleteUserRole(ctx, chatID, userID)
+	if errors.Is(err, repo.ErrNoRole) {
+		return ErrNoRole
+	}
+
+	return err
+}
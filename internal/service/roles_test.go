@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"gocha/internal/entity"
+)
+
+func TestRoleAccess(t *testing.T) {
+	t.Parallel()
+
+	heal, _ := LookupAction("heal")
+	feed, _ := LookupAction("feed")
+
+	tests := []struct {
+		name      string
+		access    roleAccess
+		action    Action
+		allowed   bool
+		wantBoost int
+	}{
+		{
+			name:    "без ролей всё открыто",
+			action:  heal,
+			allowed: true,
+		},
+		{
+			name:    "лечение закреплено за назначенным врачом",
+			access:  roleAccess{role: entity.RoleCook, assigned: map[entity.Role]bool{entity.RoleDoctor: true, entity.RoleCook: true}},
+			action:  heal,
+			allowed: false,
+		},
+		{
+			name:      "врач лечит и лечит лучше",
+			access:    roleAccess{role: entity.RoleDoctor, assigned: map[entity.Role]bool{entity.RoleDoctor: true}},
+			action:    heal,
+			allowed:   true,
+			wantBoost: roleBoost,
+		},
+		{
+			name:    "кормить может каждый, даже если повар назначен",
+			access:  roleAccess{assigned: map[entity.Role]bool{entity.RoleCook: true}},
+			action:  feed,
+			allowed: true,
+		},
+		{
+			name:      "повар кормит лучше",
+			access:    roleAccess{role: entity.RoleCook, assigned: map[entity.Role]bool{entity.RoleCook: true}},
+			action:    feed,
+			allowed:   true,
+			wantBoost: roleBoost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if allowed, reason := tt.access.allows(tt.action); allowed != tt.allowed {
+				t.Errorf("allows() = %v (%q), want %v", allowed, reason, tt.allowed)
+			}
+
+			if got := tt.access.boost(tt.action); got != tt.wantBoost {
+				t.Errorf("boost() = %v, want %v", got, tt.wantBoost)
+			}
+		})
+	}
+}
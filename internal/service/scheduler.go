@@ -0,0 +1,277 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+// scheduler Очередь чатов по времени следующего замера. Один цикл достаёт из кучи чаты, чей срок подошёл,
+// пачками до batch штук и отдаёт их ограниченному пулу воркеров; после обработки чат снова встаёт в очередь
+// на срок, который вернул process, а если срока нет — через interval. Вместо горутины с тикером на каждый
+// чат — одна куча и workers горутин.
+type scheduler struct {
+	interval time.Duration
+	batch    int
+	workers  int
+	process  func(ctx context.Context, chatIDs []int) map[int]time.Time
+
+	mu      sync.Mutex
+	queue   chatQueue
+	chats   map[int]*scheduledChat // Все чаты под наблюдением, включая обрабатываемые сейчас.
+	wake    chan struct{}
+	running int
+
+	lag       time.Duration // Насколько опоздала последняя отданная пачка.
+	processed int64
+	batches   int64
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// scheduledChat Чат в очереди; index = -1, пока его пачку обрабатывает воркер.
+type scheduledChat struct {
+	chatID    int
+	due       time.Time
+	index     int
+	requested time.Time // Срок, запрошенный во время обработки: учтётся, когда чат вернётся в очередь.
+}
+
+func newScheduler(interval time.Duration, batch, workers int, process func(ctx context.Context, chatIDs []int) map[int]time.Time) *scheduler {
+	return &scheduler{
+		interval: interval,
+		batch:    max(batch, 1),
+		workers:  max(workers, 1),
+		process:  process,
+		chats:    make(map[int]*scheduledChat),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Schedule Ставит чат в очередь на момент due или переносит его замер. Чат, который сейчас обрабатывается,
+// встанет в очередь по окончании обработки — не позже due.
+func (q *scheduler) Schedule(chatID int, due time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, ok := q.chats[chatID]
+
+	switch {
+	case !ok:
+		item = &scheduledChat{chatID: chatID, due: due}
+		q.chats[chatID] = item
+		heap.Push(&q.queue, item)
+	case item.index >= 0:
+		item.due = due
+		heap.Fix(&q.queue, item.index)
+	default:
+		item.requested = due
+	}
+
+	q.signal()
+}
+
+// Remove Снимает чат с наблюдения.
+func (q *scheduler) Remove(chatID int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, ok := q.chats[chatID]
+	if !ok {
+		return
+	}
+
+	delete(q.chats, chatID)
+
+	if item.index >= 0 {
+		heap.Remove(&q.queue, item.index)
+	}
+}
+
+// Run Запускает цикл очереди и воркеров; останавливается через Stop или по отмене ctx.
+func (q *scheduler) Run(ctx context.Context) {
+	ctx, q.cancel = context.WithCancel(ctx)
+
+	jobs := make(chan []int)
+
+	q.done.Add(q.workers)
+
+	for range q.workers {
+		go func() {
+			defer q.done.Done()
+
+			for chatIDs := range jobs {
+				q.finish(chatIDs, q.process(ctx, chatIDs))
+			}
+		}()
+	}
+
+	q.done.Add(1)
+
+	go func() {
+		defer q.done.Done()
+		defer close(jobs)
+
+		for {
+			chatIDs, wait := q.next(time.Now())
+			if len(chatIDs) == 0 {
+				timer := time.NewTimer(wait)
+
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+
+					return
+				case <-q.wake:
+				case <-timer.C:
+				}
+
+				timer.Stop()
+
+				continue
+			}
+
+			// Отправка блокируется, пока все воркеры заняты: очередь копится, а не горутины.
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- chatIDs:
+			}
+		}
+	}()
+}
+
+// Stop Останавливает цикл и дожидается, пока воркеры закончат текущие пачки.
+func (q *scheduler) Stop() {
+	if q.cancel != nil {
+		q.cancel()
+	}
+
+	q.done.Wait()
+}
+
+// Stats Глубина очереди, отставание и счётчики обработки.
+func (q *scheduler) Stats(now time.Time) entity.SchedulerStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := entity.SchedulerStats{
+		Chats:      len(q.chats),
+		QueueDepth: len(q.queue),
+		InFlight:   q.running,
+		Workers:    q.workers,
+		BatchSize:  q.batch,
+		LastLag:    q.lag.Seconds(),
+		Processed:  q.processed,
+		Batches:    q.batches,
+	}
+
+	for _, item := range q.queue {
+		if !item.due.After(now) {
+			stats.Due++
+		}
+	}
+
+	if len(q.queue) > 0 && q.queue[0].due.Before(now) {
+		stats.Lag = now.Sub(q.queue[0].due).Seconds()
+	}
+
+	return stats
+}
+
+// next Забирает пачку чатов, чей срок подошёл; если таких нет — сколько ждать до ближайшего.
+func (q *scheduler) next(now time.Time) ([]int, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.queue) == 0 {
+		return nil, q.interval
+	}
+
+	if wait := q.queue[0].due.Sub(now); wait > 0 {
+		return nil, wait
+	}
+
+	q.lag = now.Sub(q.queue[0].due)
+
+	chatIDs := make([]int, 0, min(q.batch, len(q.queue)))
+	for len(q.queue) > 0 && len(chatIDs) < q.batch && !q.queue[0].due.After(now) {
+		item := heap.Pop(&q.queue).(*scheduledChat)
+		chatIDs = append(chatIDs, item.chatID)
+	}
+
+	q.running += len(chatIDs)
+	q.batches++
+
+	return chatIDs, 0
+}
+
+// finish Возвращает обработанные чаты в очередь на сроки next, если их не сняли с наблюдения за время обработки.
+func (q *scheduler) finish(chatIDs []int, next map[int]time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+
+	for _, chatID := range chatIDs {
+		item, ok := q.chats[chatID]
+		if !ok || item.index >= 0 {
+			continue
+		}
+
+		item.due = now.Add(q.interval)
+		if due, ok := next[chatID]; ok {
+			item.due = due
+		}
+
+		if !item.requested.IsZero() && item.requested.Before(item.due) {
+			item.due = item.requested
+		}
+
+		item.requested = time.Time{}
+
+		heap.Push(&q.queue, item)
+	}
+
+	q.running -= len(chatIDs)
+	q.processed += int64(len(chatIDs))
+}
+
+func (q *scheduler) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// chatQueue Куча чатов по сроку замера.
+type chatQueue []*scheduledChat
+
+func (h chatQueue) Len() int           { return len(h) }
+func (h chatQueue) Less(i, j int) bool { return h[i].due.Before(h[j].due) }
+
+func (h chatQueue) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *chatQueue) Push(x any) {
+	item := x.(*scheduledChat)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *chatQueue) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	item.index = -1
+	*h = old[:len(old)-1]
+
+	return item
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Next(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		batch    int
+		due      map[int]time.Duration // Срок каждого чата относительно now.
+		want     [][]int
+		wantWait time.Duration
+	}{
+		{
+			name:     "пустая очередь ждёт интервал",
+			batch:    10,
+			want:     nil,
+			wantWait: time.Minute,
+		},
+		{
+			name:     "срок ещё не подошёл",
+			batch:    10,
+			due:      map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second},
+			wantWait: 5 * time.Second,
+		},
+		{
+			name:     "по сроку, будущие остаются",
+			batch:    10,
+			due:      map[int]time.Duration{1: -time.Second, 2: -time.Minute, 3: time.Second, 4: -time.Hour},
+			want:     [][]int{{4, 2, 1}},
+			wantWait: time.Second,
+		},
+		{
+			name:     "пачками не больше batch",
+			batch:    2,
+			due:      map[int]time.Duration{1: -5 * time.Second, 2: -4 * time.Second, 3: -3 * time.Second, 4: -2 * time.Second, 5: -time.Second},
+			want:     [][]int{{1, 2}, {3, 4}, {5}},
+			wantWait: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := newScheduler(time.Minute, tt.batch, 1, nil)
+			for chatID, offset := range tt.due {
+				q.Schedule(chatID, now.Add(offset))
+			}
+
+			var got [][]int
+
+			for {
+				chatIDs, wait := q.next(now)
+				if len(chatIDs) == 0 {
+					if wait != tt.wantWait {
+						t.Errorf("wait = %v, want %v", wait, tt.wantWait)
+					}
+
+					break
+				}
+
+				got = append(got, chatIDs)
+			}
+
+			if !slices.EqualFunc(got, tt.want, slices.Equal[[]int]) {
+				t.Errorf("batches = %v, want %v", got, tt.want)
+			}
+
+			if stats := q.Stats(now); stats.Batches != int64(len(tt.want)) || stats.Chats != len(tt.due) {
+				t.Errorf("stats = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		run  func(q *scheduler)
+		want time.Time
+	}{
+		{
+			name: "перенос чата в очереди раньше",
+			run: func(q *scheduler) {
+				q.Schedule(1, now.Add(time.Hour))
+				q.Schedule(1, now.Add(time.Minute))
+			},
+			want: now.Add(time.Minute),
+		},
+		{
+			name: "перенос чата в очереди позже",
+			run: func(q *scheduler) {
+				q.Schedule(1, now.Add(time.Minute))
+				q.Schedule(1, now.Add(time.Hour))
+			},
+			want: now.Add(time.Hour),
+		},
+		{
+			name: "запрос во время обработки раньше срока от process",
+			run: func(q *scheduler) {
+				q.Schedule(1, now.Add(-time.Second))
+				chatIDs, _ := q.next(now)
+				q.Schedule(1, now.Add(time.Minute))
+				q.finish(chatIDs, map[int]time.Time{1: now.Add(time.Hour)})
+			},
+			want: now.Add(time.Minute),
+		},
+		{
+			name: "запрос во время обработки позже срока от process",
+			run: func(q *scheduler) {
+				q.Schedule(1, now.Add(-time.Second))
+				chatIDs, _ := q.next(now)
+				q.Schedule(1, now.Add(2*time.Hour))
+				q.finish(chatIDs, map[int]time.Time{1: now.Add(time.Hour)})
+			},
+			want: now.Add(time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q := newScheduler(time.Minute, 10, 1, nil)
+			tt.run(q)
+
+			if len(q.queue) != 1 || len(q.chats) != 1 {
+				t.Fatalf("queue = %d, chats = %d, want one queued chat", len(q.queue), len(q.chats))
+			}
+
+			if !q.queue[0].due.Equal(tt.want) {
+				t.Errorf("due = %v, want %v", q.queue[0].due, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_RemoveWhileProcessing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	q := newScheduler(time.Minute, 10, 1, nil)
+	q.Schedule(1, now.Add(-time.Second))
+
+	chatIDs, _ := q.next(now)
+	q.Remove(1)
+	q.finish(chatIDs, nil)
+
+	if len(q.queue) != 0 || len(q.chats) != 0 {
+		t.Errorf("removed chat returned to queue: queue = %d, chats = %d", len(q.queue), len(q.chats))
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan []int, 1)
+
+	// Обработка держит пачку до отмены контекста: остановка должна её дождаться.
+	q := newScheduler(time.Minute, 10, 2, func(ctx context.Context, chatIDs []int) map[int]time.Time {
+		started <- chatIDs
+		<-ctx.Done()
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Run(ctx)
+	q.Schedule(1, time.Now())
+
+	select {
+	case chatIDs := <-started:
+		if !slices.Equal(chatIDs, []int{1}) {
+			t.Errorf("processed %v, want [1]", chatIDs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("chat was not processed")
+	}
+
+	cancel()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		q.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop after context cancel")
+	}
+
+	stats := q.Stats(time.Now())
+	if stats.Processed != 1 || stats.InFlight != 0 || stats.QueueDepth != 1 {
+		t.Errorf("stats after stop = %+v", stats)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/internal/repo"
+)
+
+const (
+	rawStatsKeep   = 24 * time.Hour      // Столько замеры хранятся как есть, дальше сворачиваются в часовые.
+	statsRetention = 90 * 24 * time.Hour // Старше этого замеры удаляются совсем.
+	compactEvery   = time.Hour
+)
+
+var ErrUnknownWindow = errors.New("неизвестное окно графика: day или week")
+
+// statWindow Окно графика и отрезок, по которому усредняются его точки.
+type statWindow struct {
+	span time.Duration
+	step time.Duration
+}
+
+var statWindows = map[string]statWindow{
+	"day":  {span: 24 * time.Hour, step: 15 * time.Minute},
+	"week": {span: 7 * 24 * time.Hour, step: 2 * time.Hour},
+}
+
+// StatSeries Как менялись статы питомца чата за окно window: day или week.
+func (s *Service) StatSeries(ctx context.Context, chatID int, window string) (entity.StatSeries, error) {
+	s.logger.Trace().Msg("stat series")
+
+	w, ok := statWindows[window]
+	if !ok {
+		return entity.StatSeries{}, ErrUnknownWindow
+	}
+
+	pet, err := s.repo.LoadPet(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, repo.ErrPetNotFound) {
+			return entity.StatSeries{}, ErrPetNotFound
+		}
+
+		return entity.StatSeries{}, err
+	}
+
+	// Отрезок короче интервала замеров даёт пустые точки между замерами.
+	step := max(w.step, s.updateInterval())
+	to := time.Now()
+	from := to.Add(-w.span)
+
+	points, err := s.repo.GetStatSeries(ctx, pet.ID, from, to, step)
+	if err != nil {
+		return entity.StatSeries{}, err
+	}
+
+	return entity.StatSeries{
+		Window: window,
+		Step:   int(step.Seconds()),
+		From:   from,
+		To:     to,
+		Points: points,
+	}, nil
+}
+
+// recordStatSamples Записывает замер статов живых питомцев пачки вместе с восстановленными замерами
+// ленивого режима одним запросом. Ключ pets и replayed — чат.
+func (s *Service) recordStatSamples(ctx context.Context, pets map[int]*entity.Pet, replayed map[int][]TickEvent, now time.Time) {
+	samples := make(map[int][]entity.StatPoint, len(pets))
+
+	for chatID, pet := range pets {
+		if pet.ID == 0 {
+			continue
+		}
+
+		for _, tick := range replayed[chatID] {
+			if tick.Pet.State != entity.PetDead {
+				samples[pet.ID] = append(samples[pet.ID], statPoint(tick.Pet, tick.At))
+			}
+		}
+
+		if pet.State != entity.PetDead {
+			samples[pet.ID] = append(samples[pet.ID], statPoint(pet, now))
+		}
+	}
+
+	if len(samples) == 0 {
+		return
+	}
+
+	err := s.repo.AddStatSamples(ctx, samples)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save stat samples")
+	}
+}
+
+// RunStatCompaction Раз в час сворачивает старые замеры статов в часовые средние и удаляет совсем старые.
+// Блокирует до отмены контекста.
+func (s *Service) RunStatCompaction(ctx context.Context) {
+	ticker := time.NewTicker(compactEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+
+			err := s.repo.CompactStatSamples(ctx, now.Add(-rawStatsKeep), now.Add(-statsRetention))
+			if err != nil {
+				s.logger.Error().Err(err).Msg("can't compact stat samples")
+			}
+		}
+	}
+}
+
+func statPoint(pet *entity.Pet, at time.Time) entity.StatPoint {
+	return entity.StatPoint{
+		At:        at,
+		Health:    pet.Health,
+		Hunger:    pet.Hunger,
+		Happiness: pet.Happiness,
+		Energy:    pet.Energy,
+		Hygiene:   pet.Hygiene,
+	}
+}
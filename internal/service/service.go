@@ -4,36 +4,77 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
+	"net/http"
 	"time"
 
 	"gocha/internal/config"
 	"gocha/internal/entity"
+	"gocha/internal/notify"
 	"gocha/internal/repo"
 	"gocha/pkg/gocha"
 
 	"github.com/rs/zerolog"
 )
 
-var ErrPetNotFound = errors.New("питомец не найден")
+var (
+	ErrPetNotFound      = errors.New("питомец не найден")
+	ErrUnknownAction    = errors.New("неизвестное действие")
+	ErrActionDenied     = errors.New("действие недоступно")
+	ErrUnknownTrick     = errors.New("неизвестный трюк")
+	ErrUnknownActivity  = errors.New("неизвестное занятие")
+	ErrConcurrentUpdate = errors.New("питомца одновременно меняют, попробуйте ещё раз")
+)
 
-type Service struct {
-	cfg    *config.Configuration
-	logger *zerolog.Logger
-	repo   repo.Repository
+// saveAttempts Сколько раз пробовать сохранить питомца, если его меняют параллельно.
+const saveAttempts = 5
 
-	// Управление мониторингом
-	monitoringChats map[int]context.CancelFunc // chatID -> cancel function
-	monitoringMutex sync.RWMutex
+type Service struct {
+	cfg      *config.Configuration
+	logger   *zerolog.Logger
+	repo     repo.Repository
+	notifier Notifier
+	phrases  *phraseMemory
+	calendar []entity.CalendarEvent
+	webhooks *http.Client
+	// Очередь доставки во внешние каналы
+	deliveries *deliveryQueue
+	// Копящиеся сводки предупреждений
+	digests *digestBuffer
+
+	leaderboards *leaderboardCache
+
+	// Очередь замеров питомцев
+	scheduler *scheduler
+	// Чаты, которым в ленивом режиме пересчитать пробуждение
+	reschedules *lazyQueue
 }
 
-func NewService(cfg *config.Configuration, logger *zerolog.Logger, repo repo.Repository) *Service {
-	return &Service{
-		cfg:             cfg,
-		logger:          logger,
-		repo:            repo,
-		monitoringChats: make(map[int]context.CancelFunc),
+func NewService(cfg *config.Configuration, logger *zerolog.Logger, repo repo.Repository, notifier Notifier) *Service {
+	calendar, err := loadCalendar(cfg.CalendarFile)
+	if err != nil {
+		logger.Error().Err(err).Msg("can't load calendar, using default")
+
+		calendar = defaultCalendar
 	}
+
+	s := &Service{
+		cfg:      cfg,
+		logger:   logger,
+		repo:     repo,
+		notifier: notifier,
+		phrases:  &phraseMemory{recent: make(map[int][]string)},
+		calendar: calendar,
+		webhooks: notify.NewWebhookClient(webhookTimeout),
+
+		deliveries: newDeliveryQueue(),
+		digests:    newDigestBuffer(),
+
+		leaderboards: &leaderboardCache{chats: make(map[int]cachedLeaderboards)},
+	}
+	s.scheduler = newScheduler(s.updateInterval(), cfg.SchedulerBatch, cfg.SchedulerWorkers, s.livePets)
+	s.reschedules = newLazyQueue()
+
+	return s
 }
 
 func (s *Service) NewPet(ctx context.Context, chatID int, name string) (*entity.Pet, error) {
@@ -46,71 +87,78 @@ func (s *Service) NewPet(ctx context.Context, chatID int, name string) (*entity.
 		return nil, err
 	}
 
+	describeActions(pet, roleAccess{})
+
 	// Запускаем мониторинг для нового питомца
 	s.startMonitoringForChat(ctx, chatID)
 
 	return pet, nil
 }
 
-func (s *Service) PetFeed(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet feed")
+// PerformAction Выполняет зарегистрированное действие с питомцем.
+func (s *Service) PerformAction(ctx context.Context, chatID int, actor entity.Actor, name string, params ActionParams) (entity.PetActionResult, error) {
+	s.logger.Trace().Msgf("pet %s", name)
 
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.Feed()
-	})
-}
+	action, ok := LookupAction(name)
+	if !ok {
+		return entity.PetActionResult{}, ErrUnknownAction
+	}
 
-func (s *Service) PetHeal(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet heal")
+	err := action.validate(params)
+	if err != nil {
+		return entity.PetActionResult{}, err
+	}
 
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.Heal()
-	})
+	return s.petAction(ctx, chatID, actor, action, params)
 }
 
-func (s *Service) PetClean(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet clean")
+func (s *Service) petAction(ctx context.Context, chatID int, actor entity.Actor, action Action, params ActionParams) (entity.PetActionResult, error) {
+	var (
+		before *entity.Pet
+		result gocha.Result
+		reason string
+	)
 
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.Clean()
-	})
-}
+	access := s.roleAccess(ctx, chatID, actor)
 
-func (s *Service) PetPlay(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet play")
-
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.Play()
-	})
-}
+	pet, err := s.updatePet(ctx, chatID, func(extPet *gocha.Pet, current *entity.Pet) error {
+		before = current
 
-func (s *Service) PetSleep(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet sleep")
+		var allowed bool
 
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.Sleep()
-	})
-}
+		allowed, reason = action.canPerformAs(before, access)
+		if !allowed {
+			return fmt.Errorf("%w: %s", ErrActionDenied, reason)
+		}
 
-func (s *Service) PetWakeUp(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet wake up")
+		extPet.CareBoost = access.boost(action)
+		result = action.Effect(extPet, params)
 
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.WakeUp()
+		return nil
 	})
-}
 
-func (s *Service) PetBuru(ctx context.Context, chatID int) (entity.PetActionResult, error) {
-	s.logger.Trace().Msg("pet buru")
+	switch {
+	case errors.Is(err, ErrActionDenied):
+		s.recordHistory(ctx, ActionEvent{
+			ChatID:  chatID,
+			Actor:   actor,
+			Action:  action,
+			Params:  params,
+			Before:  before,
+			Message: reason,
+			At:      time.Now(),
+		})
 
-	return s.petAction(ctx, chatID, func(p *gocha.Pet) gocha.Result {
-		return p.WakeUp()
-	})
-}
+		describeActions(before, access)
 
-func (s *Service) petAction(ctx context.Context, chatID int, action func(*gocha.Pet) gocha.Result) (entity.PetActionResult, error) {
-	pet, err := s.repo.LoadPet(ctx, chatID)
-	if err != nil {
+		return entity.PetActionResult{
+			Pet: before,
+			Result: entity.Result{
+				Success: false,
+				Message: reason,
+			},
+		}, err
+	case err != nil && before == nil:
 		return entity.PetActionResult{
 			Pet: nil,
 			Result: entity.Result{
@@ -118,18 +166,7 @@ func (s *Service) petAction(ctx context.Context, chatID int, action func(*gocha.
 				Message: "Не удалось загрузить питомца: " + err.Error(),
 			},
 		}, err
-	}
-
-	extPet := PetEntityToGocha(pet)
-
-	result := action(extPet)
-
-	pet = GochaToPetEntity(extPet)
-
-	pet.GetAvatar(s.cfg.BaseUrl)
-
-	err = s.SavePet(ctx, pet, chatID)
-	if err != nil {
+	case err != nil:
 		return entity.PetActionResult{
 			Pet: nil,
 			Result: entity.Result{
@@ -139,30 +176,144 @@ func (s *Service) petAction(ctx context.Context, chatID int, action func(*gocha.
 		}, err
 	}
 
-	return entity.PetActionResult{
+	pet.GetAvatar(s.cfg.BaseUrl)
+	describeActions(pet, access)
+
+	actionResult := entity.PetActionResult{
 		Pet: pet,
 		Result: entity.Result{
 			Success: result.Success,
 			Message: result.Message,
 		},
 		Avatar: pet.Avatar,
-	}, nil
+	}
+	actionResult.GenerateActionFeedback(action.Feedback)
+
+	actionResult.Notices = s.onAction(ctx, ActionEvent{
+		ChatID:  chatID,
+		Actor:   actor,
+		Action:  action,
+		Params:  params,
+		Success: result.Success,
+		Before:  before,
+		After:   pet,
+		Message: result.Message,
+		At:      time.Now(),
+	})
+
+	return actionResult, nil
+}
+
+// updatePet Загружает питомца, доживает его до текущего момента, применяет change и сохраняет. change получает
+// питомца для изменения и его же снимок до изменения. Если питомца успели сохранить параллельно — монитор или
+// другое действие, — всё повторяется на свежем снимке, не больше saveAttempts раз. Ошибка change возвращается
+// без повторов. Пробуждение, найденное при доживании, обрабатывается только после сохранения снимка с ним.
+func (s *Service) updatePet(ctx context.Context, chatID int, change func(extPet *gocha.Pet, current *entity.Pet) error) (*entity.Pet, error) {
+	for attempt := 1; ; attempt++ {
+		pet, results, err := s.loadPet(ctx, chatID)
+		if err != nil {
+			return nil, err
+		}
+
+		extPet := PetEntityToGocha(pet)
+		extPet.DecayPercent = decayPercent(pet.Events)
+
+		// Сначала доживаем время с последнего тика, чтобы изменение применялось к актуальным статам.
+		now := time.Now()
+		results = append(results, extPet.Advance(now)...)
+
+		current := withPetMeta(GochaToPetEntity(extPet), pet)
+
+		err = change(extPet, current)
+		if err != nil {
+			return nil, err
+		}
+
+		updated := withPetMeta(GochaToPetEntity(extPet), pet)
+
+		err = s.SavePet(ctx, updated, chatID)
+		if err == nil {
+			if slept, ok := wokeUp(results, current); ok {
+				s.onWakeUp(ctx, chatID, slept, now)
+			}
+
+			return updated, nil
+		}
+
+		if !errors.Is(err, repo.ErrVersionConflict) {
+			return nil, err
+		}
+
+		if attempt == saveAttempts {
+			return nil, ErrConcurrentUpdate
+		}
+
+		s.logger.Debug().Msgf("pet of chat_id %d changed concurrently, retrying", chatID)
+	}
+}
+
+// wokeUp Питомец проснулся сам, пока доживалось время до after, и сколько он проспал: среди results есть
+// завершившийся сон, а сам after жив.
+func wokeUp(results []gocha.Result, after *entity.Pet) (time.Duration, bool) {
+	if after.State == entity.PetDead {
+		return 0, false
+	}
+
+	for _, result := range results {
+		if result.Activity != nil && result.Activity.Kind == gocha.ActivitySleep {
+			return time.Duration(result.Activity.Minutes) * time.Minute, true
+		}
+	}
+
+	return 0, false
+}
+
+// withPetMeta Переносит на пересобранного из gocha питомца поля, которых в gocha нет.
+func withPetMeta(pet, from *entity.Pet) *entity.Pet {
+	pet.ID = from.ID
+	pet.CreatedAt = from.CreatedAt
+	pet.Version = from.Version
+	pet.Cosmetics = from.Cosmetics
+	pet.Events = from.Events
+
+	return pet
 }
 
 func (s *Service) LoadPet(ctx context.Context, chatID int) (*entity.Pet, error) {
 	s.logger.Trace().Msg("load pet")
+	pet, _, err := s.loadPet(ctx, chatID)
+
+	return pet, err
+}
+
+// loadPet Загружает питомца вместе с результатами занятий, завершившихся, пока он доживался при чтении.
+// Чтение ничего не сохраняет, поэтому и побочных эффектов этих результатов здесь нет.
+func (s *Service) loadPet(ctx context.Context, chatID int) (*entity.Pet, []gocha.Result, error) {
 	pet, err := s.repo.LoadPet(ctx, chatID)
 	if err != nil {
 		if errors.Is(err, repo.ErrPetNotFound) {
 			s.logger.Warn().Msgf("Питомец не найден для chat_id: %d", chatID)
 
-			return nil, ErrPetNotFound
+			return nil, nil, ErrPetNotFound
 		}
 
-		return nil, err
+		return nil, nil, err
 	}
 
-	return pet, nil
+	var results []gocha.Result
+
+	if s.cfg.LazyStats {
+		// Статы не пишутся по таймеру: выводим их из последнего снимка и прошедшего времени.
+		pet, results = s.advancePet(ctx, chatID, pet, time.Now())
+	}
+
+	pet.Tricks = trickInfos(pet.Skills)
+	pet.Events = s.chatEvents(ctx, chatID, pet.Name, pet.CreatedAt, time.Now())
+	pet.Cosmetics = withDecor(s.equippedCosmetics(ctx, chatID), pet.Events)
+	fillActivity(pet.Activity, time.Now())
+	describeActions(pet, roleAccess{})
+
+	return pet, results, nil
 }
 
 func (s *Service) SavePet(ctx context.Context, p *entity.Pet, chatID int) error {
@@ -172,41 +323,27 @@ func (s *Service) SavePet(ctx context.Context, p *entity.Pet, chatID int) error
 		return err
 	}
 
-	return nil
-}
-
-// startMonitoringForChat запускает мониторинг для конкретного чата
-func (s *Service) startMonitoringForChat(ctx context.Context, chatID int) {
-	s.monitoringMutex.Lock()
-	defer s.monitoringMutex.Unlock()
-
-	// Если мониторинг уже активен, останавливаем старый
-	if cancelFunc, exists := s.monitoringChats[chatID]; exists {
-		cancelFunc()
-		s.logger.Debug().Msgf("Stopped existing monitoring for chat_id: %d", chatID)
+	if s.cfg.LazyStats {
+		// Новый снимок меняет, когда статы пересекут пороги; пересчёт идёт в фоне, не задерживая ответ.
+		s.reschedules.Add(chatID)
 	}
 
-	// Создаем новый контекст для мониторинга этого чата
-	monitorCtx, cancel := context.WithCancel(ctx)
-	s.monitoringChats[chatID] = cancel
+	return nil
+}
 
-	// Запускаем мониторинг
-	go s.MonitorAndLivePetAny(monitorCtx, chatID)
+// startMonitoringForChat Ставит чат в очередь замеров: первый замер — через интервал обновления.
+func (s *Service) startMonitoringForChat(_ context.Context, chatID int) {
+	s.scheduler.Schedule(chatID, time.Now().Add(s.updateInterval()))
 	s.logger.Info().Msgf("Started monitoring for chat_id: %d", chatID)
 }
 
-// stopMonitoringForChat останавливает мониторинг для конкретного чата
+// stopMonitoringForChat Снимает чат с очереди замеров.
 func (s *Service) stopMonitoringForChat(chatID int) {
-	s.monitoringMutex.Lock()
-	defer s.monitoringMutex.Unlock()
-
-	if cancelFunc, exists := s.monitoringChats[chatID]; exists {
-		cancelFunc()
-		delete(s.monitoringChats, chatID)
-		s.logger.Info().Msgf("Stopped monitoring for chat_id: %d", chatID)
-	}
+	s.scheduler.Remove(chatID)
+	s.logger.Info().Msgf("Stopped monitoring for chat_id: %d", chatID)
 }
 
+// MonitorPetsAll Ставит в очередь все чаты с живыми питомцами и запускает планировщик.
 func (s *Service) MonitorPetsAll(ctx context.Context) error {
 	s.logger.Trace().Msg("monitor pets all")
 
@@ -215,87 +352,131 @@ func (s *Service) MonitorPetsAll(ctx context.Context) error {
 		return fmt.Errorf("can't get chats: %w", err)
 	}
 
-	for _, chatID := range chats {
-		s.startMonitoringForChat(ctx, chatID)
+	// Первые замеры размазываем по интервалу, чтобы после рестарта все чаты не пришли разом.
+	now := time.Now()
+	interval := s.updateInterval()
+
+	for i, chatID := range chats {
+		s.scheduler.Schedule(chatID, now.Add(interval*time.Duration(i+1)/time.Duration(len(chats))))
 	}
 
+	s.scheduler.Run(ctx)
+
 	return nil
 }
 
-func (s *Service) MonitorAndLivePetAny(ctx context.Context, chatID int) {
-	ticker := time.NewTicker(time.Duration(s.cfg.UpdateInterval) * time.Minute)
-	defer ticker.Stop()
-
-	s.logger.Trace().Msgf("Monitoring pet for chat_id: %d", chatID)
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info().Msgf("Monitoring stopped for chat_id: %d", chatID)
-			return
-		case <-ticker.C:
-			pet, err := s.repo.LoadPet(ctx, chatID)
-			if err != nil {
-				if errors.Is(err, repo.ErrPetNotFound) {
-					s.logger.Warn().Msgf("Pet not found for chat_id: %d, stopping monitoring", chatID)
-					s.stopMonitoringForChat(chatID)
-					return
-				}
-				s.logger.Error().Err(err).Msg("can't load pet")
-				continue
-			}
+// SchedulerStats Глубина очереди замеров и отставание планировщика.
+func (s *Service) SchedulerStats() entity.SchedulerStats {
+	return s.scheduler.Stats(time.Now())
+}
 
-			// Обновляем состояние питомца
-			extPet := PetEntityToGocha(pet)
-			extPet.DegradeOverTime(pet.LastUpdated)
-			pet = GochaToPetEntity(extPet)
+// livePets Проживает время питомцев пачки чатов: одна выборка и один пакет сохранений на всю пачку.
+// В ленивом режиме возвращает, когда будить каждый чат в следующий раз; nil — через интервал обновления.
+func (s *Service) livePets(ctx context.Context, chatIDs []int) map[int]time.Time {
+	s.logger.Trace().Msgf("Monitoring pets for %d chats", len(chatIDs))
 
-			// Сохраняем обновленное состояние
-			if err := s.SavePet(ctx, pet, chatID); err != nil {
-				s.logger.Error().Err(err).Msg("can't save pet")
-				continue
-			}
+	pets, err := s.repo.LoadPets(ctx, chatIDs)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load pets")
+
+		return nil
+	}
+
+	for _, chatID := range chatIDs {
+		if _, ok := pets[chatID]; !ok {
+			s.logger.Warn().Msgf("Pet not found for chat_id: %d, stopping monitoring", chatID)
+			s.stopMonitoringForChat(chatID)
+		}
+	}
+
+	now := time.Now()
 
-			// Проверяем и отправляем предупреждения
-			now := time.Now()
-			s.sendWarningIfNeeded(chatID, "health", pet.Health <= 20, "⚠️ Внимание! Здоровье питомца на критическом уровне!", now)
-			s.sendWarningIfNeeded(chatID, "hunger", pet.Hunger >= 80, "⚠️ Внимание! Питомец очень голоден!", now)
-			s.sendWarningIfNeeded(chatID, "happiness", pet.Happiness <= 20, "⚠️ Внимание! Питомец очень несчастен!", now)
-			s.sendWarningIfNeeded(chatID, "energy", pet.Energy <= 20, "⚠️ Внимание! У питомца очень мало энергии!", now)
-			s.sendWarningIfNeeded(chatID, "hygiene", pet.Hygiene <= 20, "⚠️ Внимание! Питомец очень грязный!", now)
+	// В ленивом режиме между пробуждениями замеров не было: восстанавливаем их по снимку до того, как его
+	// проживём, чтобы отчёт, дневник, задания и графики видели весь промежуток.
+	replayed := make(map[int][]TickEvent, len(pets))
+	results := make(map[int][]gocha.Result, len(pets))
+
+	for chatID, pet := range pets {
+		if s.cfg.LazyStats {
+			replayed[chatID] = s.replayTicks(ctx, chatID, pet, now)
 		}
+
+		pets[chatID], results[chatID] = s.livePet(ctx, chatID, pet, now)
+	}
+
+	// Сохраняем обновленное состояние
+	conflicts, err := s.repo.SavePets(ctx, pets)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save pets")
+
+		return nil
+	}
+
+	// Этих питомцев успело изменить действие: их замер устарел, следующий проживёт время от свежего снимка.
+	for _, chatID := range conflicts {
+		s.logger.Debug().Msgf("pet of chat_id %d changed during tick, skipping", chatID)
+		delete(pets, chatID)
+	}
+
+	s.recordStatSamples(ctx, pets, replayed, now)
+
+	for chatID, pet := range pets {
+		if slept, ok := wokeUp(results[chatID], pet); ok {
+			s.onWakeUp(ctx, chatID, slept, now)
+		}
+
+		// Проверяем и отправляем предупреждения
+		settings := s.notifySettings(ctx, chatID)
+		triggered := triggeredAlerts(settings, pet)
+		s.sendWarnings(ctx, chatID, pet, settings, triggered, now)
+
+		s.onTick(ctx, TickEvent{ChatID: chatID, Pet: pet, Alerting: len(triggered) > 0, At: now, Replayed: replayed[chatID]})
+	}
+
+	if !s.cfg.LazyStats {
+		return nil
+	}
+
+	next := make(map[int]time.Time, len(pets))
+	for chatID, pet := range pets {
+		next[chatID] = s.nextWakeUp(ctx, chatID, pet, now)
 	}
+
+	return next
 }
 
-func (s *Service) sendWarningIfNeeded(chatID int, alertType string, condition bool, message string, now time.Time) {
-	if !condition {
-		return
+// livePet Проживает время одного питомца до now с поправками идущих событий календаря.
+func (s *Service) livePet(ctx context.Context, chatID int, pet *entity.Pet, now time.Time) (*entity.Pet, []gocha.Result) {
+	pet, results := s.advancePet(ctx, chatID, pet, now)
+	for _, result := range results {
+		s.logger.Info().Msgf("Activity completed for chat_id: %d: %s", chatID, result.Message)
 	}
 
-	// Запрашиваем время последнего предупреждения из БД
-	// lastAlert, err := s.repo.GetLastAlert(chatID, alertType)
-	// if err != nil {
-	//	s.logger.Error().Err(err).Msg("Ошибка получения последнего предупреждения")
-	//	return
-	//}
+	return pet, results
+}
+
+// advancePet Доживает копию питомца до now и возвращает её вместе с результатами завершившихся занятий.
+func (s *Service) advancePet(ctx context.Context, chatID int, pet *entity.Pet, now time.Time) (*entity.Pet, []gocha.Result) {
+	events := s.chatEvents(ctx, chatID, pet.Name, pet.CreatedAt, now)
+
+	extPet := PetEntityToGocha(pet)
+	extPet.DecayPercent = decayPercent(events)
+	results := extPet.Advance(now)
 
-	// Проверяем, прошло ли достаточно времени с момента последнего предупреждения
-	// if now.Sub(lastAlert) > time.Duration(s.cfg.AlertCooldown)*time.Minute {
-	//	_, _ = s.bot.SendMessage(tu.Message(tu.ID(int64(chatID)), message))
-	//	_ = s.repo.UpdateLastAlert(chatID, alertType, now) // Обновляем в БД
-	//}
+	advanced := withPetMeta(GochaToPetEntity(extPet), pet)
+	advanced.Events = events
+
+	return advanced, results
 }
 
-// Graceful shutdown - останавливает все мониторинги
-func (s *Service) Stop() {
-	s.monitoringMutex.Lock()
-	defer s.monitoringMutex.Unlock()
+func (s *Service) updateInterval() time.Duration {
+	return time.Duration(s.cfg.UpdateInterval) * time.Minute
+}
 
-	for chatID, cancelFunc := range s.monitoringChats {
-		cancelFunc()
-		s.logger.Info().Msgf("Stopped monitoring for chat_id: %d during shutdown", chatID)
-	}
-	s.monitoringChats = make(map[int]context.CancelFunc)
+// Graceful shutdown - останавливает планировщик, дождавшись обрабатываемых пачек
+func (s *Service) Stop() {
+	s.scheduler.Stop()
+	s.logger.Info().Msg("Stopped monitoring during shutdown")
 }
 
 func PetEntityToGocha(pet *entity.Pet) *gocha.Pet {
@@ -308,7 +489,31 @@ func PetEntityToGocha(pet *entity.Pet) *gocha.Pet {
 		Hygiene:        pet.Hygiene,
 		State:          gocha.State(pet.State),
 		SleepStartTime: pet.SleepStartTime,
+		Skills:         make(map[gocha.Trick]gocha.Skill, len(pet.Skills)),
+		LastUpdated:    pet.LastUpdated,
 	}
+
+	for name, skill := range pet.Skills {
+		outPet.Skills[gocha.Trick(name)] = gocha.Skill{Level: skill.Level, LastPracticed: skill.LastPracticed}
+	}
+
+	outPet.RecentCare = make(map[gocha.CareAction][]time.Time, len(pet.RecentCare))
+	for action, times := range pet.RecentCare {
+		outPet.RecentCare[gocha.CareAction(action)] = times
+	}
+
+	if pet.Activity != nil {
+		outPet.Activity = &gocha.Activity{
+			Kind:      gocha.ActivityKind(pet.Activity.Kind),
+			StartedAt: pet.Activity.StartedAt,
+			Duration:  pet.Activity.Duration,
+
+			Minutes:      pet.Activity.Minutes,
+			QualityTotal: pet.Activity.QualityTotal,
+			EnergyCarry:  pet.Activity.EnergyCarry,
+		}
+	}
+
 	outPet.EditConfig(gocha.Config{
 		HungerDecayRate:    pet.Config.HungerDecayRate,
 		EnergyDecayRate:    pet.Config.EnergyDecayRate,
@@ -322,6 +527,30 @@ func PetEntityToGocha(pet *entity.Pet) *gocha.Pet {
 func GochaToPetEntity(pet *gocha.Pet) *entity.Pet {
 	cfg := pet.GetConfig()
 
+	skills := make(map[string]entity.Skill, len(pet.Skills))
+	for trick, skill := range pet.Skills {
+		skills[string(trick)] = entity.Skill{Level: skill.Level, LastPracticed: skill.LastPracticed}
+	}
+
+	recentCare := make(map[string][]time.Time, len(pet.RecentCare))
+	for action, times := range pet.RecentCare {
+		recentCare[string(action)] = times
+	}
+
+	var activity *entity.Activity
+	if pet.Activity != nil {
+		activity = &entity.Activity{
+			Kind:      string(pet.Activity.Kind),
+			StartedAt: pet.Activity.StartedAt,
+			Duration:  pet.Activity.Duration,
+
+			Minutes:      pet.Activity.Minutes,
+			QualityTotal: pet.Activity.QualityTotal,
+			EnergyCarry:  pet.Activity.EnergyCarry,
+		}
+		fillActivity(activity, time.Now())
+	}
+
 	return &entity.Pet{
 		Name:           pet.Name,
 		Health:         pet.Health,
@@ -331,12 +560,59 @@ func GochaToPetEntity(pet *gocha.Pet) *entity.Pet {
 		Hygiene:        pet.Hygiene,
 		State:          entity.State(pet.State),
 		SleepStartTime: pet.SleepStartTime,
+		Skills:         skills,
+		Tricks:         trickInfos(skills),
+		Activity:       activity,
+		RecentCare:     recentCare,
 		Config: entity.PetConfig{
 			HungerDecayRate:    cfg.HungerDecayRate,
 			EnergyDecayRate:    cfg.EnergyDecayRate,
 			HygieneDecayRate:   cfg.HygieneDecayRate,
 			HappinessDecayRate: cfg.HappinessDecayRate,
 		},
-		LastUpdated: time.Now(),
+		LastUpdated: pet.LastUpdated,
+	}
+}
+
+// trickInfos Собирает уровни всех трюков с учётом забывания.
+func trickInfos(skills map[string]entity.Skill) []entity.TrickInfo {
+	now := time.Now()
+	tricks := make([]entity.TrickInfo, 0, len(gocha.Tricks))
+
+	for _, trick := range gocha.Tricks {
+		stored := skills[string(trick)]
+		level := gocha.Skill{Level: stored.Level, LastPracticed: stored.LastPracticed}.Effective(now)
+
+		tricks = append(tricks, entity.TrickInfo{
+			Name:       string(trick),
+			Title:      trick.Title(),
+			Level:      level,
+			CanPerform: level >= gocha.MinTrickLevel,
+		})
+	}
+
+	return tricks
+}
+
+// fillActivity Дополняет занятие названием и оставшимся временем.
+func fillActivity(activity *entity.Activity, now time.Time) {
+	if activity == nil {
+		return
+	}
+
+	def, _ := gocha.LookupActivity(gocha.ActivityKind(activity.Kind))
+	ext := gocha.Activity{
+		StartedAt:    activity.StartedAt,
+		Duration:     activity.Duration,
+		Minutes:      activity.Minutes,
+		QualityTotal: activity.QualityTotal,
+	}
+
+	activity.Title = def.Title
+	activity.EndsAt = ext.EndsAt()
+	activity.RemainingSeconds = int(ext.Remaining(now).Seconds())
+
+	if def.Kind == gocha.ActivitySleep {
+		activity.Quality = ext.Quality()
 	}
 }
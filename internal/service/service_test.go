@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+	"gocha/pkg/gocha"
+
+	"github.com/rs/zerolog"
+)
+
+func TestService_ConcurrentActionsAndTicks(t *testing.T) {
+	t.Parallel()
+
+	const (
+		chatID  = 1
+		workers = 8
+		actions = 20
+	)
+
+	logger := zerolog.Nop()
+	store := newMemoryRepo()
+	store.putPet(chatID, &entity.Pet{
+		ID:          1,
+		Name:        "Гоча",
+		Health:      100,
+		Hunger:      100,
+		Happiness:   100,
+		Energy:      100,
+		State:       entity.PetAlive,
+		Config:      entity.PetConfig{HungerDecayRate: 1, EnergyDecayRate: 1, HygieneDecayRate: 1, HappinessDecayRate: 1},
+		LastUpdated: time.Now().Add(-time.Hour),
+		CreatedAt:   time.Now().Add(-time.Hour),
+	})
+	s := NewService(&config.Configuration{UpdateInterval: 1, AlertCooldown: 60}, &logger, store, nil)
+
+	ctx := context.Background()
+	stop := make(chan struct{})
+
+	var ticks sync.WaitGroup
+
+	ticks.Add(1)
+
+	go func() {
+		defer ticks.Done()
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				s.livePets(ctx, []int{chatID})
+			}
+		}
+	}()
+
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int64
+	)
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range actions {
+				_, err := s.PerformAction(ctx, chatID, entity.Actor{ID: 1}, "clean", nil)
+
+				switch {
+				case err == nil:
+					succeeded.Add(1)
+				case !errors.Is(err, ErrConcurrentUpdate):
+					t.Errorf("PerformAction() unexpected error = %v", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(stop)
+	ticks.Wait()
+
+	// Каждое успешное мытьё запоминается в недавнем уходе: если замер или другое действие затёрли
+	// снимок, записей станет меньше, чем успешных действий.
+	cleans := len(store.pet(chatID).RecentCare[string(gocha.CareClean)])
+	if cleans != int(succeeded.Load()) {
+		t.Errorf("lost updates: %d successful cleans, %d remembered", succeeded.Load(), cleans)
+	}
+
+	if succeeded.Load() == 0 {
+		t.Errorf("no action succeeded")
+	}
+
+	t.Logf("%d of %d actions succeeded, %d conflicts retried", succeeded.Load(), workers*actions, store.conflicts)
+}
+
+func TestTrain_EnergyThreshold(t *testing.T) {
+	t.Parallel()
+
+	train, _ := LookupAction("train")
+
+	for _, energy := range []int{gocha.TrainEnergyCost - 1, gocha.TrainEnergyCost, gocha.TrainEnergyCost + 10} {
+		pet := &entity.Pet{State: entity.PetAlive, Health: 100, Hunger: 100, Happiness: 100, Energy: energy, Hygiene: 100}
+
+		allowed, _ := train.Precondition(pet)
+
+		// Реестр и движок должны сходиться: что разрешено, на то движок и тратит энергию.
+		extPet := PetEntityToGocha(pet)
+		extPet.Train(gocha.TrickSit)
+
+		if trained := extPet.Energy < energy; allowed != trained {
+			t.Errorf("energy %d: precondition allows = %v, trained = %v", energy, allowed, trained)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gocha/internal/entity"
+	"gocha/pkg/gocha"
+)
+
+var ErrBadSettings = errors.New("неверные настройки уведомлений")
+
+// Тихие часы по умолчанию: ночью предупреждения ждут утра.
+const (
+	defaultQuietFrom = 23
+	defaultQuietTo   = 8
+)
+
+// NotifySettings Настройки предупреждений чата.
+func (s *Service) NotifySettings(ctx context.Context, chatID int) entity.NotifySettings {
+	s.logger.Trace().Msg("notify settings")
+
+	settings := s.notifySettings(ctx, chatID)
+	settings.Timezone = s.chatLocation(ctx, chatID).String()
+
+	return settings
+}
+
+// UpdateNotifySettings Сохраняет настройки предупреждений чата. Меняются только переданные в update поля и
+// виды предупреждений, остальное остаётся как было.
+func (s *Service) UpdateNotifySettings(ctx context.Context, chatID int, update entity.NotifySettingsUpdate) (entity.NotifySettings, error) {
+	s.logger.Trace().Msg("update notify settings")
+
+	settings := s.notifySettings(ctx, chatID)
+
+	err := mergeNotifySettings(&settings, update)
+	if err != nil {
+		return entity.NotifySettings{}, err
+	}
+
+	err = s.repo.SaveNotifySettings(ctx, chatID, settings)
+	if err != nil {
+		return entity.NotifySettings{}, err
+	}
+
+	if settings.Mode != entity.AlertDigest {
+		// Копившаяся сводка в обычном режиме уже не уйдёт.
+		s.digests.drop(chatID)
+	}
+
+	if s.cfg.LazyStats {
+		// Пороги, тихие часы и режим меняют, когда будить чат.
+		s.rescheduleLazy(ctx, chatID)
+	}
+
+	settings.Timezone = s.chatLocation(ctx, chatID).String()
+
+	return settings, nil
+}
+
+// notifySettings Настройки чата поверх значений по умолчанию; при ошибке чтения — значения по умолчанию.
+func (s *Service) notifySettings(ctx context.Context, chatID int) entity.NotifySettings {
+	settings := defaultNotifySettings()
+
+	stored, err := s.repo.GetNotifySettings(ctx, chatID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load notify settings")
+
+		return settings
+	}
+
+	if stored != nil {
+		// Сохранённые настройки проверялись при записи; новые виды предупреждений получают значения по умолчанию.
+		_ = mergeNotifySettings(&settings, settingsUpdate(*stored))
+	}
+
+	return settings
+}
+
+func defaultNotifySettings() entity.NotifySettings {
+	settings := entity.NotifySettings{
+		QuietFrom: defaultQuietFrom,
+		QuietTo:   defaultQuietTo,
+		Mode:      entity.AlertInstant,
+		Alerts:    make([]entity.AlertPreference, 0, len(alertRules)),
+	}
+
+	for _, rule := range alertRules {
+		settings.Alerts = append(settings.Alerts, entity.AlertPreference{
+			Type:      rule.Type,
+			Title:     rule.Title,
+			Enabled:   true,
+			Threshold: defaultAlertThreshold,
+		})
+	}
+
+	return settings
+}
+
+// settingsUpdate Изменение, задающее все поля settings.
+func settingsUpdate(settings entity.NotifySettings) entity.NotifySettingsUpdate {
+	update := entity.NotifySettingsUpdate{
+		QuietFrom: &settings.QuietFrom,
+		QuietTo:   &settings.QuietTo,
+		Mode:      &settings.Mode,
+		Alerts:    make([]entity.AlertPreferenceUpdate, 0, len(settings.Alerts)),
+	}
+
+	for _, pref := range settings.Alerts {
+		update.Alerts = append(update.Alerts, entity.AlertPreferenceUpdate{
+			Type:      pref.Type,
+			Enabled:   &pref.Enabled,
+			Threshold: &pref.Threshold,
+		})
+	}
+
+	return update
+}
+
+// mergeNotifySettings Проверяет update и переносит в settings его заданные поля. При ошибке settings не
+// меняются.
+func mergeNotifySettings(settings *entity.NotifySettings, update entity.NotifySettingsUpdate) error {
+	for _, hour := range []*int{update.QuietFrom, update.QuietTo} {
+		if hour != nil && (*hour < 0 || *hour > 23) {
+			return fmt.Errorf("%w: часы тихого времени — от 0 до 23", ErrBadSettings)
+		}
+	}
+
+	if update.Mode != nil && *update.Mode != entity.AlertInstant && *update.Mode != entity.AlertDigest {
+		return fmt.Errorf("%w: режим %q", ErrBadSettings, *update.Mode)
+	}
+
+	for _, pref := range update.Alerts {
+		if pref.Threshold != nil && (*pref.Threshold < gocha.MinStatValue || *pref.Threshold > gocha.MaxStatValue) {
+			return fmt.Errorf("%w: порог %d", ErrBadSettings, *pref.Threshold)
+		}
+
+		if alertPreference(*settings, pref.Type) < 0 {
+			return fmt.Errorf("%w: неизвестное предупреждение %q", ErrBadSettings, pref.Type)
+		}
+	}
+
+	if update.QuietFrom != nil {
+		settings.QuietFrom = *update.QuietFrom
+	}
+
+	if update.QuietTo != nil {
+		settings.QuietTo = *update.QuietTo
+	}
+
+	if update.Mode != nil {
+		settings.Mode = *update.Mode
+	}
+
+	for _, pref := range update.Alerts {
+		i := alertPreference(*settings, pref.Type)
+
+		if pref.Enabled != nil {
+			settings.Alerts[i].Enabled = *pref.Enabled
+		}
+
+		if pref.Threshold != nil {
+			settings.Alerts[i].Threshold = *pref.Threshold
+		}
+	}
+
+	return nil
+}
+
+func alertPreference(settings entity.NotifySettings, alertType string) int {
+	for i, pref := range settings.Alerts {
+		if pref.Type == alertType {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// quietHours Тихие ли часы в момент local по времени чата.
+func quietHours(settings entity.NotifySettings, local time.Time) bool {
+	hour := local.Hour()
+
+	switch {
+	case settings.QuietFrom == settings.QuietTo:
+		return false
+	case settings.QuietFrom < settings.QuietTo:
+		return hour >= settings.QuietFrom && hour < settings.QuietTo
+	default:
+		return hour >= settings.QuietFrom || hour < settings.QuietTo
+	}
+}
+
+// quietEnd Когда кончатся тихие часы, идущие в момент local.
+func quietEnd(settings entity.NotifySettings, local time.Time) time.Time {
+	end := time.Date(local.Year(), local.Month(), local.Day(), settings.QuietTo, 0, 0, 0, local.Location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return end
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestMergeNotifySettings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		update  entity.NotifySettingsUpdate
+		change  func(settings *entity.NotifySettings)
+		wantErr error
+	}{
+		{name: "пустое изменение ничего не трогает"},
+		{
+			name:   "только начало тихих часов",
+			update: entity.NotifySettingsUpdate{QuietFrom: ptr(22)},
+			change: func(settings *entity.NotifySettings) { settings.QuietFrom = 22 },
+		},
+		{
+			name:   "только режим",
+			update: entity.NotifySettingsUpdate{Mode: ptr(entity.AlertDigest)},
+			change: func(settings *entity.NotifySettings) { settings.Mode = entity.AlertDigest },
+		},
+		{
+			name:   "выключение вида не сбрасывает порог",
+			update: entity.NotifySettingsUpdate{Alerts: []entity.AlertPreferenceUpdate{{Type: "hunger", Enabled: ptr(false)}}},
+			change: func(settings *entity.NotifySettings) { settings.Alerts[1].Enabled = false },
+		},
+		{
+			name:   "порог не включает выключенный вид",
+			update: entity.NotifySettingsUpdate{Alerts: []entity.AlertPreferenceUpdate{{Type: "energy", Threshold: ptr(35)}}},
+			change: func(settings *entity.NotifySettings) { settings.Alerts[3].Threshold = 35 },
+		},
+		{
+			name:    "час вне суток",
+			update:  entity.NotifySettingsUpdate{QuietFrom: ptr(22), QuietTo: ptr(24)},
+			wantErr: ErrBadSettings,
+		},
+		{
+			name:    "неизвестный режим",
+			update:  entity.NotifySettingsUpdate{Mode: ptr(entity.AlertMode("weekly"))},
+			wantErr: ErrBadSettings,
+		},
+		{
+			name: "ошибка в одном виде не меняет остальные",
+			update: entity.NotifySettingsUpdate{QuietFrom: ptr(1), Alerts: []entity.AlertPreferenceUpdate{
+				{Type: "hunger", Threshold: ptr(30)},
+				{Type: "boredom", Enabled: ptr(false)},
+			}},
+			wantErr: ErrBadSettings,
+		},
+		{
+			name:    "порог больше максимума",
+			update:  entity.NotifySettingsUpdate{Alerts: []entity.AlertPreferenceUpdate{{Type: "hunger", Threshold: ptr(101)}}},
+			wantErr: ErrBadSettings,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			settings := defaultNotifySettings()
+			settings.Alerts[3].Enabled = false
+
+			want := defaultNotifySettings()
+			want.Alerts[3].Enabled = false
+
+			if tt.change != nil {
+				tt.change(&want)
+			}
+
+			err := mergeNotifySettings(&settings, tt.update)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("mergeNotifySettings() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(settings, want) {
+				t.Errorf("settings = %+v, want %+v", settings, want)
+			}
+		})
+	}
+}
+
+func TestSettingsUpdate(t *testing.T) {
+	t.Parallel()
+
+	stored := defaultNotifySettings()
+	stored.QuietFrom, stored.QuietTo, stored.Mode = 0, 0, entity.AlertDigest
+	stored.Alerts[0].Enabled, stored.Alerts[2].Threshold = false, 5
+
+	settings := defaultNotifySettings()
+
+	err := mergeNotifySettings(&settings, settingsUpdate(stored))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(settings, stored) {
+		t.Errorf("settings = %+v, want %+v", settings, stored)
+	}
+}
+
+func TestService_UpdateNotifySettings(t *testing.T) {
+	t.Parallel()
+
+	stored := defaultNotifySettings()
+	stored.QuietFrom, stored.QuietTo = 22, 7
+	stored.Alerts[1].Threshold = 40
+
+	store := newMemoryRepo()
+	store.timezones[1] = "UTC"
+	store.settings[1] = stored
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, nil)
+
+	// Меню меняет только режим: тихие часы и пороги, сохранённые раньше, остаются.
+	got, err := s.UpdateNotifySettings(context.Background(), 1, entity.NotifySettingsUpdate{Mode: ptr(entity.AlertDigest)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.QuietFrom != 22 || got.QuietTo != 7 || got.Alerts[1].Threshold != 40 || got.Mode != entity.AlertDigest {
+		t.Errorf("UpdateNotifySettings() = %+v", got)
+	}
+
+	if got.Timezone != "UTC" {
+		t.Errorf("timezone = %q, want UTC", got.Timezone)
+	}
+
+	if saved := store.settings[1]; saved.QuietFrom != 22 || saved.Mode != entity.AlertDigest {
+		t.Errorf("saved settings = %+v", saved)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		from, to  int
+		quiet     []int
+		notQuiet  []int
+		wantEndAt map[int]int // Час, в который спросили, — час конца тихих часов (24+ — уже завтра).
+	}{
+		{
+			name:      "через полночь",
+			from:      23,
+			to:        8,
+			quiet:     []int{23, 0, 3, 7},
+			notQuiet:  []int{8, 12, 22},
+			wantEndAt: map[int]int{23: 32, 0: 8, 7: 8},
+		},
+		{
+			name:      "внутри дня",
+			from:      13,
+			to:        15,
+			quiet:     []int{13, 14},
+			notQuiet:  []int{12, 15, 0},
+			wantEndAt: map[int]int{13: 15, 14: 15},
+		},
+		{
+			name:     "выключены",
+			from:     5,
+			to:       5,
+			notQuiet: []int{0, 5, 12, 23},
+		},
+	}
+
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			settings := entity.NotifySettings{QuietFrom: tt.from, QuietTo: tt.to}
+
+			for _, hour := range tt.quiet {
+				if !quietHours(settings, day.Add(time.Duration(hour)*time.Hour+30*time.Minute)) {
+					t.Errorf("quietHours(%02d:30) = false, want true", hour)
+				}
+			}
+
+			for _, hour := range tt.notQuiet {
+				if quietHours(settings, day.Add(time.Duration(hour)*time.Hour)) {
+					t.Errorf("quietHours(%02d:00) = true, want false", hour)
+				}
+			}
+
+			for hour, endAt := range tt.wantEndAt {
+				got := quietEnd(settings, day.Add(time.Duration(hour)*time.Hour+30*time.Minute))
+				if want := day.Add(time.Duration(endAt) * time.Hour); !got.Equal(want) {
+					t.Errorf("quietEnd(%02d:30) = %v, want %v", hour, got, want)
+				}
+			}
+		})
+	}
+}
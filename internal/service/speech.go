@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+const rememberedPhrases = 5 // Столько последних фраз питомец не повторяет.
+
+// phraseMemory Последние сказанные фразы по чатам.
+type phraseMemory struct {
+	mu     sync.Mutex
+	recent map[int][]string
+}
+
+// Speak Заполняет реплику питомца на языке locale с учётом времени суток чата.
+func (s *Service) Speak(ctx context.Context, chatID int, pet *entity.Pet, locale string) string {
+	if pet == nil {
+		return ""
+	}
+
+	now := time.Now().In(s.chatLocation(ctx, chatID))
+
+	s.phrases.mu.Lock()
+	defer s.phrases.mu.Unlock()
+
+	recent := s.phrases.recent[chatID]
+
+	phrase := pet.Speak(normalizeLocale(locale), now, recent, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	if phrase == "" {
+		return ""
+	}
+
+	recent = append(recent, phrase)
+	if len(recent) > rememberedPhrases {
+		recent = recent[len(recent)-rememberedPhrases:]
+	}
+
+	s.phrases.recent[chatID] = recent
+
+	return phrase
+}
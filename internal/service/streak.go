@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gocha/internal/entity"
+)
+
+const (
+	chatStreakUser = 0 // user_id серии и кошелька всего чата.
+	freezeEvery    = 7 // Каждые столько дней серии дают заморозку.
+	maxFreezes     = 2
+)
+
+// streakMilestone Награда за серию заданной длины.
+type streakMilestone struct {
+	Days  int
+	Coins int
+}
+
+var streakMilestones = []streakMilestone{
+	{Days: 3, Coins: 10},
+	{Days: 7, Coins: 30},
+	{Days: 14, Coins: 70},
+	{Days: 30, Coins: 200},
+	{Days: 100, Coins: 1000},
+}
+
+// Streaks Серии чата и пользователя.
+func (s *Service) Streaks(ctx context.Context, chatID int, actor entity.Actor) (entity.StreakInfo, error) {
+	s.logger.Trace().Msg("streaks")
+
+	today := dayOf(time.Now().In(s.chatLocation(ctx, chatID)))
+
+	chat, err := s.repo.GetStreak(ctx, chatID, chatStreakUser)
+	if err != nil {
+		return entity.StreakInfo{}, err
+	}
+
+	user, err := s.repo.GetStreak(ctx, chatID, actor.ID)
+	if err != nil {
+		return entity.StreakInfo{}, err
+	}
+
+	coins, err := s.repo.GetCoins(ctx, chatID, actor.ID)
+	if err != nil {
+		return entity.StreakInfo{}, err
+	}
+
+	user = currentStreak(user, today)
+
+	return entity.StreakInfo{
+		Chat:      currentStreak(chat, today),
+		User:      user,
+		Coins:     coins,
+		Milestone: daysToMilestone(user.Current),
+	}, nil
+}
+
+// recordStreaks Продлевает серии чата и пользователя и возвращает сообщения о наградах. Дни считаются по часовому
+// поясу чата.
+func (s *Service) recordStreaks(ctx context.Context, chatID int, actor entity.Actor, now time.Time) []string {
+	today := dayOf(now.In(s.chatLocation(ctx, chatID)))
+	notices := s.recordStreak(ctx, chatID, chatStreakUser, "Серия чата", today)
+
+	if actor.ID != chatStreakUser {
+		notices = append(notices, s.recordStreak(ctx, chatID, actor.ID, "Ваша серия", today)...)
+	}
+
+	return notices
+}
+
+func (s *Service) recordStreak(ctx context.Context, chatID int, userID int64, title string, today time.Time) []string {
+	streak, err := s.repo.GetStreak(ctx, chatID, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't load streak")
+
+		return nil
+	}
+
+	next, extended := extendStreak(streak, today)
+	if !extended {
+		return nil
+	}
+
+	// Серию продлевает и награждает только одно из параллельных действий дня: то, чьё сохранение прошло.
+	extended, err = s.repo.ExtendStreak(ctx, chatID, userID, streak.LastDay, next)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("can't save streak")
+
+		return nil
+	}
+
+	if !extended {
+		return nil
+	}
+
+	notices := []string{fmt.Sprintf("🔥 %s: %d дн.", title, next.Current)}
+
+	if next.Freezes < streak.Freezes {
+		notices = append(notices, fmt.Sprintf("🧊 Заморозка спасла серию (осталось %d)", next.Freezes))
+	}
+
+	if next.Freezes > streak.Freezes && next.Current > streak.Current {
+		notices = append(notices, "🧊 Получена заморозка серии")
+	}
+
+	for _, milestone := range streakMilestones {
+		if next.Current != milestone.Days {
+			continue
+		}
+
+		_, err = s.repo.AddCoins(ctx, chatID, userID, milestone.Coins)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("can't reward streak")
+
+			continue
+		}
+
+		notices = append(notices, fmt.Sprintf("🏆 %s %d дн.! Награда: %d 🪙", title, milestone.Days, milestone.Coins))
+	}
+
+	return notices
+}
+
+// extendStreak Продлевает серию на день today. Пропущенные дни списываются с заморозок,
+// если их не хватает — серия начинается заново.
+func extendStreak(streak entity.Streak, today time.Time) (entity.Streak, bool) {
+	if !streak.LastDay.IsZero() && sameDay(streak.LastDay, today) {
+		return streak, false
+	}
+
+	missed := daysBetween(streak.LastDay, today) - 1
+
+	switch {
+	case streak.LastDay.IsZero() || missed > streak.Freezes:
+		streak.Current = 1
+	case missed > 0:
+		streak.Freezes -= missed
+		streak.Current++
+	default:
+		streak.Current++
+	}
+
+	if streak.Current%freezeEvery == 0 && streak.Freezes < maxFreezes {
+		streak.Freezes++
+	}
+
+	streak.Best = max(streak.Best, streak.Current)
+	streak.LastDay = today
+
+	return streak, true
+}
+
+// currentStreak Серия на сегодня: если пропуск уже не покрыть заморозками, она сгорела.
+func currentStreak(streak entity.Streak, today time.Time) entity.Streak {
+	if streak.LastDay.IsZero() {
+		return streak
+	}
+
+	if daysBetween(streak.LastDay, today)-1 > streak.Freezes {
+		streak.Current = 0
+	}
+
+	return streak
+}
+
+func daysToMilestone(current int) int {
+	for _, milestone := range streakMilestones {
+		if milestone.Days > current {
+			return milestone.Days - current
+		}
+	}
+
+	return 0
+}
+
+// daysBetween Число календарных дней между датами без учёта часового пояса.
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(b.Sub(a).Hours() / 24)
+}
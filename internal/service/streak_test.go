@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gocha/internal/config"
+	"gocha/internal/entity"
+
+	"github.com/rs/zerolog"
+)
+
+func TestExtendStreak(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		streak   entity.Streak
+		today    time.Time
+		want     entity.Streak
+		extended bool
+	}{
+		{
+			name:     "первый день",
+			today:    day(1),
+			want:     entity.Streak{Current: 1, Best: 1, LastDay: day(1)},
+			extended: true,
+		},
+		{
+			name:   "второй раз за день серию не продлевает",
+			streak: entity.Streak{Current: 3, Best: 3, LastDay: day(5)},
+			today:  day(5),
+			want:   entity.Streak{Current: 3, Best: 3, LastDay: day(5)},
+		},
+		{
+			name:     "следующий день",
+			streak:   entity.Streak{Current: 3, Best: 5, LastDay: day(5)},
+			today:    day(6),
+			want:     entity.Streak{Current: 4, Best: 5, LastDay: day(6)},
+			extended: true,
+		},
+		{
+			name:     "каждая неделя даёт заморозку",
+			streak:   entity.Streak{Current: 6, Best: 6, LastDay: day(6)},
+			today:    day(7),
+			want:     entity.Streak{Current: 7, Best: 7, Freezes: 1, LastDay: day(7)},
+			extended: true,
+		},
+		{
+			name:     "заморозок не больше максимума",
+			streak:   entity.Streak{Current: 13, Best: 13, Freezes: maxFreezes, LastDay: day(13)},
+			today:    day(14),
+			want:     entity.Streak{Current: 14, Best: 14, Freezes: maxFreezes, LastDay: day(14)},
+			extended: true,
+		},
+		{
+			name:     "заморозка покрывает пропуск",
+			streak:   entity.Streak{Current: 8, Best: 8, Freezes: 2, LastDay: day(8)},
+			today:    day(10),
+			want:     entity.Streak{Current: 9, Best: 9, Freezes: 1, LastDay: day(10)},
+			extended: true,
+		},
+		{
+			name:     "пропуск длиннее заморозок сбрасывает серию",
+			streak:   entity.Streak{Current: 8, Best: 8, Freezes: 1, LastDay: day(8)},
+			today:    day(11),
+			want:     entity.Streak{Current: 1, Best: 8, Freezes: 1, LastDay: day(11)},
+			extended: true,
+		},
+		{
+			name:     "смена месяца",
+			streak:   entity.Streak{Current: 2, Best: 2, LastDay: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+			today:    day(1),
+			want:     entity.Streak{Current: 3, Best: 3, LastDay: day(1)},
+			extended: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, extended := extendStreak(tt.streak, tt.today)
+			if extended != tt.extended {
+				t.Errorf("extendStreak() extended = %v, want %v", extended, tt.extended)
+			}
+
+			if got != tt.want {
+				t.Errorf("extendStreak() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		streak entity.Streak
+		want   int
+	}{
+		{
+			name:   "продлена сегодня",
+			streak: entity.Streak{Current: 5, LastDay: day(10)},
+			want:   5,
+		},
+		{
+			name:   "продлена вчера",
+			streak: entity.Streak{Current: 5, LastDay: day(9)},
+			want:   5,
+		},
+		{
+			name:   "пропуск покрыт заморозкой",
+			streak: entity.Streak{Current: 5, Freezes: 1, LastDay: day(8)},
+			want:   5,
+		},
+		{
+			name:   "серия сгорела",
+			streak: entity.Streak{Current: 5, Freezes: 1, LastDay: day(7)},
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := currentStreak(tt.streak, day(10)); got.Current != tt.want {
+				t.Errorf("currentStreak().Current = %d, want %d", got.Current, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_RecordStreaks_ChatTimezone(t *testing.T) {
+	t.Parallel()
+
+	const userID = 42
+
+	// 22:30 UTC 1 марта — во Владивостоке (UTC+10) это уже утро 2 марта.
+	now := time.Date(2024, time.March, 1, 22, 30, 0, 0, time.UTC)
+	store := newMemoryRepo()
+	store.timezones[1] = "Asia/Vladivostok"
+
+	for _, user := range []int64{chatStreakUser, userID} {
+		store.streaks[walletKey{1, user}] = entity.Streak{Current: 2, Best: 2, LastDay: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	}
+
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, nil)
+
+	s.recordStreaks(context.Background(), 1, entity.Actor{ID: userID}, now)
+
+	for _, user := range []int64{chatStreakUser, userID} {
+		if got := store.streaks[walletKey{1, user}]; got.Current != 3 || got.LastDay.Format(time.DateOnly) != "2024-03-02" {
+			t.Errorf("streak of %d = %+v, want 3 days up to 2024-03-02", user, got)
+		}
+	}
+
+	if coins := store.coins[walletKey{1, userID}]; coins != streakMilestones[0].Coins {
+		t.Errorf("personal coins = %d, want %d", coins, streakMilestones[0].Coins)
+	}
+}
+
+func TestService_RecordStreaks_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		userID  = 42
+		actions = 8
+	)
+
+	now := time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+	// Сегодня серия дорастает до первой награды: её должно получить ровно одно из одновременных действий.
+	store := newMemoryRepo()
+	store.timezones[1] = "UTC"
+	store.streaks[walletKey{1, userID}] = entity.Streak{Current: streakMilestones[0].Days - 1, LastDay: yesterday}
+	logger := zerolog.Nop()
+	s := NewService(&config.Configuration{}, &logger, store, nil)
+
+	var wg sync.WaitGroup
+
+	for range actions {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			s.recordStreak(context.Background(), 1, userID, "Ваша серия", dayOf(now))
+		}()
+	}
+
+	wg.Wait()
+
+	if got := store.streaks[walletKey{1, userID}].Current; got != streakMilestones[0].Days {
+		t.Errorf("streak = %d, want %d", got, streakMilestones[0].Days)
+	}
+
+	if coins := store.coins[walletKey{1, userID}]; coins != streakMilestones[0].Coins {
+		t.Errorf("coins = %d, want %d paid once", coins, streakMilestones[0].Coins)
+	}
+}
+
+func TestService_SpendCoins(t *testing.T) {
+	t.Parallel()
+
+	const userID = 42
+
+	tests := []struct {
+		name       string
+		buyer      entity.Actor
+		coins      map[int64]int
+		wantWallet int64
+		wantErr    error
+	}{
+		{
+			name:       "сначала тратится кошелёк чата",
+			buyer:      entity.Actor{ID: userID},
+			coins:      map[int64]int{chatStreakUser: 50, userID: 50},
+			wantWallet: chatStreakUser,
+		},
+		{
+			name:       "награды за личную серию можно потратить в магазине",
+			buyer:      entity.Actor{ID: userID},
+			coins:      map[int64]int{chatStreakUser: 10, userID: 30},
+			wantWallet: userID,
+		},
+		{
+			name:    "не хватает ни там, ни там",
+			buyer:   entity.Actor{ID: userID},
+			coins:   map[int64]int{chatStreakUser: 10, userID: 10},
+			wantErr: ErrNotEnoughCoins,
+		},
+		{
+			name:    "у анонима нет личного кошелька",
+			coins:   map[int64]int{chatStreakUser: 10},
+			wantErr: ErrNotEnoughCoins,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := zerolog.Nop()
+			store := newMemoryRepo()
+			for userID, coins := range tt.coins {
+				store.coins[walletKey{1, userID}] = coins
+			}
+
+			s := NewService(&config.Configuration{}, &logger, store, nil)
+
+			wallet, err := s.spendCoins(context.Background(), 1, tt.buyer, 30)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("spendCoins() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err == nil && wallet != tt.wantWallet {
+				t.Errorf("spendCoins() wallet = %d, want %d", wallet, tt.wantWallet)
+			}
+		})
+	}
+}
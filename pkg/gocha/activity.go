@@ -0,0 +1,254 @@
+package gocha
+
+import (
+	"fmt"
+	"time"
+)
+
+type ActivityKind string
+
+const (
+	ActivitySleep  ActivityKind = "sleep"
+	ActivityWalk   ActivityKind = "walk"
+	ActivityVet    ActivityKind = "vet"
+	ActivitySchool ActivityKind = "school"
+)
+
+const maxSleepDuration = 8 * time.Hour // Дольше питомец не спит, даже если не восстановился.
+
+// Effects Изменение статов питомца за одну минуту занятия.
+type Effects struct {
+	Health    int
+	Hunger    int
+	Happiness int
+	Energy    int
+	Hygiene   int
+}
+
+// ActivityDefinition Описание занятия с длительностью.
+type ActivityDefinition struct {
+	Kind          ActivityKind
+	Title         string
+	Duration      time.Duration // Плановая длительность.
+	MinEnergy     int           // Сколько энергии нужно, чтобы начать.
+	PerMinute     Effects       // Эффект каждой минуты занятия.
+	SuspendsDecay bool          // Обычная деградация статов на время занятия не действует.
+	EveryMinute   func(p *Pet, a *Activity)
+	IsDone        func(p *Pet) bool // Условие досрочного завершения.
+	OnComplete    func(p *Pet, a Activity) Result
+}
+
+// Activity Текущее занятие питомца.
+type Activity struct {
+	Kind      ActivityKind
+	StartedAt time.Time
+	Duration  time.Duration
+
+	Minutes      int // Сколько минут занятия уже прожито.
+	QualityTotal int // Сумма поминутного качества сна.
+	EnergyCarry  int // Накопленный дробный прирост энергии во сне, в сотых.
+}
+
+var activities = map[ActivityKind]ActivityDefinition{
+	ActivitySleep: {
+		Kind:          ActivitySleep,
+		Title:         "сон",
+		Duration:      maxSleepDuration,
+		SuspendsDecay: true,
+		EveryMinute:   sleepMinute,
+		IsDone:        isRested,
+		OnComplete:    wakeUp,
+	},
+	ActivityWalk: {
+		Kind:      ActivityWalk,
+		Title:     "прогулка",
+		Duration:  30 * time.Minute,
+		MinEnergy: 20,
+		PerMinute: Effects{Energy: -1, Hygiene: -1},
+		OnComplete: func(p *Pet, _ Activity) Result {
+			p.Happiness = clamp(p.Happiness+2*defaultCoefficient, MinStatValue, MaxStatValue)
+
+			return Result{Success: true, Message: fmt.Sprintf("Питомец вернулся с прогулки! Счастье: +%d", 2*defaultCoefficient)}
+		},
+	},
+	ActivityVet: {
+		Kind:      ActivityVet,
+		Title:     "визит к ветеринару",
+		Duration:  time.Hour,
+		PerMinute: Effects{Health: 1},
+		OnComplete: func(p *Pet, _ Activity) Result {
+			p.Happiness = clamp(p.Happiness-defaultCoefficient, MinStatValue, MaxStatValue)
+
+			return Result{Success: true, Message: fmt.Sprintf("Питомец вернулся от ветеринара. Счастье: -%d", defaultCoefficient)}
+		},
+	},
+	ActivitySchool: {
+		Kind:      ActivitySchool,
+		Title:     "школа",
+		Duration:  2 * time.Hour,
+		MinEnergy: 30,
+		OnComplete: func(p *Pet, _ Activity) Result {
+			for _, trick := range Tricks {
+				p.practice(trick, skillGainOnFailure)
+			}
+
+			return Result{Success: true, Message: fmt.Sprintf("Питомец вернулся из школы! Все трюки: +%d", skillGainOnFailure)}
+		},
+	},
+}
+
+func LookupActivity(kind ActivityKind) (ActivityDefinition, bool) {
+	def, ok := activities[kind]
+
+	return def, ok
+}
+
+func (a Activity) EndsAt() time.Time {
+	return a.StartedAt.Add(a.Duration)
+}
+
+func (a Activity) Remaining(now time.Time) time.Duration {
+	return max(a.EndsAt().Sub(now), 0)
+}
+
+func (p *Pet) StartActivity(kind ActivityKind) Result {
+	if p.IsDead() {
+		p.Kill()
+
+		return Result{Success: false, Message: PetIsDeadMessage}
+	}
+
+	def, ok := activities[kind]
+	if !ok {
+		return Result{Success: false, Message: "Неизвестное занятие."}
+	}
+
+	if p.IsSleeping() {
+		return Result{Success: false, Message: "Питомец спит."}
+	}
+
+	if p.IsBusy() {
+		return Result{Success: false, Message: "Питомец занят: " + activities[p.Activity.Kind].Title + "."}
+	}
+
+	if p.Energy < def.MinEnergy {
+		return Result{Success: false, Message: "Питомец слишком устал."}
+	}
+
+	now := time.Now()
+	p.Activity = &Activity{Kind: kind, StartedAt: now, Duration: def.Duration}
+
+	if kind == ActivitySleep {
+		p.State = Sleeping
+		p.SleepStartTime = now
+	}
+
+	return Result{
+		Success: true,
+		Message: fmt.Sprintf("Питомец занят: %s. Вернётся через %d мин.", def.Title, int(def.Duration.Minutes())),
+	}
+}
+
+// Advance Проживает время питомца поминутно с момента LastUpdated до to и возвращает
+// результаты занятий, завершившихся за этот период. LastUpdated сдвигается только на
+// целые прожитые минуты, так что итог не зависит от частоты вызовов.
+func (p *Pet) Advance(to time.Time) []Result {
+	if p.LastUpdated.IsZero() || p.IsDead() {
+		p.LastUpdated = to
+
+		return nil
+	}
+
+	p.restoreSleep()
+
+	var results []Result
+
+	for !p.IsDead() && !p.LastUpdated.Add(time.Minute).After(to) {
+		p.tick()
+		p.LastUpdated = p.LastUpdated.Add(time.Minute)
+
+		if p.Activity != nil && p.isActivityFinished() {
+			results = append(results, p.completeActivity())
+		}
+	}
+
+	if p.IsDead() {
+		p.LastUpdated = to
+	}
+
+	return results
+}
+
+// tick Одна минута жизни питомца.
+func (p *Pet) tick() {
+	def, busy := p.currentActivity()
+
+	if !busy || !def.SuspendsDecay {
+		p.updateAwakeState(1)
+	}
+
+	if busy {
+		p.Activity.Minutes++
+		p.applyEffects(def.PerMinute)
+
+		if def.EveryMinute != nil {
+			def.EveryMinute(p, p.Activity)
+		}
+	}
+
+	p.applyDamage(1)
+
+	if p.Health == MinStatValue {
+		p.Kill()
+	}
+}
+
+func (p *Pet) currentActivity() (ActivityDefinition, bool) {
+	if p.Activity == nil {
+		return ActivityDefinition{}, false
+	}
+
+	def, ok := activities[p.Activity.Kind]
+
+	return def, ok
+}
+
+// restoreSleep Питомец мог уснуть до появления занятий — восстанавливаем сон по времени засыпания.
+func (p *Pet) restoreSleep() {
+	if p.IsSleeping() && p.Activity == nil {
+		p.Activity = &Activity{Kind: ActivitySleep, StartedAt: p.SleepStartTime, Duration: maxSleepDuration}
+	}
+}
+
+func (p *Pet) isActivityFinished() bool {
+	if !p.LastUpdated.Before(p.Activity.EndsAt()) {
+		return true
+	}
+
+	def := activities[p.Activity.Kind]
+
+	return def.IsDone != nil && def.IsDone(p)
+}
+
+func (p *Pet) completeActivity() Result {
+	activity := *p.Activity
+	p.Activity = nil
+
+	def := activities[activity.Kind]
+	if def.OnComplete == nil {
+		return Result{Success: true, Message: "Занятие завершено: " + def.Title + ".", Activity: &activity}
+	}
+
+	result := def.OnComplete(p, activity)
+	result.Activity = &activity
+
+	return result
+}
+
+func (p *Pet) applyEffects(e Effects) {
+	p.Health = clamp(p.Health+e.Health, MinStatValue, MaxStatValue)
+	p.Hunger = clamp(p.Hunger+e.Hunger, MinStatValue, MaxStatValue)
+	p.Happiness = clamp(p.Happiness+e.Happiness, MinStatValue, MaxStatValue)
+	p.Energy = clamp(p.Energy+e.Energy, MinStatValue, MaxStatValue)
+	p.Hygiene = clamp(p.Hygiene+e.Hygiene, MinStatValue, MaxStatValue)
+}
@@ -0,0 +1,127 @@
+package gocha
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPet_StartActivity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("питомец уходит на прогулку", func(t *testing.T) {
+		p := NewPet("")
+		res := p.StartActivity(ActivityWalk)
+
+		if !res.Success || p.Activity == nil || p.Activity.Kind != ActivityWalk {
+			t.Errorf("StartActivity() should start a walk, got %q", res.Message)
+		}
+
+		if !p.IsBusy() {
+			t.Errorf("pet on a walk should be busy")
+		}
+	})
+
+	t.Run("занятый питомец не начинает второе занятие", func(t *testing.T) {
+		p := NewPet("")
+		p.StartActivity(ActivityWalk)
+		res := p.StartActivity(ActivitySchool)
+
+		if res.Success || p.Activity.Kind != ActivityWalk {
+			t.Errorf("busy pet should not start another activity")
+		}
+	})
+
+	t.Run("уставший питомец не идёт гулять", func(t *testing.T) {
+		p := NewPet("")
+		p.Energy = 10
+		res := p.StartActivity(ActivityWalk)
+
+		if res.Success || p.Activity != nil {
+			t.Errorf("tired pet should not go for a walk")
+		}
+	})
+
+	t.Run("сон — тоже занятие", func(t *testing.T) {
+		p := NewPet("")
+		p.Sleep()
+
+		if !p.IsSleeping() || p.Activity == nil || p.Activity.Kind != ActivitySleep {
+			t.Errorf("Sleep() should start the sleep activity")
+		}
+
+		if p.IsBusy() {
+			t.Errorf("sleeping pet should not be reported as busy")
+		}
+	})
+}
+
+func TestPet_Advance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("прогулка завершается автоматически", func(t *testing.T) {
+		p := NewPet("")
+		p.Happiness = 50
+		start := time.Now()
+		p.Activity = &Activity{Kind: ActivityWalk, StartedAt: start, Duration: 30 * time.Minute}
+
+		p.LastUpdated = start
+		results := p.Advance(start.Add(45 * time.Minute))
+
+		if p.Activity != nil {
+			t.Fatalf("walk should be completed")
+		}
+
+		if len(results) != 1 || !results[0].Success {
+			t.Errorf("Advance() should report the completed walk, got %v", results)
+		}
+	})
+
+	t.Run("прогулка ещё идёт", func(t *testing.T) {
+		p := NewPet("")
+		start := time.Now()
+		p.Activity = &Activity{Kind: ActivityWalk, StartedAt: start, Duration: 30 * time.Minute}
+
+		p.LastUpdated = start
+		results := p.Advance(start.Add(10 * time.Minute))
+
+		if p.Activity == nil || len(results) != 0 {
+			t.Errorf("walk should still be in progress")
+		}
+
+		if p.Activity.Remaining(start.Add(10*time.Minute)) != 20*time.Minute {
+			t.Errorf("Remaining() = %v, want 20m", p.Activity.Remaining(start.Add(10*time.Minute)))
+		}
+	})
+
+	t.Run("визит к ветеринару лечит поминутно", func(t *testing.T) {
+		p := NewPet("")
+		p.Health = 50
+		start := time.Now()
+		p.Activity = &Activity{Kind: ActivityVet, StartedAt: start, Duration: time.Hour}
+
+		p.LastUpdated = start
+		p.Advance(start.Add(10 * time.Minute))
+
+		if p.Health != 60 {
+			t.Errorf("Health = %v, want 60", p.Health)
+		}
+	})
+
+	t.Run("сон не длится дольше максимума", func(t *testing.T) {
+		p := NewPet("")
+		start := time.Now().Add(-10 * time.Hour)
+		p.Energy = 10
+		p.Hunger = 0
+		p.Hygiene = 0
+		p.Sleep()
+		p.SleepStartTime = start
+		p.Activity.StartedAt = start
+
+		p.LastUpdated = start
+		p.Advance(start.Add(10 * time.Hour))
+
+		if p.IsSleeping() || p.Activity != nil {
+			t.Errorf("pet should wake up after %v", maxSleepDuration)
+		}
+	})
+}
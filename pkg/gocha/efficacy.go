@@ -0,0 +1,113 @@
+package gocha
+
+import (
+	"time"
+)
+
+// CareAction Действие ухода, эффект которого зависит от состояния питомца и частоты повторов.
+type CareAction string
+
+const (
+	CareFeed  CareAction = "feed"
+	CareClean CareAction = "clean"
+	CareHeal  CareAction = "heal"
+	CarePlay  CareAction = "play"
+)
+
+const (
+	repeatWindow     = 30 * time.Minute // Повторы внутри окна дают всё меньший эффект.
+	repeatFalloff    = 50               // Каждый повтор в окне оставляет столько процентов от предыдущего эффекта.
+	fullEfficacy     = 100
+	boostedEfficacy  = 150
+	weakenedEfficacy = 50
+)
+
+// Efficacy Эффективность действия в процентах: состояние питомца с учётом недавних повторов.
+func (p *Pet) Efficacy(action CareAction) int {
+	return p.efficacyAt(action, time.Now())
+}
+
+func (p *Pet) efficacyAt(action CareAction, now time.Time) int {
+	return p.stateEfficacy(action) * p.repeatEfficacy(action, now) / fullEfficacy
+}
+
+// stateEfficacy Довольный питомец лечится быстрее, уставший меньше радуется игре,
+// несчастный плохо ест и сопротивляется купанию.
+func (p *Pet) stateEfficacy(action CareAction) int {
+	efficacy := fullEfficacy
+
+	switch action {
+	case CareFeed:
+		if p.IsUnhappy() || p.Health <= 20 {
+			efficacy = efficacy * 70 / 100
+		}
+	case CareHeal:
+		if p.Happiness >= 70 {
+			efficacy = efficacy * boostedEfficacy / 100
+		} else if p.IsUnhappy() {
+			efficacy = efficacy * weakenedEfficacy / 100
+		}
+	case CarePlay:
+		if p.Energy <= 30 {
+			efficacy = efficacy * weakenedEfficacy / 100
+		}
+
+		if p.Hunger <= 20 {
+			efficacy = efficacy * weakenedEfficacy / 100
+		}
+	case CareClean:
+		if p.IsUnhappy() {
+			efficacy = efficacy * 70 / 100
+		}
+	}
+
+	return efficacy
+}
+
+// repeatEfficacy Убывающая отдача: каждый повтор действия в окне ослабляет его вдвое.
+func (p *Pet) repeatEfficacy(action CareAction, now time.Time) int {
+	efficacy := fullEfficacy
+
+	for _, at := range p.RecentCare[action] {
+		if now.Sub(at) < repeatWindow {
+			efficacy = efficacy * repeatFalloff / 100
+		}
+	}
+
+	return efficacy
+}
+
+// careAmount Фиксирует действие и возвращает, на сколько оно меняет стат, и была ли отдача снижена повтором.
+func (p *Pet) careAmount(action CareAction) (int, bool) {
+	now := time.Now()
+	repeated := p.repeatEfficacy(action, now) < fullEfficacy
+	amount := defaultCoefficient * p.efficacyAt(action, now) * (fullEfficacy + p.CareBoost) / (fullEfficacy * fullEfficacy)
+
+	p.rememberCare(action, now)
+
+	return amount, repeated
+}
+
+// rememberCare Запоминает время действия и забывает те, что вышли за окно.
+func (p *Pet) rememberCare(action CareAction, now time.Time) {
+	if p.RecentCare == nil {
+		p.RecentCare = make(map[CareAction][]time.Time)
+	}
+
+	recent := make([]time.Time, 0, len(p.RecentCare[action])+1)
+	for _, at := range p.RecentCare[action] {
+		if now.Sub(at) < repeatWindow {
+			recent = append(recent, at)
+		}
+	}
+
+	p.RecentCare[action] = append(recent, now)
+}
+
+func repeatNote(repeated bool) string {
+	if !repeated {
+		return ""
+	}
+
+	return ". Питомцу надоело одно и то же"
+}
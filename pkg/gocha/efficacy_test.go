@@ -0,0 +1,89 @@
+package gocha
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPet_Efficacy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("довольный питомец лечится быстрее", func(t *testing.T) {
+		p := NewPet("")
+		p.Health = 50
+		p.Heal()
+
+		if p.Health != 50+defaultCoefficient*boostedEfficacy/100 {
+			t.Errorf("Heal() Health = %v, want boosted gain", p.Health)
+		}
+	})
+
+	t.Run("несчастный питомец лечится медленнее", func(t *testing.T) {
+		p := NewPet("")
+		p.Health = 50
+		p.Happiness = 10
+		p.Heal()
+
+		if p.Health != 50+defaultCoefficient*weakenedEfficacy/100 {
+			t.Errorf("Heal() Health = %v, want weakened gain", p.Health)
+		}
+	})
+
+	t.Run("уставшему питомцу игра приносит меньше радости", func(t *testing.T) {
+		p := NewPet("")
+		p.Happiness = 50
+		p.Energy = 30
+		p.Play()
+
+		if p.Happiness != 50+defaultCoefficient*weakenedEfficacy/100 {
+			t.Errorf("Play() Happiness = %v, want weakened gain", p.Happiness)
+		}
+	})
+
+	t.Run("повторы в окне дают убывающую отдачу", func(t *testing.T) {
+		p := NewPet("")
+		p.Hygiene = 50
+
+		var gains []int
+		for range 3 {
+			before := p.Hygiene
+			p.Clean()
+			gains = append(gains, p.Hygiene-before)
+		}
+
+		if gains[0] <= gains[1] || gains[1] <= gains[2] {
+			t.Errorf("Clean() gains = %v, want strictly decreasing", gains)
+		}
+	})
+
+	t.Run("прибавка роли усиливает уход", func(t *testing.T) {
+		p := NewPet("")
+		p.Hygiene = 50
+		p.CareBoost = 100
+		p.Clean()
+
+		if p.Hygiene != 50+2*defaultCoefficient {
+			t.Errorf("Clean() Hygiene = %v, want doubled gain", p.Hygiene)
+		}
+	})
+
+	t.Run("после окна эффективность восстанавливается", func(t *testing.T) {
+		p := NewPet("")
+		p.RecentCare[CareClean] = []time.Time{time.Now().Add(-repeatWindow - time.Minute)}
+
+		if got := p.Efficacy(CareClean); got != fullEfficacy {
+			t.Errorf("Efficacy() = %v, want %v", got, fullEfficacy)
+		}
+	})
+
+	t.Run("старые повторы забываются", func(t *testing.T) {
+		p := NewPet("")
+		p.Hygiene = 50
+		p.RecentCare[CareClean] = []time.Time{time.Now().Add(-2 * repeatWindow)}
+		p.Clean()
+
+		if len(p.RecentCare[CareClean]) != 1 {
+			t.Errorf("RecentCare = %v, want only the last clean", p.RecentCare[CareClean])
+		}
+	})
+}
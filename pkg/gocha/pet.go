@@ -28,8 +28,9 @@ const (
 )
 
 type Result struct {
-	Success bool
-	Message string
+	Success  bool
+	Message  string
+	Activity *Activity // Завершившееся занятие, если это его итог; у действий nil.
 }
 
 type Pet struct {
@@ -41,7 +42,14 @@ type Pet struct {
 	Hygiene        int // Гигиена питомца в процентах.
 	State          State
 	SleepStartTime time.Time
+	Skills         map[Trick]Skill            // Выученные трюки.
+	Activity       *Activity                  // Текущее занятие, nil если питомец свободен.
+	LastUpdated    time.Time                  // До какого момента прожита жизнь питомца.
+	RecentCare     map[CareAction][]time.Time // Недавние действия ухода для убывающей отдачи.
+	DecayPercent   *DecayPercent              // Временные множители убывания статов (праздники), nil — обычная скорость.
+	CareBoost      int                        // Прибавка к эффекту ухода в процентах (роль того, кто ухаживает), 0 — без прибавки.
 	config         Config
+	random         func() float64
 }
 
 type Config struct {
@@ -51,6 +59,14 @@ type Config struct {
 	HappinessDecayRate int
 }
 
+// DecayPercent Скорость убывания статов в процентах от скорости из Config.
+type DecayPercent struct {
+	Hunger    int
+	Energy    int
+	Hygiene   int
+	Happiness int
+}
+
 func NewPet(name string) *Pet {
 	config := Config{
 		HungerDecayRate:    defaultHungerDecayRate,
@@ -67,7 +83,12 @@ func NewPet(name string) *Pet {
 		Energy:    MaxStatValue,
 		Hygiene:   MaxStatValue,
 		State:     Alive,
+		Skills:    make(map[Trick]Skill),
 		config:    config,
+
+		RecentCare: make(map[CareAction][]time.Time),
+
+		LastUpdated: time.Now(),
 	}
 }
 
@@ -86,7 +107,9 @@ func (p *Pet) Feed() Result {
 		return Result{Success: false, Message: PetIsDeadMessage}
 	}
 
-	p.Hunger += defaultCoefficient // Уменьшаем голод
+	amount, repeated := p.careAmount(CareFeed)
+
+	p.Hunger += amount // Уменьшаем голод
 
 	if p.IsOverfed() {
 		p.Health = clamp(p.Health-defaultCoefficient, MinStatValue, MaxStatValue)
@@ -103,7 +126,7 @@ func (p *Pet) Feed() Result {
 
 	p.Hunger = clamp(p.Hunger, MinStatValue, MaxStatValue)
 
-	return Result{Success: true, Message: "Питомец покормлен!"}
+	return Result{Success: true, Message: fmt.Sprintf("Питомец покормлен! Сытость: +%d%s", amount, repeatNote(repeated))}
 }
 
 func (p *Pet) Heal() Result {
@@ -124,14 +147,16 @@ func (p *Pet) Heal() Result {
 		return Result{Success: true, Message: fmt.Sprintf("Питомец перелечен! Энергия: -%d", defaultCoefficient)}
 	}
 
-	p.Health += defaultCoefficient
+	amount, repeated := p.careAmount(CareHeal)
+
+	p.Health += amount
 	p.Health = clamp(p.Health, MinStatValue, MaxStatValue)
 
 	if p.Health == MaxStatValue {
 		return Result{Success: true, Message: "Питомец полностью здоров!"}
 	}
 
-	return Result{Success: true, Message: fmt.Sprintf("Питомца полечили. Здоровье: +%d", defaultCoefficient)}
+	return Result{Success: true, Message: fmt.Sprintf("Питомца полечили. Здоровье: +%d%s", amount, repeatNote(repeated))}
 }
 
 func (p *Pet) Play() Result {
@@ -150,14 +175,21 @@ func (p *Pet) Play() Result {
 
 			return Result{Success: false, Message: "Питомец умер."}
 		}
-	} else {
-		p.Happiness = clamp(p.Happiness+defaultCoefficient, MinStatValue, MaxStatValue)
-		p.Energy = clamp(p.Energy-defaultCoefficient/2, MinStatValue, MaxStatValue)
+
+		return Result{
+			Success: true,
+			Message: fmt.Sprintf("Питомец играл через силу. Счастье: -%d", defaultCoefficient),
+		}
 	}
 
+	amount, repeated := p.careAmount(CarePlay)
+
+	p.Happiness = clamp(p.Happiness+amount, MinStatValue, MaxStatValue)
+	p.Energy = clamp(p.Energy-defaultCoefficient/2, MinStatValue, MaxStatValue)
+
 	return Result{
 		Success: true,
-		Message: fmt.Sprintf("Питомец играл. Счастье: +%d, Энергия: -%d", defaultCoefficient, defaultCoefficient/2),
+		Message: fmt.Sprintf("Питомец играл. Счастье: +%d, Энергия: -%d%s", amount, defaultCoefficient/2, repeatNote(repeated)),
 	}
 }
 
@@ -168,7 +200,9 @@ func (p *Pet) Clean() Result {
 		return Result{Success: false, Message: PetIsDeadMessage}
 	}
 
-	p.Hygiene = clamp(p.Hygiene+defaultCoefficient, MinStatValue, MaxStatValue)
+	amount, repeated := p.careAmount(CareClean)
+
+	p.Hygiene = clamp(p.Hygiene+amount, MinStatValue, MaxStatValue)
 
 	if p.Hygiene == MaxStatValue {
 		return Result{Success: true, Message: "Питомец полностью чист!"}
@@ -176,7 +210,7 @@ func (p *Pet) Clean() Result {
 
 	return Result{
 		Success: true,
-		Message: fmt.Sprintf("Питомца помыли. Гигиена: +%d", defaultCoefficient),
+		Message: fmt.Sprintf("Питомца помыли. Гигиена: +%d%s", amount, repeatNote(repeated)),
 	}
 }
 
@@ -194,8 +228,10 @@ func (p *Pet) Sleep() Result {
 		}
 	}
 
-	p.State = Sleeping
-	p.SleepStartTime = time.Now()
+	result := p.StartActivity(ActivitySleep)
+	if !result.Success {
+		return result
+	}
 
 	return Result{
 		Success: true,
@@ -214,69 +250,19 @@ func (p *Pet) WakeUp() Result {
 		return Result{Success: false, Message: "Питомец не спит."}
 	}
 
-	// Вычисляем продолжительность сна
-	sleepDuration := time.Since(p.SleepStartTime)
-	minutesSlept := int(sleepDuration.Minutes())
-
 	// Минимальное время сна
-	if minutesSlept < 1 {
+	if time.Since(p.SleepStartTime) < time.Minute {
 		return Result{Success: false, Message: "Питомец не выспался."}
 	}
 
-	// Максимальное время сна
-	if minutesSlept > 480 { // 8 часов
-		minutesSlept = 480
-	}
-
-	// Вычисляем изменения
-	energyGained := minutesSlept * 2 // 2 единицы энергии за каждую минуту сна
-	hungerGained := minutesSlept * 1 // 1 единица голода за каждую минуту сна
-
-	// Применяем изменения, но проверяем их границы
-	newEnergy := clamp(p.Energy+energyGained, MinStatValue, MaxStatValue)
-	newHunger := clamp(p.Hunger+hungerGained, MinStatValue, MaxStatValue)
-
-	// Если ничего не изменилось, результат бессмысленный
-	if newEnergy == p.Energy && newHunger == p.Hunger {
-		p.State = Alive
-
-		return Result{Success: false, Message: "Питомец не получил пользы от сна."}
-	}
-
-	// Обновляем состояние питомца
-	p.Energy = newEnergy
-	p.Hunger = newHunger
-
-	// Меняем состояние на "бодрствует"
-	p.State = Alive
+	p.restoreSleep()
 
-	// Формируем сообщение с результатами
-	message := fmt.Sprintf(
-		"Питомец проснулся! Спал %d минут. Энергия +%d, голод +%d.",
-		minutesSlept, energyGained, hungerGained,
-	)
-
-	return Result{Success: true, Message: message}
+	return p.completeActivity()
 }
 
 func (p *Pet) DegradeOverTime(lastUpdated time.Time) {
-	minutes := int(time.Since(lastUpdated).Minutes())
-
-	if minutes <= 0 {
-		return
-	}
-
-	if p.State == Sleeping {
-		p.updateSleepingState(minutes)
-	} else {
-		p.updateAwakeState(minutes)
-	}
-
-	p.applyDamage(minutes)
-
-	if p.Health == MinStatValue {
-		p.Kill()
-	}
+	p.LastUpdated = lastUpdated
+	p.Advance(time.Now())
 }
 
 func (p *Pet) Kill() {
@@ -286,18 +272,33 @@ func (p *Pet) Kill() {
 	p.Happiness = MinStatValue
 	p.Energy = MinStatValue
 	p.Hygiene = MinStatValue
+	p.Activity = nil
 }
 
-func (p *Pet) updateSleepingState(minutes int) {
-	p.Energy = clamp(p.Energy+minutes*defaultCoefficient, MinStatValue, MaxStatValue)
-	p.Hunger = clamp(p.Hunger-minutes*2, MinStatValue, MaxStatValue)
+func (p *Pet) updateAwakeState(minutes int) {
+	percent := DecayPercent{Hunger: 100, Energy: 100, Hygiene: 100, Happiness: 100}
+	if p.DecayPercent != nil {
+		percent = *p.DecayPercent
+	}
+
+	p.Hunger = clamp(p.Hunger-p.decay(minutes, p.config.HungerDecayRate, percent.Hunger), MinStatValue, MaxStatValue)
+	p.Energy = clamp(p.Energy-p.decay(minutes, p.config.EnergyDecayRate, percent.Energy), MinStatValue, MaxStatValue)
+	p.Hygiene = clamp(p.Hygiene-p.decay(minutes, p.config.HygieneDecayRate, percent.Hygiene), MinStatValue, MaxStatValue)
+	p.Happiness = clamp(p.Happiness-p.decay(minutes, p.config.HappinessDecayRate, percent.Happiness), MinStatValue, MaxStatValue)
 }
 
-func (p *Pet) updateAwakeState(minutes int) {
-	p.Hunger = clamp(p.Hunger-minutes*p.config.HungerDecayRate, MinStatValue, MaxStatValue)
-	p.Energy = clamp(p.Energy-minutes*p.config.EnergyDecayRate, MinStatValue, MaxStatValue)
-	p.Hygiene = clamp(p.Hygiene-minutes*p.config.HygieneDecayRate, MinStatValue, MaxStatValue)
-	p.Happiness = clamp(p.Happiness-minutes*p.config.HappinessDecayRate, MinStatValue, MaxStatValue)
+// decay Убыль стата за minutes минут, начиная с LastUpdated. Дробная скорость раскладывается по минутам
+// абсолютного времени, так что итог не зависит от частоты вызовов: 50% от единицы — единица через минуту.
+func (p *Pet) decay(minutes, rate, percent int) int {
+	if percent == 100 {
+		return minutes * rate
+	}
+
+	from := p.LastUpdated.Unix() / 60
+	to := from + int64(minutes)
+	perMinute := int64(rate * max(percent, 0))
+
+	return int(to*perMinute/100 - from*perMinute/100)
 }
 
 func (p *Pet) applyDamage(minutes int) {
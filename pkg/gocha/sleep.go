@@ -0,0 +1,84 @@
+package gocha
+
+import (
+	"fmt"
+	"time"
+)
+
+// Модель сна. Энергия восстанавливается только поминутно в Advance, пробуждение
+// лишь подводит итог, поэтому результат не зависит от того, как часто идут тики.
+const (
+	sleepEnergyPerMinute = 2 // Прирост энергии за минуту при идеальном качестве сна.
+	sleepHungerPerMinute = 1 // Во сне питомец голодает медленнее, чем бодрствуя.
+	minSleepQuality      = 10
+	oversleepAfter       = time.Hour // После этого каждая минута сна портит настроение.
+	oversleepPenalty     = 1         // Потеря счастья за минуту пересыпа.
+)
+
+// SleepQuality Качество сна в процентах: голодный, грязный, несчастный или больной питомец спит хуже.
+func (p *Pet) SleepQuality() int {
+	quality := 100
+
+	if p.Hunger <= 20 {
+		quality -= 40
+	}
+
+	if p.IsDirty() {
+		quality -= 20
+	}
+
+	if p.IsUnhappy() {
+		quality -= 20
+	}
+
+	if p.Health <= 20 {
+		quality -= 20
+	}
+
+	return max(quality, minSleepQuality)
+}
+
+// Quality Среднее качество сна за всё время занятия.
+func (a Activity) Quality() int {
+	if a.Minutes == 0 {
+		return 0
+	}
+
+	return a.QualityTotal / a.Minutes
+}
+
+// sleepMinute Одна минута сна. Дробный прирост энергии копится в EnergyCarry,
+// чтобы плохой сон восстанавливал меньше, а не округлялся до нуля.
+func sleepMinute(p *Pet, a *Activity) {
+	quality := p.SleepQuality()
+
+	a.QualityTotal += quality
+	a.EnergyCarry += sleepEnergyPerMinute * quality
+
+	p.Energy = clamp(p.Energy+a.EnergyCarry/100, MinStatValue, MaxStatValue)
+	a.EnergyCarry %= 100
+
+	p.Hunger = clamp(p.Hunger-sleepHungerPerMinute, MinStatValue, MaxStatValue)
+
+	if time.Duration(a.Minutes)*time.Minute > oversleepAfter {
+		p.Happiness = clamp(p.Happiness-oversleepPenalty, MinStatValue, MaxStatValue)
+	}
+}
+
+// isRested Питомец просыпается сам, когда полностью восстановил энергию.
+func isRested(p *Pet) bool {
+	return p.Energy == MaxStatValue
+}
+
+// wakeUp Итог сна: статы уже изменены поминутно, здесь только смена состояния и отчёт.
+func wakeUp(p *Pet, a Activity) Result {
+	p.State = Alive
+
+	message := fmt.Sprintf("Питомец проснулся! Спал %d мин., качество сна %d%%.", a.Minutes, a.Quality())
+
+	if overslept := time.Duration(a.Minutes)*time.Minute - oversleepAfter; overslept > 0 {
+		message += fmt.Sprintf(" Питомец проспал, счастье: -%d.", int(overslept.Minutes())*oversleepPenalty)
+	}
+
+	return Result{Success: true, Message: message}
+}
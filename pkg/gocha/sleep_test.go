@@ -0,0 +1,130 @@
+package gocha
+
+import (
+	"testing"
+	"time"
+)
+
+// sleepyPet Питомец, которого уложили спать в момент start.
+func sleepyPet(start time.Time) *Pet {
+	p := NewPet("")
+	p.Energy = 10
+	p.Hunger = 60
+	p.Hygiene = 50
+	p.Sleep()
+	p.SleepStartTime = start
+	p.Activity.StartedAt = start
+	p.LastUpdated = start
+
+	return p
+}
+
+func TestPet_SleepTickInvariance(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 22, 0, 17, 0, time.UTC)
+	end := start.Add(2*time.Hour + 42*time.Second)
+
+	once := sleepyPet(start)
+	once.Advance(end)
+
+	steps := map[string]time.Duration{
+		"каждую минуту":    time.Minute,
+		"каждые 90 секунд": 90 * time.Second,
+		"каждые 7 минут":   7*time.Minute + 13*time.Second,
+		"каждый час":       time.Hour,
+	}
+
+	for name, step := range steps {
+		t.Run(name, func(t *testing.T) {
+			ticked := sleepyPet(start)
+			for at := start.Add(step); at.Before(end); at = at.Add(step) {
+				ticked.Advance(at)
+			}
+			ticked.Advance(end)
+
+			if ticked.Health != once.Health || ticked.Hunger != once.Hunger || ticked.Happiness != once.Happiness ||
+				ticked.Energy != once.Energy || ticked.Hygiene != once.Hygiene || ticked.State != once.State {
+				t.Errorf("stats differ: ticked %+v, once %+v", *ticked, *once)
+			}
+
+			if !ticked.LastUpdated.Equal(once.LastUpdated) {
+				t.Errorf("LastUpdated = %v, want %v", ticked.LastUpdated, once.LastUpdated)
+			}
+		})
+	}
+}
+
+func TestPet_SleepModel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("пробуждение не добавляет энергию повторно", func(t *testing.T) {
+		start := time.Now().Add(-10 * time.Minute)
+		p := sleepyPet(start)
+		p.Advance(start.Add(10 * time.Minute))
+
+		energy, hunger := p.Energy, p.Hunger
+		res := p.WakeUp()
+
+		if !res.Success || p.IsSleeping() {
+			t.Fatalf("WakeUp() should wake the pet, got %q", res.Message)
+		}
+
+		if p.Energy != energy || p.Hunger != hunger {
+			t.Errorf("WakeUp() changed stats: energy %v -> %v, hunger %v -> %v", energy, p.Energy, hunger, p.Hunger)
+		}
+	})
+
+	t.Run("во сне сытость убывает медленно, на sleepHungerPerMinute в минуту", func(t *testing.T) {
+		start := time.Now()
+		p := sleepyPet(start)
+		p.Advance(start.Add(10 * time.Minute))
+
+		if p.Hunger != 60-10*sleepHungerPerMinute {
+			t.Errorf("Hunger = %v, want %v", p.Hunger, 60-10*sleepHungerPerMinute)
+		}
+	})
+
+	t.Run("питомец просыпается сам, когда выспался", func(t *testing.T) {
+		start := time.Now()
+		p := sleepyPet(start)
+		p.Energy = 90
+		results := p.Advance(start.Add(10 * time.Minute))
+
+		if p.IsSleeping() || len(results) != 1 {
+			t.Errorf("pet with full energy should wake up, results: %v", results)
+		}
+	})
+
+	t.Run("плохой сон восстанавливает меньше", func(t *testing.T) {
+		start := time.Now()
+		good := sleepyPet(start)
+		bad := sleepyPet(start)
+		bad.Hygiene = 10
+		bad.Hunger = 100
+
+		good.Advance(start.Add(10 * time.Minute))
+		bad.Advance(start.Add(10 * time.Minute))
+
+		if bad.Energy >= good.Energy {
+			t.Errorf("dirty pet should restore less energy: %v >= %v", bad.Energy, good.Energy)
+		}
+	})
+
+	t.Run("пересып портит настроение", func(t *testing.T) {
+		start := time.Now()
+		p := sleepyPet(start)
+		p.Energy = 0
+		p.Hunger = 100
+		p.Hygiene = 10
+		results := p.Advance(start.Add(70 * time.Minute))
+
+		if p.IsSleeping() || p.IsDead() || len(results) != 1 {
+			t.Fatalf("pet should wake up alive, results: %v", results)
+		}
+
+		if p.Happiness >= MaxStatValue {
+			t.Errorf("oversleeping should cost happiness, got %v", p.Happiness)
+		}
+	})
+}
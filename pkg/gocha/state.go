@@ -27,3 +27,8 @@ func (p *Pet) IsAlive() bool {
 func (p *Pet) IsSleeping() bool {
 	return p.State == Sleeping
 }
+
+// IsBusy Питомец занят чем-то кроме сна.
+func (p *Pet) IsBusy() bool {
+	return p.Activity != nil && p.Activity.Kind != ActivitySleep
+}
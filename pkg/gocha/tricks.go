@@ -0,0 +1,177 @@
+package gocha
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+type Trick string
+
+const (
+	TrickSit   Trick = "sit"
+	TrickDance Trick = "dance"
+	TrickFetch Trick = "fetch"
+)
+
+const (
+	TrainEnergyCost      = 10 // Сколько энергии тратит тренировка; с меньшим запасом питомец не учится.
+	trickEnergyCost      = 5
+	skillGainOnSuccess   = 10
+	skillGainOnFailure   = 2
+	skillGainOnPerform   = 1
+	MinTrickLevel        = 20             // Уровень, начиная с которого питомец может показать трюк.
+	skillDecayGracePause = 24 * time.Hour // Сколько навык хранится без тренировок.
+	skillDecayInterval   = 6 * time.Hour  // Навык теряет 1 уровень за каждый такой интервал после паузы.
+)
+
+// Tricks Все трюки, которым можно научить питомца.
+var Tricks = []Trick{TrickSit, TrickDance, TrickFetch}
+
+var trickNames = map[Trick]string{
+	TrickSit:   "сидеть",
+	TrickDance: "танцевать",
+	TrickFetch: "апорт",
+}
+
+// Skill Уровень владения трюком. Level фиксируется на момент последней практики,
+// текущий уровень с учётом забывания считает Effective.
+type Skill struct {
+	Level         int
+	LastPracticed time.Time
+}
+
+func ParseTrick(name string) (Trick, bool) {
+	trick := Trick(name)
+	if _, ok := trickNames[trick]; !ok {
+		return "", false
+	}
+
+	return trick, true
+}
+
+func (t Trick) Title() string {
+	return trickNames[t]
+}
+
+// Effective Текущий уровень навыка с учётом забывания без практики.
+func (s Skill) Effective(now time.Time) int {
+	idle := now.Sub(s.LastPracticed) - skillDecayGracePause
+	if s.LastPracticed.IsZero() || idle <= 0 {
+		return s.Level
+	}
+
+	return clamp(s.Level-int(idle/skillDecayInterval), MinStatValue, MaxStatValue)
+}
+
+// SkillLevel Текущий уровень навыка питомца.
+func (p *Pet) SkillLevel(trick Trick) int {
+	return p.Skills[trick].Effective(time.Now())
+}
+
+// CanPerformTrick Знает ли питомец трюк достаточно, чтобы его показать.
+func (p *Pet) CanPerformTrick(trick Trick) bool {
+	return p.SkillLevel(trick) >= MinTrickLevel
+}
+
+// TrainingChance Вероятность успешной тренировки: бодрый и довольный питомец учится лучше.
+func (p *Pet) TrainingChance() float64 {
+	chance := float64(p.Energy+p.Happiness) / float64(2*MaxStatValue)
+
+	return min(max(chance, 0.1), 0.95)
+}
+
+func (p *Pet) Train(trick Trick) Result {
+	if p.IsDead() {
+		p.Kill()
+
+		return Result{Success: false, Message: PetIsDeadMessage}
+	}
+
+	if p.IsSleeping() {
+		return Result{Success: false, Message: "Питомец спит."}
+	}
+
+	if p.Energy < TrainEnergyCost {
+		return Result{Success: false, Message: "Питомец слишком устал, чтобы учиться!"}
+	}
+
+	p.Energy = clamp(p.Energy-TrainEnergyCost, MinStatValue, MaxStatValue)
+
+	if p.roll() >= p.TrainingChance() {
+		level := p.practice(trick, skillGainOnFailure)
+
+		return Result{
+			Success: false,
+			Message: fmt.Sprintf("Питомец не понял команду «%s». Навык: %d", trick.Title(), level),
+		}
+	}
+
+	level := p.practice(trick, skillGainOnSuccess)
+
+	return Result{
+		Success: true,
+		Message: fmt.Sprintf("Питомец разучил команду «%s»! Навык: %d", trick.Title(), level),
+	}
+}
+
+func (p *Pet) PerformTrick(trick Trick) Result {
+	if p.IsDead() {
+		p.Kill()
+
+		return Result{Success: false, Message: PetIsDeadMessage}
+	}
+
+	if p.IsSleeping() {
+		return Result{Success: false, Message: "Питомец спит."}
+	}
+
+	if !p.CanPerformTrick(trick) {
+		return Result{Success: false, Message: fmt.Sprintf("Питомец ещё не знает команду «%s».", trick.Title())}
+	}
+
+	if p.Energy < trickEnergyCost {
+		return Result{Success: false, Message: "Питомец слишком устал для трюков!"}
+	}
+
+	level := p.SkillLevel(trick)
+	p.Energy = clamp(p.Energy-trickEnergyCost, MinStatValue, MaxStatValue)
+
+	// Чем лучше выучен трюк, тем реже питомец ошибается.
+	if p.roll() >= float64(level)/float64(MaxStatValue) {
+		p.practice(trick, skillGainOnPerform)
+
+		return Result{Success: false, Message: fmt.Sprintf("Питомец запутался в команде «%s».", trick.Title())}
+	}
+
+	bonus := defaultCoefficient + level/10
+	p.Happiness = clamp(p.Happiness+bonus, MinStatValue, MaxStatValue)
+	p.practice(trick, skillGainOnPerform)
+
+	return Result{
+		Success: true,
+		Message: fmt.Sprintf("Питомец выполнил «%s»! Счастье: +%d", trick.Title(), bonus),
+	}
+}
+
+// practice Закрепляет навык и возвращает новый уровень.
+func (p *Pet) practice(trick Trick, gain int) int {
+	now := time.Now()
+
+	if p.Skills == nil {
+		p.Skills = make(map[Trick]Skill)
+	}
+
+	level := clamp(p.Skills[trick].Effective(now)+gain, MinStatValue, MaxStatValue)
+	p.Skills[trick] = Skill{Level: level, LastPracticed: now}
+
+	return level
+}
+
+func (p *Pet) roll() float64 {
+	if p.random != nil {
+		return p.random()
+	}
+
+	return rand.Float64()
+}
@@ -0,0 +1,122 @@
+package gocha
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPet_Train(t *testing.T) {
+	t.Parallel()
+
+	t.Run("успешная тренировка повышает навык", func(t *testing.T) {
+		p := NewPet("")
+		p.random = func() float64 { return 0 }
+		res := p.Train(TrickSit)
+
+		if !res.Success {
+			t.Errorf("Train() should succeed, got %q", res.Message)
+		}
+
+		if p.SkillLevel(TrickSit) != skillGainOnSuccess {
+			t.Errorf("SkillLevel() = %v, want %v", p.SkillLevel(TrickSit), skillGainOnSuccess)
+		}
+
+		if p.Energy != MaxStatValue-TrainEnergyCost {
+			t.Errorf("Energy = %v, want %v", p.Energy, MaxStatValue-TrainEnergyCost)
+		}
+	})
+
+	t.Run("неудачная тренировка всё равно немного учит", func(t *testing.T) {
+		p := NewPet("")
+		p.random = func() float64 { return 1 }
+		res := p.Train(TrickDance)
+
+		if res.Success {
+			t.Errorf("Train() should fail")
+		}
+
+		if p.SkillLevel(TrickDance) != skillGainOnFailure {
+			t.Errorf("SkillLevel() = %v, want %v", p.SkillLevel(TrickDance), skillGainOnFailure)
+		}
+	})
+
+	t.Run("уставший питомец не тренируется", func(t *testing.T) {
+		p := NewPet("")
+		p.Energy = TrainEnergyCost - 1
+		p.Train(TrickFetch)
+
+		if p.SkillLevel(TrickFetch) != 0 {
+			t.Errorf("tired pet should not learn, got %v", p.SkillLevel(TrickFetch))
+		}
+	})
+
+	t.Run("шанс зависит от энергии и счастья", func(t *testing.T) {
+		p := NewPet("")
+		high := p.TrainingChance()
+		p.Energy, p.Happiness = 30, 30
+
+		if p.TrainingChance() >= high {
+			t.Errorf("TrainingChance() should drop for a tired and sad pet")
+		}
+	})
+}
+
+func TestPet_PerformTrick(t *testing.T) {
+	t.Parallel()
+
+	t.Run("невыученный трюк", func(t *testing.T) {
+		p := NewPet("")
+		p.Happiness = 50
+		res := p.PerformTrick(TrickSit)
+
+		if res.Success || p.Happiness != 50 {
+			t.Errorf("unknown trick should not be performed")
+		}
+	})
+
+	t.Run("выученный трюк радует питомца", func(t *testing.T) {
+		p := NewPet("")
+		p.random = func() float64 { return 0 }
+		p.Happiness = 50
+		p.Skills[TrickDance] = Skill{Level: 50, LastPracticed: time.Now()}
+		res := p.PerformTrick(TrickDance)
+
+		if !res.Success {
+			t.Errorf("PerformTrick() should succeed, got %q", res.Message)
+		}
+
+		if p.Happiness != 50+defaultCoefficient+5 {
+			t.Errorf("Happiness = %v, want %v", p.Happiness, 50+defaultCoefficient+5)
+		}
+	})
+}
+
+func TestSkill_Effective(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	t.Run("навык не забывается в течение паузы", func(t *testing.T) {
+		s := Skill{Level: 40, LastPracticed: now.Add(-skillDecayGracePause)}
+
+		if s.Effective(now) != 40 {
+			t.Errorf("Effective() = %v, want 40", s.Effective(now))
+		}
+	})
+
+	t.Run("навык забывается без практики", func(t *testing.T) {
+		s := Skill{Level: 40, LastPracticed: now.Add(-skillDecayGracePause - 3*skillDecayInterval)}
+
+		if s.Effective(now) != 37 {
+			t.Errorf("Effective() = %v, want 37", s.Effective(now))
+		}
+	})
+
+	t.Run("навык не уходит в минус", func(t *testing.T) {
+		s := Skill{Level: 5, LastPracticed: now.Add(-30 * 24 * time.Hour)}
+
+		if s.Effective(now) != 0 {
+			t.Errorf("Effective() = %v, want 0", s.Effective(now))
+		}
+	})
+}